@@ -27,11 +27,30 @@ func (r *binaryReader) SymbolTable() SymbolTable {
 	return r.lst
 }
 
+// Span returns the span of the current value.
+func (r *binaryReader) Span() (Span, error) {
+	return r.span(r.lst)
+}
+
+// Seek resets the reader to read the value at the given span from in, which
+// must be positioned at its start.
+func (r *binaryReader) seek(in *bufio.Reader, s Span) {
+	r.hoist(s)
+	r.bits = bitstream{}
+	r.bits.InitAt(in, s.Start)
+	r.lst = s.symbolTableOrSystem()
+}
+
 // Next moves the reader to the next value.
 func (r *binaryReader) Next() bool {
 	if r.eof || r.err != nil {
 		return false
 	}
+	if r.hoistDone() {
+		r.clear()
+		r.eof = true
+		return false
+	}
 
 	r.clear()
 
@@ -39,11 +58,16 @@ func (r *binaryReader) Next() bool {
 	for !done {
 		done, r.err = r.next()
 		if r.err != nil {
+			r.err = withPosition(r.err, nil, r.errPath())
 			return false
 		}
 	}
 
-	return !r.eof
+	if r.eof {
+		return false
+	}
+	r.count++
+	return true
 }
 
 // Next consumes the next raw value from the stream, returning true if it
@@ -54,6 +78,12 @@ func (r *binaryReader) next() (bool, error) {
 	}
 
 	code := r.bits.Code()
+	if code != bitcodeFieldID && len(r.annotations) == 0 {
+		// This is either an annotation wrapper or the value itself.
+		r.offset = r.bits.Start()
+		r.end = r.bits.End()
+	}
+
 	switch code {
 	case bitcodeEOF:
 		r.eof = true
@@ -138,7 +168,9 @@ func (r *binaryReader) next() (bool, error) {
 			if err != nil {
 				return false, err
 			}
-			r.value = r.resolve(id)
+			tok, str := r.resolve(id)
+			r.value = str
+			r.symbolValue = &tok
 		}
 		return true, nil
 
@@ -206,10 +238,6 @@ func (r *binaryReader) next() (bool, error) {
 	panic(fmt.Sprintf("invalid bitcode %v", code))
 }
 
-func isIonSymbolTable(as []string) bool {
-	return len(as) > 0 && as[0] == "$ion_symbol_table"
-}
-
 // ReadBVM reads a BVM, validates it, and resets the local symbol table.
 func (r *binaryReader) readBVM() error {
 	major, minor, err := r.bits.ReadBVM()
@@ -227,188 +255,24 @@ func (r *binaryReader) readBVM() error {
 	}
 
 	return &UnsupportedVersionError{
-		int(major),
-		int(minor),
-		r.bits.Pos() - 4,
+		Major:  int(major),
+		Minor:  int(minor),
+		Offset: r.bits.Pos() - 4,
 	}
 }
 
 // ReadLocalSymbolTable reads and installs a new local symbol table.
 func (r *binaryReader) readLocalSymbolTable() error {
-	if r.IsNull() {
-		r.clear()
-		r.lst = V1SystemSymbolTable
-		return nil
-	}
-
-	if err := r.StepIn(); err != nil {
-		return err
-	}
-
-	imps := []SharedSymbolTable{}
-	syms := []string{}
-
-	for r.Next() {
-		var err error
-		switch r.FieldName() {
-		case "imports":
-			imps, err = r.readImports()
-		case "symbols":
-			syms, err = r.readSymbols()
-		}
-		if err != nil {
-			return err
-		}
-	}
-
-	if err := r.StepOut(); err != nil {
+	lst, err := readLocalSymbolTable(r, r.cat)
+	if err != nil {
 		return err
 	}
 
-	r.lst = NewLocalSymbolTable(imps, syms)
+	r.clear()
+	r.lst = lst
 	return nil
 }
 
-// ReadImports reads the imports field of a local symbol table.
-func (r *binaryReader) readImports() ([]SharedSymbolTable, error) {
-	if r.valueType == SymbolType && r.value == "$ion_symbol_table" {
-		// Special case that imports the current local symbol table.
-		if r.lst == nil || r.lst == V1SystemSymbolTable {
-			return nil, nil
-		}
-
-		imps := r.lst.Imports()
-		lsst := NewSharedSymbolTable("", 0, r.lst.Symbols())
-		return append(imps, lsst), nil
-	}
-
-	if r.Type() != ListType || r.IsNull() {
-		return nil, nil
-	}
-	if err := r.StepIn(); err != nil {
-		return nil, err
-	}
-
-	imps := []SharedSymbolTable{}
-	for r.Next() {
-		imp, err := r.readImport()
-		if err != nil {
-			return nil, err
-		}
-		if imp != nil {
-			imps = append(imps, imp)
-		}
-	}
-
-	err := r.StepOut()
-	return imps, err
-}
-
-// ReadImport reads an import definition.
-func (r *binaryReader) readImport() (SharedSymbolTable, error) {
-	if r.Type() != StructType || r.IsNull() {
-		return nil, nil
-	}
-	if err := r.StepIn(); err != nil {
-		return nil, err
-	}
-
-	name := ""
-	version := 0
-	maxID := uint64(0)
-
-	for r.Next() {
-		var err error
-		switch r.FieldName() {
-		case "name":
-			if r.Type() == StringType {
-				name, err = r.StringValue()
-			}
-		case "version":
-			if r.Type() == IntType {
-				version, err = r.IntValue()
-			}
-		case "max_id":
-			if r.Type() == IntType {
-				var i int64
-				i, err = r.Int64Value()
-				if i < 0 {
-					i = 0
-				}
-				maxID = uint64(i)
-			}
-		}
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	if err := r.StepOut(); err != nil {
-		return nil, err
-	}
-
-	if name == "" || name == "$ion" {
-		return nil, nil
-	}
-	if version < 1 {
-		version = 1
-	}
-
-	var imp SharedSymbolTable
-	if r.cat != nil {
-		imp = r.cat.FindExact(name, version)
-		if imp == nil {
-			imp = r.cat.FindLatest(name)
-		}
-	}
-
-	if maxID == 0 {
-		if imp == nil || version != imp.Version() {
-			return nil, fmt.Errorf("ion: import of shared table %v/%v lacks a valid max_id, but an exact "+
-				"match was not found in the catalog", name, version)
-		}
-		maxID = imp.MaxID()
-	}
-
-	if imp == nil {
-		imp = &bogusSST{
-			name:    name,
-			version: version,
-			maxID:   maxID,
-		}
-	} else {
-		imp = imp.Adjust(maxID)
-	}
-
-	return imp, nil
-}
-
-// ReadSymbols reads the symbols from a symbol table.
-func (r *binaryReader) readSymbols() ([]string, error) {
-	if r.Type() != ListType {
-		return nil, nil
-	}
-	if err := r.StepIn(); err != nil {
-		return nil, err
-	}
-
-	syms := []string{}
-	for r.Next() {
-		if r.Type() == StringType {
-			sym, err := r.StringValue()
-			if err != nil {
-				return nil, err
-			}
-			syms = append(syms, sym)
-		} else {
-			syms = append(syms, "")
-		}
-	}
-
-	err := r.StepOut()
-	return syms, err
-}
-
 // ReadFieldName reads and resolves a field name.
 func (r *binaryReader) readFieldName() error {
 	id, err := r.bits.ReadFieldID()
@@ -416,7 +280,9 @@ func (r *binaryReader) readFieldName() error {
 		return err
 	}
 
-	r.fieldName = r.resolve(id)
+	tok, str := r.resolve(id)
+	r.fieldName = str
+	r.fieldNameSym = &tok
 	return nil
 }
 
@@ -428,22 +294,53 @@ func (r *binaryReader) readAnnotations() error {
 	}
 
 	as := make([]string, len(ids))
+	toks := make([]SymbolToken, len(ids))
 	for i, id := range ids {
-		as[i] = r.resolve(id)
+		toks[i], as[i] = r.resolve(id)
 	}
 
 	r.annotations = as
+	r.annotationSyms = toks
 	return nil
 }
 
-// Resolve resolves a symbol ID to a symbol value (possibly ${id} if we're
-// missing the appropriate symbol table).
-func (r *binaryReader) resolve(id uint64) string {
-	s, ok := r.lst.FindByID(id)
-	if !ok {
-		return fmt.Sprintf("$%v", id)
+// Resolve resolves a symbol ID to a symbol token and its string form. The token's
+// text is unknown (and its string form is ${id}) if we're missing the appropriate
+// symbol table.
+func (r *binaryReader) resolve(id uint64) (SymbolToken, string) {
+	tok := newSymbolTokenFromTable(r.lst, id)
+	if tok.Text == nil {
+		return tok, fmt.Sprintf("$%v", id)
 	}
-	return s
+	return tok, *tok.Text
+}
+
+// RawContainer returns the type code and serialized contents of the current value,
+// consuming it, if it is a non-null container we haven't stepped in to.
+func (r *binaryReader) rawContainer() (byte, []byte, bool, error) {
+	var code byte
+	switch r.valueType {
+	case ListType:
+		code = 0xB0
+	case SexpType:
+		code = 0xC0
+	case StructType:
+		code = 0xD0
+	default:
+		return 0, nil, false, nil
+	}
+	if r.value == nil {
+		return 0, nil, false, nil
+	}
+
+	body, err := r.bits.ReadRaw()
+	if err != nil {
+		r.err = withPosition(err, nil, r.valuePath())
+		return 0, nil, false, r.err
+	}
+
+	r.clear()
+	return code, body, true, nil
 }
 
 // StepIn steps in to a container-type value
@@ -459,6 +356,7 @@ func (r *binaryReader) StepIn() error {
 		return &UsageError{"Reader.StepIn", "cannot step in to a null container"}
 	}
 
+	r.pushPath()
 	r.ctx.push(containerTypeToCtx(r.valueType))
 	r.clear()
 	r.bits.StepIn()
@@ -476,10 +374,11 @@ func (r *binaryReader) StepOut() error {
 	}
 
 	if err := r.bits.StepOut(); err != nil {
-		return err
+		return withPosition(err, nil, r.errPath())
 	}
 
 	r.clear()
+	r.popPath()
 	r.ctx.pop()
 	r.eof = false
 
@@ -1,9 +1,11 @@
 package ion
 
 import (
+	"bytes"
 	"fmt"
 	"math"
 	"math/big"
+	"strings"
 	"testing"
 	"time"
 )
@@ -345,6 +347,81 @@ func TestReadBinaryDecimals(t *testing.T) {
 	_eof(t, r)
 }
 
+func TestReadBinaryNegativeZeros(t *testing.T) {
+	r := readBinary([]byte{
+		0x52, 0x80, 0x80, // -0.
+		0x52, 0xC2, 0x80, // -0.00
+		0x51, 0x80, // 0., with an explicit exponent
+		0x44, 0x80, 0x00, 0x00, 0x00, // -0e0, 32-bit
+		0x48, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // -0e0, 64-bit
+	})
+
+	for _, eval := range []string{"-0.", "-0d-2", "0."} {
+		_next(t, r, DecimalType)
+		val, err := r.DecimalValue()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if val.String() != eval {
+			t.Errorf("expected %v, got %v", eval, val)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		_next(t, r, FloatType)
+		val, err := r.FloatValue()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if val != 0 || !math.Signbit(val) {
+			t.Errorf("expected -0e0, got %v", val)
+		}
+	}
+
+	_eof(t, r)
+}
+
+func TestBinaryNumberRoundTrip(t *testing.T) {
+	in := "-0. -0d5 -0.00 0.00 -0e0 0e0 1.5e0 -1.25d-3"
+
+	buf := bytes.Buffer{}
+	w := NewBinaryWriter(&buf)
+	if err := Copy(w, NewReaderStr(in)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := strings.Builder{}
+	tw := NewTextWriterOpts(&out, TextWriterQuietFinish)
+	if err := Copy(tw, NewReaderBytes(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "-0.\n-0d5\n-0d-2\n0d-2\n-0e+0\n0e+0\n1.5e+0\n-1.25d-3"
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+
+	vals, err := ReadValues(NewReaderBytes(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	evals := readValuesStr(t, in)
+	for i := range evals {
+		if !vals[i].Equal(evals[i]) {
+			t.Errorf("expected %v, got %v", evals[i], vals[i])
+		}
+	}
+	if vals[2].Equal(vals[3]) {
+		t.Errorf("expected -0.00 not to equal 0.00")
+	}
+}
+
 func TestReadBinaryFloats(t *testing.T) {
 	r := readBinary([]byte{
 		0x40,                         // 0
@@ -452,3 +529,103 @@ func readBinary(ion []byte) Reader {
 	}
 	return NewReaderBytes(append(prefix, ion...))
 }
+
+func TestReadBinaryTimestampPrecision(t *testing.T) {
+	r := readBinary([]byte{
+		0x62, 0xC0, 0x8F, // 15T
+		0x66, 0xC0, 0x8F, 0x81, 0x81, 0x80, 0x80, // 0015-01-01T00:00-00:00
+		0x69, 0x80, 0x8F, 0x81, 0x81, 0x80, 0x80, 0x80, 0xC3, 0x00, // 0015-01-01T00:00:00.000Z
+	})
+
+	test := func(eval string) {
+		_next(t, r, TimestampType)
+		ts, err := r.TimestampValue()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ts.String() != eval {
+			t.Errorf("expected %v, got %v", eval, ts)
+		}
+	}
+
+	test("0015T")
+	test("0015-01-01T00:00-00:00")
+	test("0015-01-01T00:00:00.000Z")
+	_eof(t, r)
+}
+
+func TestReadBinarySymbolTokens(t *testing.T) {
+	r := readBinary([]byte{
+		0x71, 0x0B, // bogus[2]
+		0x71, 0x6E, // foo
+		0xD6, 0x8B, 0xE4, 0x81, 0x8A, 0x71, 0x6F, // { bogus[2]: bogus[1]::bar }
+	})
+
+	_next(t, r, SymbolType)
+	tok, err := r.SymbolValue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.Text != nil {
+		t.Errorf("expected unknown text, got %v", *tok.Text)
+	}
+	if tok.LocalSID != 11 {
+		t.Errorf("expected sid 11, got %v", tok.LocalSID)
+	}
+	if tok.Source == nil || *tok.Source != (ImportSource{"bogus", 2}) {
+		t.Errorf("expected import source bogus/2, got %v", tok.Source)
+	}
+
+	_next(t, r, SymbolType)
+	tok, err = r.SymbolValue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.Text == nil || *tok.Text != "foo" || tok.LocalSID != 110 || tok.Source != nil {
+		t.Errorf("expected local symbol foo, got %v", tok)
+	}
+
+	_struct(t, r, func(t *testing.T, r Reader) {
+		if !r.Next() || r.Type() != SymbolType {
+			t.Fatalf("expected a symbol, got %v", r.Type())
+		}
+
+		fn := r.FieldNameSymbol()
+		if fn == nil || fn.Text != nil || *fn.Source != (ImportSource{"bogus", 2}) {
+			t.Errorf("expected field name bogus/2, got %v", fn)
+		}
+		if r.FieldName() != "$11" {
+			t.Errorf("expected field name $11, got %v", r.FieldName())
+		}
+
+		as := r.AnnotationSymbols()
+		if len(as) != 1 || as[0].Text != nil || *as[0].Source != (ImportSource{"bogus", 1}) {
+			t.Errorf("expected annotation bogus/1, got %v", as)
+		}
+	})
+
+	_eof(t, r)
+}
+
+func TestReadBinaryErrorPosition(t *testing.T) {
+	r := readBinary([]byte{
+		0xD9,       // {
+		0x84,       // name:
+		0xB7,       // [
+		0x21, 0x01, // 1
+		0xB4,                   // [
+		0x8A, 0x61, 0x62, 0x63, // "abc" (but the list is too short)
+	})
+
+	_, err := ReadValues(r)
+	se, ok := err.(*SyntaxError)
+	if !ok {
+		t.Fatalf("expected SyntaxError, got %v", err)
+	}
+	if se.Path != "name[1][0]" {
+		t.Errorf("expected path name[1][0], got %v", se.Path)
+	}
+	if se.Line != 0 || se.Column != 0 {
+		t.Errorf("expected no line or column, got line %v, column %v", se.Line, se.Column)
+	}
+}
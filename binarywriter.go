@@ -6,8 +6,6 @@ import (
 	"io"
 	"math"
 	"math/big"
-	"strconv"
-	"strings"
 	"time"
 )
 
@@ -20,6 +18,17 @@ type binaryWriter struct {
 	lstb SymbolTableBuilder
 
 	wroteLST bool
+
+	// In streaming mode, each top-level value is flushed as soon as it is complete,
+	// and newly-added symbols are appended to the previously-written LST.
+	streaming   bool
+	flushedSyms int
+	imports     []SharedSymbolTable
+
+	// The last symbol table we checked for compatibility with lst when copying
+	// raw values, and whether it was.
+	rawLST SymbolTable
+	rawOK  bool
 }
 
 // NewBinaryWriter creates a new binary writer that will construct a
@@ -35,6 +44,25 @@ func NewBinaryWriter(out io.Writer, sts ...SharedSymbolTable) Writer {
 	return w
 }
 
+// NewBinaryWriterStreaming creates a new binary writer that constructs a local symbol
+// table as it is written to, but does not buffer the entire datagram until Finish is
+// called. Instead, each top-level value is written to out as soon as it is complete,
+// preceded by an append to the local symbol table if it uses any new symbols. Memory
+// usage is bounded by the size of the largest top-level value (and the number of
+// distinct symbols) rather than the size of the whole stream.
+func NewBinaryWriterStreaming(out io.Writer, sts ...SharedSymbolTable) Writer {
+	w := &binaryWriter{
+		writer: writer{
+			out: out,
+		},
+		lstb:      NewSymbolTableBuilder(sts...),
+		streaming: true,
+		imports:   sts,
+	}
+	w.bufs.push(&datagram{})
+	return w
+}
+
 // NewBinaryWriterLST creates a new binary writer with a pre-built local
 // symbol table.
 func NewBinaryWriterLST(out io.Writer, lst SymbolTable) Writer {
@@ -153,12 +181,18 @@ func (w *binaryWriter) writeBigInt(val *big.Int) error {
 	return w.write(bs)
 }
 
-// WriteFloat writes a floating-point value.
+// WriteFloat writes a floating-point value, using the 4-byte encoding if the value
+// is exactly representable as a 32-bit float.
 func (w *binaryWriter) WriteFloat(val float64) error {
-	if val == 0 {
+	if val == 0 && !math.Signbit(val) {
 		return w.writeValue("Writer.WriteFloat", []byte{0x40})
 	}
 
+	// Use the 4-byte encoding if it's lossless, so 32-bit floats round-trip.
+	if f := float32(val); math.Float64bits(float64(f)) == math.Float64bits(val) {
+		return w.writeFloat32("Writer.WriteFloat", f)
+	}
+
 	bs := make([]byte, 9)
 	bs[0] = 0x48
 
@@ -168,26 +202,55 @@ func (w *binaryWriter) WriteFloat(val float64) error {
 	return w.writeValue("Writer.WriteFloat", bs)
 }
 
+// WriteFloat32 writes a 32-bit floating-point value, using the 4-byte encoding.
+func (w *binaryWriter) WriteFloat32(val float32) error {
+	if val == 0 && !math.Signbit(float64(val)) {
+		return w.writeValue("Writer.WriteFloat32", []byte{0x40})
+	}
+	return w.writeFloat32("Writer.WriteFloat32", val)
+}
+
+// WriteFloat32 writes a nonzero 32-bit floating-point value in four bytes.
+func (w *binaryWriter) writeFloat32(api string, val float32) error {
+	bs := make([]byte, 5)
+	bs[0] = 0x44
+
+	bits := math.Float32bits(val)
+	binary.BigEndian.PutUint32(bs[1:], bits)
+
+	return w.writeValue(api, bs)
+}
+
 // WriteDecimal writes a decimal value.
 func (w *binaryWriter) WriteDecimal(val *Decimal) error {
 	coef, exp := val.CoEx()
+	negZero := coef.Sign() == 0 && val.Signbit()
+	// Only 0d0 gets away without an exponent; every other decimal needs one.
+	hasExp := exp != 0 || coef.Sign() != 0 || negZero
 
 	vlen := uint64(0)
-	if exp != 0 {
+	if hasExp {
 		vlen += varIntLen(int64(exp))
 	}
 	if coef.Sign() != 0 {
 		vlen += bigIntLen(coef)
+	} else if negZero {
+		vlen++
 	}
 
 	buflen := vlen + tagLen(vlen)
 	buf := make([]byte, 0, buflen)
 
 	buf = appendTag(buf, 0x50, vlen)
-	if exp != 0 {
+	if hasExp {
 		buf = appendVarInt(buf, int64(exp))
 	}
-	buf = appendBigInt(buf, coef)
+	if negZero {
+		// A zero coefficient with just the sign bit set.
+		buf = append(buf, 0x80)
+	} else {
+		buf = appendBigInt(buf, coef)
+	}
 
 	return w.writeValue("Writer.WriteDecimal", buf)
 }
@@ -209,14 +272,44 @@ func (w *binaryWriter) WriteTimestamp(val time.Time) error {
 	return w.writeValue("Writer.WriteTimestamp", buf)
 }
 
+// WriteIonTimestamp writes an Ion timestamp value.
+func (w *binaryWriter) WriteIonTimestamp(val *Timestamp) error {
+	vlen := timestampLen(val)
+	buflen := vlen + tagLen(vlen)
+
+	buf := make([]byte, 0, buflen)
+
+	buf = appendTag(buf, 0x60, vlen)
+	buf = appendTimestamp(buf, val)
+
+	return w.writeValue("Writer.WriteIonTimestamp", buf)
+}
+
 // WriteSymbol writes a symbol value.
 func (w *binaryWriter) WriteSymbol(val string) error {
-	id, err := w.resolve("Writer.WriteSymbol", val)
+	return w.writeSymbol("Writer.WriteSymbol", symbolTokenFromString(val))
+}
+
+// WriteSymbolToken writes a symbol token value.
+func (w *binaryWriter) WriteSymbolToken(val SymbolToken) error {
+	return w.writeSymbol("Writer.WriteSymbolToken", val)
+}
+
+func (w *binaryWriter) writeSymbol(api string, val SymbolToken) error {
+	if w.err != nil {
+		return w.err
+	}
+
+	id, err := w.resolveToken(api, val)
 	if err != nil {
 		w.err = err
 		return err
 	}
 
+	if id == 0 {
+		return w.writeValue(api, []byte{0x70})
+	}
+
 	vlen := uintLen(uint64(id))
 	buflen := vlen + tagLen(vlen)
 	buf := make([]byte, 0, buflen)
@@ -224,7 +317,7 @@ func (w *binaryWriter) WriteSymbol(val string) error {
 	buf = appendTag(buf, 0x70, vlen)
 	buf = appendUint(buf, uint64(id))
 
-	return w.writeValue("Writer.WriteSymbol", buf)
+	return w.writeValue(api, buf)
 }
 
 // WriteString writes a string.
@@ -344,6 +437,68 @@ func (w *binaryWriter) EndStruct() error {
 	return w.err
 }
 
+// WriteValue writes the value the reader is positioned on.
+func (w *binaryWriter) WriteValue(r Reader) error {
+	return w.writeValueFrom(w, r)
+}
+
+// WriteValues writes the rest of the reader's values.
+func (w *binaryWriter) WriteValues(r Reader) error {
+	return writeValues(w, r)
+}
+
+// WriteRaw copies the reader's current value without re-encoding it, if it's a
+// binary container encoded against a symbol table compatible with ours.
+func (w *binaryWriter) writeRaw(r Reader) (bool, error) {
+	rr, ok := r.(rawReader)
+	if !ok || !w.canCopyRaw(r.SymbolTable()) {
+		return false, nil
+	}
+
+	code, body, ok, err := rr.rawContainer()
+	if !ok || err != nil {
+		return false, err
+	}
+
+	if w.err = w.beginValue("Writer.WriteValue"); w.err != nil {
+		return true, w.err
+	}
+	if w.err = w.writeTag(code, uint64(len(body))); w.err != nil {
+		return true, w.err
+	}
+	if len(body) > 0 {
+		if w.err = w.write(body); w.err != nil {
+			return true, w.err
+		}
+	}
+
+	w.err = w.endValue()
+	return true, w.err
+}
+
+// CanCopyRaw returns true if values encoded against the given symbol table can be
+// copied as-is, because we have a fixed local symbol table that assigns the same
+// IDs to all of its symbols.
+func (w *binaryWriter) canCopyRaw(lst SymbolTable) bool {
+	if w.lst == nil || lst == nil {
+		return false
+	}
+	if lst == w.rawLST {
+		return w.rawOK
+	}
+
+	ok := lst.MaxID() <= w.lst.MaxID()
+	for id := uint64(1); ok && id <= lst.MaxID(); id++ {
+		a, aok := lst.FindByID(id)
+		b, bok := w.lst.FindByID(id)
+		ok = a == b && aok == bok
+	}
+
+	w.rawLST = lst
+	w.rawOK = ok
+	return ok
+}
+
 // Finish finishes writing a datagram.
 func (w *binaryWriter) Finish() error {
 	if w.err != nil {
@@ -354,6 +509,19 @@ func (w *binaryWriter) Finish() error {
 	}
 
 	w.clear()
+
+	if w.streaming {
+		// Everything but the LST has already been flushed; make sure we've at least
+		// written an IVM, then start over with a fresh LST for any subsequent values.
+		if w.err = w.flush(); w.err != nil {
+			return w.err
+		}
+		w.wroteLST = false
+		w.lstb = NewSymbolTableBuilder(w.imports...)
+		w.flushedSyms = 0
+		return nil
+	}
+
 	w.wroteLST = false
 
 	seq := w.bufs.peek()
@@ -375,6 +543,34 @@ func (w *binaryWriter) Finish() error {
 	return nil
 }
 
+// Flush writes out the top-level values buffered so far in streaming mode, preceded
+// by the local symbol table (the first time) or an append to it defining any symbols
+// added since the last flush.
+func (w *binaryWriter) flush() error {
+	seq := w.bufs.peek()
+	w.bufs.pop()
+
+	syms := w.lstb.Symbols()
+	if !w.wroteLST {
+		w.wroteLST = true
+		if err := w.writeLST(w.lstb.Build()); err != nil {
+			return err
+		}
+	} else if len(syms) > w.flushedSyms {
+		if err := w.writeLSTAppend(syms[w.flushedSyms:]); err != nil {
+			return err
+		}
+	}
+	w.flushedSyms = len(syms)
+
+	if err := w.emit(seq); err != nil {
+		return err
+	}
+
+	w.bufs.push(&datagram{})
+	return nil
+}
+
 // Emit emits the given node. If we're currently at the top level, that
 // means actually emitting to the output stream. If not, we emit append
 // to the current bufseq.
@@ -428,6 +624,25 @@ func (w *binaryWriter) writeLST(lst SymbolTable) error {
 	return lst.WriteTo(w)
 }
 
+// WriteLSTAppend writes out a local symbol table that appends the given symbols to
+// the current local symbol table.
+func (w *binaryWriter) writeLSTAppend(syms []string) error {
+	w.Annotation("$ion_symbol_table")
+	w.BeginStruct()
+
+	w.FieldName("imports")
+	w.WriteSymbol("$ion_symbol_table")
+
+	w.FieldName("symbols")
+	w.BeginList()
+	for _, sym := range syms {
+		w.WriteString(sym)
+	}
+	w.EndList()
+
+	return w.EndStruct()
+}
+
 // BeginValue begins the process of writing a value by writing out
 // its field name and annotations.
 func (w *binaryWriter) beginValue(api string) error {
@@ -446,11 +661,11 @@ func (w *binaryWriter) beginValue(api string) error {
 	}
 
 	if w.inStruct() {
-		if name == "" {
+		if name == nil {
 			return &UsageError{api, "field name not set"}
 		}
 
-		id, err := w.resolve(api, name)
+		id, err := w.resolveToken(api, *name)
 		if err != nil {
 			return err
 		}
@@ -467,7 +682,7 @@ func (w *binaryWriter) beginValue(api string) error {
 		idlen := uint64(0)
 
 		for i, a := range as {
-			id, err := w.resolve(api, a)
+			id, err := w.resolveToken(api, a)
 			if err != nil {
 				return err
 			}
@@ -496,13 +711,25 @@ func (w *binaryWriter) beginValue(api string) error {
 }
 
 // EndValue ends the process of writing a value by flushing it and its annotations
-// up a level, if needed.
+// up a level, if needed. In streaming mode, completed top-level values are flushed
+// to the output stream.
 func (w *binaryWriter) endValue() error {
 	seq := w.bufs.peek()
 	if seq != nil {
 		if c, ok := seq.(*container); ok && c.code == 0xE0 {
 			w.bufs.pop()
-			return w.emit(seq)
+			if err := w.emit(seq); err != nil {
+				return err
+			}
+			seq = w.bufs.peek()
+		}
+	}
+
+	if w.streaming && w.ctx.peek() == ctxAtTopLevel {
+		// Only flush user values buffered in the datagram, not the LST we write
+		// directly to the output stream while flushing.
+		if _, ok := seq.(*datagram); ok {
+			return w.flush()
 		}
 	}
 	return nil
@@ -540,15 +767,58 @@ func (w *binaryWriter) end(api string, t ctx) error {
 	return w.endValue()
 }
 
-// Resolve resolves a symbol to its ID.
-func (w *binaryWriter) resolve(api, sym string) (uint64, error) {
-	if strings.HasPrefix(sym, "$") {
-		id, err := strconv.ParseUint(sym[1:], 10, 64)
-		if err == nil {
-			return id, nil
+// ResolveToken resolves a symbol token to its ID.
+func (w *binaryWriter) resolveToken(api string, tok SymbolToken) (uint64, error) {
+	if tok.Text != nil {
+		return w.resolve(api, *tok.Text)
+	}
+
+	if tok.Source != nil {
+		id, ok := w.resolveImport(tok.Source)
+		if !ok {
+			msg := fmt.Sprintf("symbol %v from shared table '%v' not imported", tok.Source.SID, tok.Source.Table)
+			return 0, &UsageError{api, msg}
+		}
+		return id, nil
+	}
+
+	if tok.LocalSID == SymbolIDUnknown || tok.LocalSID == 0 {
+		// A symbol with no text and no ID is $0.
+		return 0, nil
+	}
+	if tok.ref || (tok.LocalSID > 0 && uint64(tok.LocalSID) <= V1SystemSymbolTable.MaxID()) {
+		// An explicit $<integer> refers to our own symbol table, and system symbols
+		// have the same ID in every symbol table.
+		return uint64(tok.LocalSID), nil
+	}
+
+	// Any other local ID belongs to the symbol table the token was read with, and
+	// would mean something else (or nothing) in ours.
+	msg := fmt.Sprintf("symbol $%v has no text or import location", tok.LocalSID)
+	return 0, &UsageError{api, msg}
+}
+
+// ResolveImport resolves the location of a symbol in a shared table to its ID
+// in our symbol table.
+func (w *binaryWriter) resolveImport(src *ImportSource) (uint64, bool) {
+	var st SymbolTable = w.lstb
+	if w.lst != nil {
+		st = w.lst
+	}
+
+	off := uint64(0)
+	for _, imp := range importsOf(st) {
+		if imp.Name() == src.Table && src.SID > 0 && uint64(src.SID) <= imp.MaxID() {
+			return off + uint64(src.SID), true
 		}
+		off += imp.MaxID()
 	}
 
+	return 0, false
+}
+
+// Resolve resolves a symbol's text to its ID.
+func (w *binaryWriter) resolve(api, sym string) (uint64, error) {
 	if w.lst != nil {
 		id, ok := w.lst.FindByName(sym)
 		if !ok {
@@ -181,6 +181,11 @@ func TestWriteBinaryDecimal(t *testing.T) {
 		0x53, 0xC3, 0x83, 0xE8, // -1.000, aka -1000 x 10^-3
 		0x53, 0x00, 0xE4, 0x01, // 1d100, aka 1 * 10^100
 		0x53, 0x00, 0xE4, 0x81, // -1d100, aka -1 * 10^100
+		0x52, 0x80, 0x80, // -0., aka -0 x 10^0
+		0x52, 0xC2, 0x80, // -0.00, aka -0 x 10^-2
+		0x52, 0x85, 0x80, // -0d5, aka -0 x 10^5
+		0x52, 0x80, 0x01, // 1., aka 1 x 10^0
+		0x54, 0x80, 0x83, 0x02, 0x5B, // -197211., aka -197211 x 10^0
 	}
 
 	testBinaryWriter(t, eval, func(w Writer) {
@@ -190,6 +195,11 @@ func TestWriteBinaryDecimal(t *testing.T) {
 		w.WriteDecimal(MustParseDecimal("-1.000"))
 		w.WriteDecimal(MustParseDecimal("1d100"))
 		w.WriteDecimal(MustParseDecimal("-1d100"))
+		w.WriteDecimal(MustParseDecimal("-0."))
+		w.WriteDecimal(MustParseDecimal("-0.00"))
+		w.WriteDecimal(MustParseDecimal("-0d5"))
+		w.WriteDecimal(MustParseDecimal("1."))
+		w.WriteDecimal(MustParseDecimal("-197211."))
 	})
 }
 
@@ -198,17 +208,93 @@ func TestWriteBinaryFloats(t *testing.T) {
 		0x40,                                                 // 0
 		0x48, 0x7F, 0xEF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, // MaxFloat64
 		0x48, 0xFF, 0xEF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, // -MaxFloat64
-		0x48, 0x7F, 0xF0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // +inf
-		0x48, 0xFF, 0xF0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // -inf
+		0x48, 0x3F, 0xB9, 0x99, 0x99, 0x99, 0x99, 0x99, 0x9A, // 0.1
+		0x44, 0x3F, 0xC0, 0x00, 0x00, // 1.5
+		0x44, 0x7F, 0x7F, 0xFF, 0xFF, // MaxFloat32
+		0x44, 0x7F, 0x80, 0x00, 0x00, // +inf
+		0x44, 0xFF, 0x80, 0x00, 0x00, // -inf
 		0x48, 0x7F, 0xF8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, // NaN
+		0x44, 0x80, 0x00, 0x00, 0x00, // -0
 	}
 	testBinaryWriter(t, eval, func(w Writer) {
 		w.WriteFloat(0)
 		w.WriteFloat(math.MaxFloat64)
 		w.WriteFloat(-math.MaxFloat64)
+		w.WriteFloat(0.1)
+		w.WriteFloat(1.5)
+		w.WriteFloat(math.MaxFloat32)
 		w.WriteFloat(math.Inf(1))
 		w.WriteFloat(math.Inf(-1))
 		w.WriteFloat(math.NaN())
+		w.WriteFloat(math.Copysign(0, -1))
+	})
+}
+
+func TestWriteBinaryFloatRoundTrip(t *testing.T) {
+	data := []byte{
+		0xE0, 0x01, 0x00, 0xEA,
+		0x44, 0x3F, 0xC0, 0x00, 0x00, // 1.5, 32-bit
+		0x44, 0x7F, 0xC0, 0x00, 0x00, // NaN, 32-bit
+		0x48, 0x3F, 0xB9, 0x99, 0x99, 0x99, 0x99, 0x99, 0x9A, // 0.1, 64-bit
+	}
+
+	test := func(name string, f func(w Writer, r Reader) error) {
+		t.Run(name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			w := NewBinaryWriter(&buf)
+			if err := f(w, NewReaderBytes(data)); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Finish(); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), data) {
+				t.Errorf("expected %v, got %v", fmtbytes(data), fmtbytes(buf.Bytes()))
+			}
+		})
+	}
+
+	test("Copy", Copy)
+	test("FloatValue", func(w Writer, r Reader) error {
+		for r.Next() {
+			f, err := r.FloatValue()
+			if err != nil {
+				return err
+			}
+			if err := w.WriteFloat(f); err != nil {
+				return err
+			}
+		}
+		return r.Err()
+	})
+	test("Value", func(w Writer, r Reader) error {
+		vals, err := ReadValues(r)
+		if err != nil {
+			return err
+		}
+		for _, v := range vals {
+			if err := v.WriteTo(w); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func TestWriteBinaryFloat32s(t *testing.T) {
+	eval := []byte{
+		0x40,                         // 0
+		0x44, 0x80, 0x00, 0x00, 0x00, // -0
+		0x44, 0x3F, 0xC0, 0x00, 0x00, // 1.5
+		0x44, 0x7F, 0x7F, 0xFF, 0xFF, // MaxFloat32
+		0x44, 0xFF, 0x80, 0x00, 0x00, // -inf
+	}
+	testBinaryWriter(t, eval, func(w Writer) {
+		w.WriteFloat32(0)
+		w.WriteFloat32(float32(math.Copysign(0, -1)))
+		w.WriteFloat32(1.5)
+		w.WriteFloat32(math.MaxFloat32)
+		w.WriteFloat32(float32(math.Inf(-1)))
 	})
 }
 
@@ -385,3 +471,191 @@ func writeBinary(t *testing.T, f func(w Writer)) []byte {
 
 	return buf.Bytes()
 }
+
+func TestWriteBinaryIonTimestamp(t *testing.T) {
+	eval := []byte{
+		0x62, 0xC0, 0x8F, // 15T
+		0x66, 0xC0, 0x8F, 0x81, 0x81, 0x80, 0x80, // 0015-01-01T00:00-00:00
+		0x68, 0x80, 0x8F, 0x81, 0x81, 0x80, 0x80, 0x80, 0xC3, // 0015-01-01T00:00:00.000Z
+		0x6B, 0x04, 0xD8, 0x0F, 0xE3, 0x88, 0x84, 0x88, 0x8F, 0xAB, 0xC1, 0x05, // 2019-08-04T18:15:43.5+10:00
+	}
+	testBinaryWriter(t, eval, func(w Writer) {
+		w.WriteIonTimestamp(MustParseTimestamp("0015T"))
+		w.WriteIonTimestamp(MustParseTimestamp("0015-01-01T00:00-00:00"))
+		w.WriteIonTimestamp(MustParseTimestamp("0015-01-01T00:00:00.000Z"))
+		w.WriteIonTimestamp(MustParseTimestamp("2019-08-04T18:15:43.5+10:00"))
+	})
+}
+
+func TestWriteBinarySymbolTokens(t *testing.T) {
+	eval := []byte{
+		0x71, 0x0B, // bogus[2]
+		0x71, 0x6E, // foo
+		0x70,                                     // $0
+		0xD6, 0x8B, 0xE4, 0x81, 0x8A, 0x71, 0x6F, // { bogus[2]: bogus[1]::bar }
+	}
+	testBinaryWriter(t, eval, func(w Writer) {
+		w.WriteSymbolToken(SymbolToken{LocalSID: 99, Source: &ImportSource{"bogus", 2}})
+		w.WriteSymbolToken(NewSymbolToken("foo"))
+		w.WriteSymbolToken(SymbolToken{LocalSID: SymbolIDUnknown})
+
+		w.BeginStruct()
+		w.FieldNameSymbol(SymbolToken{LocalSID: 11, Source: &ImportSource{"bogus", 2}})
+		w.AnnotationSymbols(SymbolToken{LocalSID: SymbolIDUnknown, Source: &ImportSource{"bogus", 1}})
+		w.WriteSymbol("bar")
+		w.EndStruct()
+	})
+}
+
+func TestWriteBinarySymbolTokenNotImported(t *testing.T) {
+	buf := bytes.Buffer{}
+	w := NewBinaryWriter(&buf)
+
+	err := w.WriteSymbolToken(SymbolToken{LocalSID: 11, Source: &ImportSource{"missing", 2}})
+	if _, ok := err.(*UsageError); !ok {
+		t.Fatalf("expected a UsageError, got %v", err)
+	}
+}
+
+func TestWriteBinarySymbolTokenLocalSID(t *testing.T) {
+	eval := []byte{
+		0x71, 0x04, // $4 (name)
+		0x70, // $0
+	}
+	testBinaryWriter(t, eval, func(w Writer) {
+		w.WriteSymbolToken(NewSymbolTokenSID(4))
+		w.WriteSymbolToken(NewSymbolTokenSID(0))
+	})
+
+	buf := bytes.Buffer{}
+	w := NewBinaryWriter(&buf)
+
+	err := w.WriteSymbolToken(NewSymbolTokenSID(11))
+	if _, ok := err.(*UsageError); !ok {
+		t.Fatalf("expected a UsageError, got %v", err)
+	}
+}
+
+func TestWriteBinaryStreaming(t *testing.T) {
+	buf := bytes.Buffer{}
+	w := NewBinaryWriterStreaming(&buf)
+
+	w.WriteSymbol("foo")
+	lst := []byte{
+		0xE0, 0x01, 0x00, 0xEA, // $ion_1_0
+		0xE9, 0x81, 0x83, 0xD6, // $ion_symbol_table::{
+		0x87, 0xB4, // symbols: [
+		0x83, 'f', 'o', 'o', // "foo"
+		// ]}
+		0x71, 0x0A, // $10
+	}
+	if !bytes.Equal(buf.Bytes(), lst) {
+		t.Fatalf("expected %v, got %v", fmtbytes(lst), fmtbytes(buf.Bytes()))
+	}
+	buf.Reset()
+
+	w.Annotation("foo")
+	w.BeginStruct()
+	w.FieldName("bar")
+	w.WriteSymbol("foo")
+	w.EndStruct()
+	app := []byte{
+		0xEC, 0x81, 0x83, 0xD9, // $ion_symbol_table::{
+		0x86, 0x71, 0x03, // imports: $ion_symbol_table
+		0x87, 0xB4, // symbols: [
+		0x83, 'b', 'a', 'r', // "bar"
+		// ]}
+		0xE6, 0x81, 0x8A, // foo::
+		0xD3, 0x8B, 0x71, 0x0A, // {bar: foo}
+	}
+	if !bytes.Equal(buf.Bytes(), app) {
+		t.Fatalf("expected %v, got %v", fmtbytes(app), fmtbytes(buf.Bytes()))
+	}
+	buf.Reset()
+
+	// No new symbols, no LST append.
+	w.WriteSymbol("bar")
+	if eval := []byte{0x71, 0x0B}; !bytes.Equal(buf.Bytes(), eval) {
+		t.Fatalf("expected %v, got %v", fmtbytes(eval), fmtbytes(buf.Bytes()))
+	}
+
+	if err := w.Finish(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWriteBinaryStreamingFinish(t *testing.T) {
+	buf := bytes.Buffer{}
+	w := NewBinaryWriterStreaming(&buf)
+
+	w.WriteSymbol("foo")
+	if err := w.Finish(); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+
+	// After Finish, the next value starts a new LST that doesn't carry over foo.
+	w.WriteSymbol("bar")
+	lst := []byte{
+		0xE0, 0x01, 0x00, 0xEA, // $ion_1_0
+		0xE9, 0x81, 0x83, 0xD6, // $ion_symbol_table::{
+		0x87, 0xB4, // symbols: [
+		0x83, 'b', 'a', 'r', // "bar"
+		// ]}
+		0x71, 0x0A, // $10
+	}
+	if !bytes.Equal(buf.Bytes(), lst) {
+		t.Fatalf("expected %v, got %v", fmtbytes(lst), fmtbytes(buf.Bytes()))
+	}
+	buf.Reset()
+
+	// And symbols added to it are appended as usual.
+	w.WriteSymbol("foo")
+	app := []byte{
+		0xEC, 0x81, 0x83, 0xD9, // $ion_symbol_table::{
+		0x86, 0x71, 0x03, // imports: $ion_symbol_table
+		0x87, 0xB4, // symbols: [
+		0x83, 'f', 'o', 'o', // "foo"
+		// ]}
+		0x71, 0x0B, // $11
+	}
+	if !bytes.Equal(buf.Bytes(), app) {
+		t.Fatalf("expected %v, got %v", fmtbytes(app), fmtbytes(buf.Bytes()))
+	}
+
+	if err := w.Finish(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWriteBinaryStreamingRoundTrip(t *testing.T) {
+	buf := bytes.Buffer{}
+	w := NewBinaryWriterStreaming(&buf)
+
+	for i := 0; i < 3; i++ {
+		w.BeginStruct()
+		w.FieldName(fmt.Sprintf("field%v", i))
+		w.WriteSymbol(fmt.Sprintf("value%v", i))
+		w.EndStruct()
+	}
+	if err := w.Finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	w.WriteSymbol("value1")
+	w.WriteSymbol("again")
+	if err := w.Finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewReaderBytes(buf.Bytes())
+	for i := 0; i < 3; i++ {
+		_struct(t, r, func(t *testing.T, r Reader) {
+			_symbolAF(t, r, fmt.Sprintf("field%v", i), nil, fmt.Sprintf("value%v", i))
+			_eof(t, r)
+		})
+	}
+	_symbol(t, r, "value1")
+	_symbol(t, r, "again")
+	_eof(t, r)
+}
@@ -283,3 +283,71 @@ func appendTime(b []byte, offset int, utc time.Time) []byte {
 
 	return b
 }
+
+// timestampLen pre-calculates the length, in bytes, of the given timestamp value.
+func timestampLen(ts *Timestamp) uint64 {
+	ret := uint64(1) // -00:00
+	if ts.OffsetKnown() {
+		ret = varIntLen(int64(ts.Offset()))
+	}
+
+	fields := ts.utcFields()
+	ret += varUintLen(uint64(fields[0]))
+
+	// Month, day, hour, minute, and second are all guaranteed to be one byte.
+	switch ts.Precision() {
+	case TimestampPrecisionMonth:
+		ret++
+	case TimestampPrecisionDay:
+		ret += 2
+	case TimestampPrecisionMinute:
+		ret += 4
+	case TimestampPrecisionSecond, TimestampPrecisionFraction:
+		ret += 5
+	}
+
+	if frac := ts.Fraction(); frac != nil {
+		coef, exp := frac.CoEx()
+		ret += varIntLen(int64(exp))
+		ret += bigIntLen(coef)
+	}
+
+	return ret
+}
+
+// appendTimestamp appends a timestamp value, including only those fields
+// required by its precision.
+func appendTimestamp(b []byte, ts *Timestamp) []byte {
+	if ts.OffsetKnown() {
+		b = appendVarInt(b, int64(ts.Offset()))
+	} else {
+		// Negative zero marks an unknown offset.
+		b = append(b, 0xC0)
+	}
+
+	fields := ts.utcFields()
+	b = appendVarUint(b, uint64(fields[0]))
+
+	precision := ts.Precision()
+	if precision >= TimestampPrecisionMonth {
+		b = appendVarUint(b, uint64(fields[1]))
+	}
+	if precision >= TimestampPrecisionDay {
+		b = appendVarUint(b, uint64(fields[2]))
+	}
+	if precision >= TimestampPrecisionMinute {
+		b = appendVarUint(b, uint64(fields[3]))
+		b = appendVarUint(b, uint64(fields[4]))
+	}
+	if precision >= TimestampPrecisionSecond {
+		b = appendVarUint(b, uint64(fields[5]))
+	}
+
+	if frac := ts.Fraction(); frac != nil {
+		coef, exp := frac.CoEx()
+		b = appendVarInt(b, int64(exp))
+		b = appendBigInt(b, coef)
+	}
+
+	return b
+}
@@ -8,7 +8,6 @@ import (
 	"io"
 	"math"
 	"math/big"
-	"time"
 )
 
 type bss uint8
@@ -97,9 +96,10 @@ type bitstream struct {
 	state bss
 	stack bitstack
 
-	code bitcode
-	null bool
-	len  uint64
+	code  bitcode
+	null  bool
+	start uint64
+	len   uint64
 }
 
 // Init initializes this stream with the given bufio.Reader.
@@ -107,6 +107,13 @@ func (b *bitstream) Init(in *bufio.Reader) {
 	b.in = in
 }
 
+// InitAt initializes this stream with the given bufio.Reader, which is positioned
+// at the given offset in the original input.
+func (b *bitstream) InitAt(in *bufio.Reader, pos uint64) {
+	b.in = in
+	b.pos = pos
+}
+
 // InitBytes initializes this stream with the given bytes.
 func (b *bitstream) InitBytes(in []byte) {
 	b.in = bufio.NewReader(bytes.NewReader(in))
@@ -127,11 +134,21 @@ func (b *bitstream) Pos() uint64 {
 	return b.pos
 }
 
+// Start returns the position of the current value's type descriptor.
+func (b *bitstream) Start() uint64 {
+	return b.start
+}
+
 // Len returns the length of the current value.
 func (b *bitstream) Len() uint64 {
 	return b.len
 }
 
+// End returns the position just past the end of the current value.
+func (b *bitstream) End() uint64 {
+	return b.pos + b.len
+}
+
 // Next advances the stream to the next value.
 func (b *bitstream) Next() error {
 	// If we have an unread value, skip over it to get to the next one.
@@ -170,10 +187,12 @@ func (b *bitstream) Next() error {
 		return nil
 	}
 
+	b.start = b.pos - 1
+
 	// Parse the tag.
 	code, len := parseTag(c)
 	if code == bitcodeNone {
-		return &InvalidTagByteError{byte(c), b.pos - 1}
+		return &InvalidTagByteError{Byte: byte(c), Offset: b.pos - 1}
 	}
 
 	b.state = bssOnValue
@@ -183,7 +202,7 @@ func (b *bitstream) Next() error {
 		case 0:
 			// This value is actually a BVM. It's invalid if we're not at the top level.
 			if !b.stack.empty() {
-				return &SyntaxError{"invalid BVM in a container", b.pos - 1}
+				return &SyntaxError{Msg: "invalid BVM in a container", Offset: b.pos - 1}
 			}
 			b.code = bitcodeBVM
 			b.len = 3
@@ -191,7 +210,7 @@ func (b *bitstream) Next() error {
 
 		case 0x0F:
 			// No such thing as a null annotation.
-			return &InvalidTagByteError{byte(c), b.pos - 1}
+			return &InvalidTagByteError{Byte: byte(c), Offset: b.pos - 1}
 		}
 	}
 
@@ -205,7 +224,7 @@ func (b *bitstream) Next() error {
 			len = 0
 		default:
 			// Other forms are invalid.
-			return &InvalidTagByteError{byte(c), b.pos - 1}
+			return &InvalidTagByteError{Byte: byte(c), Offset: b.pos - 1}
 		}
 	}
 
@@ -231,7 +250,7 @@ func (b *bitstream) Next() error {
 
 	if len > rem {
 		msg := fmt.Sprintf("value overruns its container: %v vs %v", len, rem)
-		return &SyntaxError{msg, pos - 1}
+		return &SyntaxError{Msg: msg, Offset: pos - 1}
 	}
 
 	b.code = code
@@ -335,7 +354,7 @@ func (b *bitstream) ReadBVM() (byte, byte, error) {
 
 	if end != 0xEA {
 		msg := fmt.Sprintf("invalid BVM: 0xE0 0x%02X 0x%02X 0x%02X", major, minor, end)
-		return 0, 0, &SyntaxError{msg, b.pos - 4}
+		return 0, 0, &SyntaxError{Msg: msg, Offset: b.pos - 4}
 	}
 
 	b.state = bssBeforeValue
@@ -375,7 +394,7 @@ func (b *bitstream) ReadAnnotationIDs() ([]uint64, error) {
 	if b.len-lenlen <= alen {
 		// The size of the annotations is larger than the remaining free space inside the
 		// annotation container.
-		return nil, &SyntaxError{"malformed annotation", b.pos - lenlen}
+		return nil, &SyntaxError{Msg: "malformed annotation", Offset: b.pos - lenlen}
 	}
 
 	as := []uint64{}
@@ -464,7 +483,7 @@ func (b *bitstream) ReadFloat() (float64, error) {
 		ret = math.Float64frombits(ui)
 
 	default:
-		return 0, &SyntaxError{"invalid float size", b.pos - b.len}
+		return 0, &SyntaxError{Msg: "invalid float size", Offset: b.pos - b.len}
 	}
 
 	b.state = b.stateAfterValue()
@@ -491,55 +510,76 @@ func (b *bitstream) ReadDecimal() (*Decimal, error) {
 }
 
 // ReadTimestamp reads a timestamp value.
-func (b *bitstream) ReadTimestamp() (time.Time, error) {
+func (b *bitstream) ReadTimestamp() (*Timestamp, error) {
 	if b.code != bitcodeTimestamp {
 		panic("not a timestamp")
 	}
 
 	len := b.len
 
-	offset, olen, err := b.readVarIntLen(len)
+	offset, neg, olen, err := b.readVarIntLenSign(len)
 	if err != nil {
-		return time.Time{}, err
+		return nil, err
 	}
 	len -= olen
+	offsetKnown := offset != 0 || !neg
 
-	ts := []int{1, 1, 1, 0, 0, 0}
-	for i := 0; len > 0 && i < 6; i++ {
+	ts := [6]int{1, 1, 1, 0, 0, 0}
+	n := 0
+	for ; len > 0 && n < 6; n++ {
 		val, vlen, err := b.readVarUintLen(len)
 		if err != nil {
-			return time.Time{}, err
+			return nil, err
 		}
 		len -= vlen
-		ts[i] = int(val)
+		ts[n] = int(val)
+	}
+
+	var precision TimestampPrecision
+	switch n {
+	case 1:
+		precision = TimestampPrecisionYear
+	case 2:
+		precision = TimestampPrecisionMonth
+	case 3:
+		precision = TimestampPrecisionDay
+	case 5:
+		precision = TimestampPrecisionMinute
+	case 6:
+		precision = TimestampPrecisionSecond
+	default:
+		return nil, &SyntaxError{Msg: "invalid timestamp length", Offset: b.pos - b.len}
 	}
 
-	nsecs, err := b.readNsecs(len)
-	if err != nil {
-		return time.Time{}, err
+	var fraction *Decimal
+	if len > 0 {
+		fraction, err = b.readDecimal(len)
+		if err != nil {
+			return nil, err
+		}
+		if fraction.scale <= 0 && fraction.Sign() == 0 {
+			// A zero fraction with no digits after the decimal point is no fraction at all.
+			fraction = nil
+		} else {
+			precision = TimestampPrecisionFraction
+		}
 	}
 
 	b.state = b.stateAfterValue()
 	b.clear()
 
-	utc := time.Date(ts[0], time.Month(ts[1]), ts[2], ts[3], ts[4], ts[5], int(nsecs), time.UTC)
-	return utc.In(time.FixedZone("fixed", int(offset)*60)), nil
-}
-
-// ReadNsecs reads the fraction part of a timestamp and truncates it to nanoseconds.
-func (b *bitstream) readNsecs(len uint64) (int, error) {
-	d, err := b.readDecimal(len)
+	// Binary timestamps are stored in UTC; move them to their local offset.
+	val, err := newTimestampFields(ts, precision, fraction, 0, false)
 	if err != nil {
-		return 0, err
+		return nil, &SyntaxError{Msg: err.Error(), Offset: b.pos}
 	}
-
-	nsec, err := d.ShiftL(9).Trunc()
-	if err != nil || nsec < 0 || nsec > 999999999 {
-		msg := fmt.Sprintf("invalid timestamp fraction: %v", d)
-		return 0, &SyntaxError{msg, b.pos}
+	if offsetKnown && precision >= TimestampPrecisionMinute {
+		t := val.t.In(offsetZone(int(offset)))
+		val.t = t
+		val.offsetKnown = true
 	}
 
-	return int(nsec), nil
+	return val, nil
 }
 
 // ReadDecimal reads a decimal value of the given length: an exponent encoded as a
@@ -556,20 +596,24 @@ func (b *bitstream) readDecimal(len uint64) (*Decimal, error) {
 
 		if val > math.MaxInt32 || val < math.MinInt32 {
 			msg := fmt.Sprintf("decimal exponent out of range: %v", val)
-			return nil, &SyntaxError{msg, b.pos - vlen}
+			return nil, &SyntaxError{Msg: msg, Offset: b.pos - vlen}
 		}
 
 		exp = val
 		len -= vlen
 	}
 
+	neg := false
 	if len > 0 {
-		if err := b.readBigInt(len, coef); err != nil {
+		var err error
+		if neg, err = b.readBigInt(len, coef); err != nil {
 			return nil, err
 		}
 	}
 
-	return NewDecimal(coef, int32(exp)), nil
+	d := NewDecimal(coef, int32(exp))
+	d.negZero = neg && coef.Sign() == 0
+	return d, nil
 }
 
 // ReadSymbolID reads a symbol value.
@@ -579,7 +623,7 @@ func (b *bitstream) ReadSymbolID() (uint64, error) {
 	}
 
 	if b.len > 8 {
-		return 0, &SyntaxError{"symbol id too large", b.pos}
+		return 0, &SyntaxError{Msg: "symbol id too large", Offset: b.pos}
 	}
 
 	bs, err := b.readN(b.len)
@@ -632,6 +676,19 @@ func (b *bitstream) ReadBytes() ([]byte, error) {
 	return bs, nil
 }
 
+// ReadRaw reads the serialized contents of the current value without parsing them.
+func (b *bitstream) ReadRaw() ([]byte, error) {
+	bs, err := b.readN(b.len)
+	if err != nil {
+		return nil, err
+	}
+
+	b.state = b.stateAfterValue()
+	b.clear()
+
+	return bs, nil
+}
+
 // Clear clears the current code and len.
 func (b *bitstream) clear() {
 	b.code = bitcodeNone
@@ -640,11 +697,12 @@ func (b *bitstream) clear() {
 }
 
 // ReadBigInt reads a fixed-length integer of the given length and stores
-// the value in the given big.Int.
-func (b *bitstream) readBigInt(len uint64, ret *big.Int) error {
+// the value in the given big.Int. It returns true if the sign bit was set, which
+// distinguishes a negative zero.
+func (b *bitstream) readBigInt(len uint64, ret *big.Int) (bool, error) {
 	bs, err := b.readN(len)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	neg := (bs[0]&0x80 != 0)
@@ -658,7 +716,7 @@ func (b *bitstream) readBigInt(len uint64, ret *big.Int) error {
 		ret.Neg(ret)
 	}
 
-	return nil
+	return neg, nil
 }
 
 // ReadVarUint reads a variable-length-encoded uint.
@@ -679,7 +737,7 @@ func (b *bitstream) readVarUintLen(max uint64) (uint64, uint64, error) {
 
 	for {
 		if len >= max {
-			return 0, 0, &SyntaxError{"varuint too large", b.pos}
+			return 0, 0, &SyntaxError{Msg: "varuint too large", Offset: b.pos}
 		}
 
 		c, err := b.read1()
@@ -712,7 +770,7 @@ func (b *bitstream) skipVarUintLen(max uint64) (uint64, error) {
 	len := uint64(0)
 	for {
 		if len >= max {
-			return 0, &SyntaxError{"varuint too large", b.pos - len}
+			return 0, &SyntaxError{Msg: "varuint too large", Offset: b.pos - len}
 		}
 
 		c, err := b.read1()
@@ -745,8 +803,16 @@ func (b *bitstream) remaining() uint64 {
 // ReadVarIntLen reads a variable-length-encoded int of at most max bytes,
 // returning the value and its actual length in bytes
 func (b *bitstream) readVarIntLen(max uint64) (int64, uint64, error) {
+	val, _, len, err := b.readVarIntLenSign(max)
+	return val, len, err
+}
+
+// ReadVarIntLenSign reads a variable-length-encoded int of at most max bytes,
+// returning the value, whether its sign bit was set (which distinguishes -0
+// from 0), and its actual length in bytes.
+func (b *bitstream) readVarIntLenSign(max uint64) (int64, bool, uint64, error) {
 	if max == 0 {
-		return 0, 0, &SyntaxError{"varint too large", b.pos}
+		return 0, false, 0, &SyntaxError{Msg: "varint too large", Offset: b.pos}
 	}
 	if max > 10 {
 		max = 10
@@ -755,7 +821,7 @@ func (b *bitstream) readVarIntLen(max uint64) (int64, uint64, error) {
 	// Read the first byte, which contains the sign bit.
 	c, err := b.read1()
 	if err != nil {
-		return 0, 0, err
+		return 0, false, 0, err
 	}
 
 	sign := int64(1)
@@ -768,17 +834,17 @@ func (b *bitstream) readVarIntLen(max uint64) (int64, uint64, error) {
 
 	// Check if that was the last (only) byte.
 	if c&0x80 != 0 {
-		return val * sign, len, nil
+		return val * sign, sign < 0, len, nil
 	}
 
 	for {
 		if len >= max {
-			return 0, 0, &SyntaxError{"varint too large", b.pos - len}
+			return 0, false, 0, &SyntaxError{Msg: "varint too large", Offset: b.pos - len}
 		}
 
 		c, err := b.read1()
 		if err != nil {
-			return 0, 0, err
+			return 0, false, 0, err
 		}
 
 		val <<= 7
@@ -786,7 +852,7 @@ func (b *bitstream) readVarIntLen(max uint64) (int64, uint64, error) {
 		len++
 
 		if c&0x80 != 0 {
-			return val * sign, len, nil
+			return val * sign, sign < 0, len, nil
 		}
 	}
 }
@@ -837,11 +903,11 @@ func (b *bitstream) readN(n uint64) ([]byte, error) {
 	}
 
 	bs := make([]byte, n)
-	actual, err := b.in.Read(bs)
+	actual, err := io.ReadFull(b.in, bs)
 	b.pos += uint64(actual)
 
-	if err == io.EOF {
-		return nil, &UnexpectedEOFError{b.pos}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, &UnexpectedEOFError{Offset: b.pos}
 	}
 	if err != nil {
 		return nil, &IOError{err}
@@ -858,7 +924,7 @@ func (b *bitstream) read1() (int, error) {
 		return 0, err
 	}
 	if c == -1 {
-		return 0, &UnexpectedEOFError{b.pos}
+		return 0, &UnexpectedEOFError{Offset: b.pos}
 	}
 	return c, nil
 }
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/fernomac/ion-go"
+)
+
+// LoadCatalog builds a catalog from the shared symbol tables in the files in the
+// given directory, in name order. A table may import tables defined earlier. It
+// returns a nil catalog if dir is empty.
+func loadCatalog(dir string) (ion.Catalog, error) {
+	if dir == "" {
+		return nil, nil
+	}
+
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ssts := []ion.SharedSymbolTable{}
+	for _, info := range infos {
+		if info.IsDir() {
+			continue
+		}
+
+		file := filepath.Join(dir, info.Name())
+		ssts, err = loadSharedSymbolTables(file, ssts)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", file, err)
+		}
+	}
+
+	return ion.NewCatalog(ssts...), nil
+}
+
+// LoadSharedSymbolTables appends the shared symbol tables in the given file to
+// ssts, ignoring any other values.
+func loadSharedSymbolTables(file string, ssts []ion.SharedSymbolTable) ([]ion.SharedSymbolTable, error) {
+	in, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	r := ion.NewReader(in)
+	for r.Next() {
+		as := r.Annotations()
+		if r.Type() != ion.StructType || r.IsNull() || len(as) == 0 || as[0] != "$ion_shared_symbol_table" {
+			continue
+		}
+
+		sst, err := readSharedSymbolTable(r, ion.NewCatalog(ssts...))
+		if err != nil {
+			return nil, err
+		}
+		ssts = append(ssts, sst)
+	}
+
+	return ssts, r.Err()
+}
+
+// ReadSharedSymbolTable reads a $ion_shared_symbol_table struct, flattening the
+// symbols of any tables it imports (which must be in cat) into its own.
+func readSharedSymbolTable(r ion.Reader, cat ion.Catalog) (ion.SharedSymbolTable, error) {
+	if err := r.StepIn(); err != nil {
+		return nil, err
+	}
+
+	name := ""
+	version := 1
+	imports := []string{}
+	symbols := []string{}
+
+	for r.Next() {
+		var err error
+		switch r.FieldName() {
+		case "name":
+			if r.Type() == ion.StringType {
+				name, err = r.StringValue()
+			}
+		case "version":
+			if r.Type() == ion.IntType && !r.IsNull() {
+				version, err = r.IntValue()
+			}
+		case "imports":
+			imports, err = readImports(r, cat)
+		case "symbols":
+			symbols, err = readSymbols(r)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := r.Err(); err != nil {
+		return nil, err
+	}
+	if err := r.StepOut(); err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("shared symbol table has no name")
+	}
+	if version < 1 {
+		version = 1
+	}
+
+	return ion.NewSharedSymbolTable(name, version, append(imports, symbols...)), nil
+}
+
+// ReadImports reads the imports of a shared symbol table, returning their symbols.
+func readImports(r ion.Reader, cat ion.Catalog) ([]string, error) {
+	syms := []string{}
+	if r.Type() != ion.ListType || r.IsNull() {
+		return syms, nil
+	}
+	if err := r.StepIn(); err != nil {
+		return nil, err
+	}
+
+	for r.Next() {
+		if r.Type() != ion.StructType || r.IsNull() {
+			continue
+		}
+		if err := r.StepIn(); err != nil {
+			return nil, err
+		}
+
+		name := ""
+		version := 1
+		maxID := int64(-1)
+		for r.Next() {
+			var err error
+			switch r.FieldName() {
+			case "name":
+				if r.Type() == ion.StringType {
+					name, err = r.StringValue()
+				}
+			case "version":
+				if r.Type() == ion.IntType && !r.IsNull() {
+					version, err = r.IntValue()
+				}
+			case "max_id":
+				if r.Type() == ion.IntType && !r.IsNull() {
+					maxID, err = r.Int64Value()
+				}
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err := r.StepOut(); err != nil {
+			return nil, err
+		}
+
+		imp := cat.FindExact(name, version)
+		if imp == nil {
+			return nil, fmt.Errorf("imported table %v/%v not found", name, version)
+		}
+		if maxID >= 0 {
+			imp = imp.Adjust(uint64(maxID))
+		}
+		syms = append(syms, imp.Symbols()...)
+	}
+
+	if err := r.Err(); err != nil {
+		return nil, err
+	}
+	return syms, r.StepOut()
+}
+
+// ReadSymbols reads the symbols of a shared symbol table. Anything but a string
+// takes up a symbol ID with unknown text.
+func readSymbols(r ion.Reader) ([]string, error) {
+	syms := []string{}
+	if r.Type() != ion.ListType || r.IsNull() {
+		return syms, nil
+	}
+	if err := r.StepIn(); err != nil {
+		return nil, err
+	}
+
+	for r.Next() {
+		sym := ""
+		if r.Type() == ion.StringType && !r.IsNull() {
+			var err error
+			if sym, err = r.StringValue(); err != nil {
+				return nil, err
+			}
+		}
+		syms = append(syms, sym)
+	}
+
+	if err := r.Err(); err != nil {
+		return nil, err
+	}
+	return syms, r.StepOut()
+}
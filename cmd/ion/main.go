@@ -0,0 +1,251 @@
+// Command ion converts, pretty-prints, and counts Ion data.
+//
+// Usage:
+//
+// 	ion <command> [flags] [file ...]
+//
+// The commands are:
+//
+// 	convert   convert between Ion text and binary, or to JSON (-to text|binary|json)
+// 	pretty    pretty-print as Ion text
+// 	count     count top-level values
+//
+// Input is read from each of the named files in turn, or from standard input if
+// none are named (or a file is named -). Whether each input is text or binary is
+// detected automatically. Output goes to standard output.
+//
+// Every command accepts -catalog dir, naming a directory of files containing
+// $ion_shared_symbol_table structs that are used to resolve shared symbol tables
+// imported by the input.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fernomac/ion-go"
+)
+
+const usage = `usage: ion <command> [flags] [file ...]
+
+commands:
+  convert   convert between Ion text and binary, or to JSON (-to text|binary|json)
+  pretty    pretty-print as Ion text
+  count     count top-level values
+
+Run 'ion <command> -h' for a command's flags.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// Run runs the command given by args, returning the exit status.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	var cmd func([]string, io.Reader, io.Writer, io.Writer) error
+	switch args[0] {
+	case "convert":
+		cmd = convert
+	case "pretty":
+		cmd = pretty
+	case "count":
+		cmd = count
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "ion: unknown command %q\n\n%v", args[0], usage)
+		return 2
+	}
+
+	out := bufio.NewWriter(stdout)
+	err := cmd(args[1:], stdin, out, stderr)
+	if ferr := out.Flush(); err == nil {
+		err = ferr
+	}
+
+	switch {
+	case err == flag.ErrHelp:
+		return 0
+	case err == errUsage:
+		return 2
+	case err != nil:
+		fmt.Fprintf(stderr, "ion %v: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+// ErrUsage is returned by commands whose flags couldn't be parsed; the flag
+// package has already explained why.
+var errUsage = fmt.Errorf("usage error")
+
+// NewFlagSet creates a flag set for the given command, including the -catalog
+// flag that all commands share.
+func newFlagSet(name, args string, stderr io.Writer) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: ion %v [flags] %v\n\nflags:\n", name, args)
+		fs.PrintDefaults()
+	}
+	catalog := fs.String("catalog", "", "`dir`ectory of shared symbol tables used to resolve imports")
+	return fs, catalog
+}
+
+// ParseFlags parses a command's flags, translating parse errors to errUsage.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	if err != nil && err != flag.ErrHelp {
+		return errUsage
+	}
+	return err
+}
+
+// Convert copies its inputs to a single text or binary output stream.
+func convert(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs, catalog := newFlagSet("convert", "[file ...]", stderr)
+	to := fs.String("to", "text", "output `format`: text, binary, or json")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	var w ion.Writer
+	switch *to {
+	case "text":
+		w = ion.NewTextWriterOpts(stdout, ion.TextWriterQuietFinish)
+	case "binary":
+		w = ion.NewBinaryWriterStreaming(stdout)
+	case "json":
+		w = ion.NewTextWriterOpts(stdout, ion.TextWriterJSON|ion.TextWriterQuietFinish)
+	default:
+		return fmt.Errorf("unknown output format %q", *to)
+	}
+
+	err := copyInputs(w, fs.Args(), *catalog, stdin)
+	if err == nil && *to != "binary" {
+		_, err = io.WriteString(stdout, "\n")
+	}
+	return err
+}
+
+// Pretty copies its inputs to a single pretty-printed text output stream.
+func pretty(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs, catalog := newFlagSet("pretty", "[file ...]", stderr)
+	indent := fs.String("indent", "  ", "`string` to indent each level of nesting with")
+	width := fs.Int("width", 80, "maximum line `width` to aim for")
+	compact := fs.Bool("compact-lists", false, "write lists and sexps of scalars on one line when they fit")
+	wrap := fs.Bool("wrap-strings", false, "split long strings in lists and structs across lines")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	opts := ion.TextWriterQuietFinish | ion.TextWriterPretty
+	if *compact {
+		opts |= ion.TextWriterCompactLists
+	}
+	if *wrap {
+		opts |= ion.TextWriterWrapStrings
+	}
+
+	w := ion.NewTextWriterIndent(stdout, opts, *indent, *width)
+	err := copyInputs(w, fs.Args(), *catalog, stdin)
+	if err == nil {
+		_, err = io.WriteString(stdout, "\n")
+	}
+	return err
+}
+
+// Count counts the top-level values in its inputs.
+func count(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs, catalog := newFlagSet("count", "[file ...]", stderr)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	files := fs.Args()
+	total := 0
+
+	err := eachInput(files, *catalog, stdin, func(name string, r ion.Reader) error {
+		n := 0
+		for r.Next() {
+			n++
+		}
+		if err := r.Err(); err != nil {
+			return err
+		}
+
+		if len(files) > 1 {
+			fmt.Fprintf(stdout, "%v\t%v\n", n, name)
+		}
+		total += n
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(files) > 1 {
+		_, err = fmt.Fprintf(stdout, "%v\ttotal\n", total)
+	} else {
+		_, err = fmt.Fprintf(stdout, "%v\n", total)
+	}
+	return err
+}
+
+// CopyInputs copies the values from each input to the given writer and finishes it.
+func copyInputs(w ion.Writer, files []string, catalog string, stdin io.Reader) error {
+	err := eachInput(files, catalog, stdin, func(name string, r ion.Reader) error {
+		return ion.Copy(w, r)
+	})
+	if err != nil {
+		return err
+	}
+	return w.Finish()
+}
+
+// EachInput calls f with a Reader for each of the named files, or for stdin if
+// there are none.
+func eachInput(files []string, catalog string, stdin io.Reader, f func(string, ion.Reader) error) error {
+	cat, err := loadCatalog(catalog)
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	for _, file := range files {
+		if err := readInput(file, cat, stdin, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadInput calls f with a Reader for the named file, or for stdin if it's named -.
+func readInput(file string, cat ion.Catalog, stdin io.Reader, f func(string, ion.Reader) error) error {
+	in := stdin
+	if file != "-" {
+		fh, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer fh.Close()
+		in = fh
+	}
+
+	if err := f(file, ion.NewReaderCat(in, cat)); err != nil {
+		return fmt.Errorf("%v: %v", file, err)
+	}
+	return nil
+}
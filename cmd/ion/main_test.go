@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fernomac/ion-go"
+)
+
+func runIon(t *testing.T, stdin []byte, args ...string) string {
+	stdout := bytes.Buffer{}
+	stderr := bytes.Buffer{}
+
+	if status := run(args, bytes.NewReader(stdin), &stdout, &stderr); status != 0 {
+		t.Fatalf("ion %v exited with status %v: %v", strings.Join(args, " "), status, stderr.String())
+	}
+	return stdout.String()
+}
+
+func tempDir(t *testing.T, files map[string][]byte) string {
+	dir, err := ioutil.TempDir("", "ion")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestConvert(t *testing.T) {
+	in := []byte(`a::{b:(c [1, 2.0]), d:{{Zm9v}}} null.int`)
+
+	bin := runIon(t, in, "convert", "-to", "binary")
+	if !strings.HasPrefix(bin, "\xE0\x01\x00\xEA") {
+		t.Fatalf("expected binary output, got %q", bin)
+	}
+
+	text := runIon(t, []byte(bin), "convert")
+	if expected := "a::{b:(c [1,2.0]),d:{{Zm9v}}}\nnull.int\n"; text != expected {
+		t.Errorf("expected %q, got %q", expected, text)
+	}
+
+	json := runIon(t, []byte(bin), "convert", "-to", "json")
+	if expected := "{\"b\":[\"c\",[1,2.0]],\"d\":\"Zm9v\"}\nnull\n"; json != expected {
+		t.Errorf("expected %q, got %q", expected, json)
+	}
+}
+
+func TestPretty(t *testing.T) {
+	actual := runIon(t, []byte(`{a:[1,2],b:{}}`), "pretty", "-indent", "\t", "-compact-lists")
+	expected := "{\n\ta: [1, 2],\n\tb: {}\n}\n"
+	if actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
+
+func TestCount(t *testing.T) {
+	dir := tempDir(t, map[string][]byte{
+		"a.ion": []byte("1 2 [3, 4]"),
+		"b.ion": []byte("$ion_symbol_table::{symbols:[\"x\"]} $10"),
+	})
+	defer os.RemoveAll(dir)
+
+	actual := runIon(t, nil, "count", filepath.Join(dir, "a.ion"), filepath.Join(dir, "b.ion"))
+	expected := "3\t" + filepath.Join(dir, "a.ion") + "\n" +
+		"1\t" + filepath.Join(dir, "b.ion") + "\n" +
+		"4\ttotal\n"
+	if actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+
+	if actual := runIon(t, []byte("a b c"), "count"); actual != "3\n" {
+		t.Errorf("expected 3, got %q", actual)
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	dir := tempDir(t, map[string][]byte{
+		"1-base.ion": []byte(`$ion_shared_symbol_table::{name:"base", version:1, symbols:["foo"]}`),
+		"2-more.ion": []byte(`$ion_shared_symbol_table::{name:"more", version:2,
+			imports:[{name:"base", version:1, max_id:1}], symbols:["bar", 12]}`),
+	})
+	defer os.RemoveAll(dir)
+
+	cat, err := loadCatalog(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	more := cat.FindExact("more", 2)
+	if more == nil {
+		t.Fatal("expected to find more/2")
+	}
+	if syms := more.Symbols(); len(syms) != 3 || syms[0] != "foo" || syms[1] != "bar" || syms[2] != "" {
+		t.Errorf("expected [foo bar <unknown>], got %v", syms)
+	}
+
+	// Binary data that imports more/2.
+	buf := bytes.Buffer{}
+	w := ion.NewBinaryWriter(&buf, more)
+	w.WriteSymbol("bar")
+	if err := w.Finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	if actual := runIon(t, buf.Bytes(), "convert"); actual != "$11\n" {
+		t.Errorf("expected $11 without a catalog, got %q", actual)
+	}
+	if actual := runIon(t, buf.Bytes(), "convert", "-catalog", dir); actual != "bar\n" {
+		t.Errorf("expected bar with a catalog, got %q", actual)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	test := func(estatus int, args ...string) {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			stderr := bytes.Buffer{}
+			status := run(args, strings.NewReader("1"), &bytes.Buffer{}, &stderr)
+			if status != estatus {
+				t.Errorf("expected status %v, got %v", estatus, status)
+			}
+		})
+	}
+
+	test(2)
+	test(2, "bogus")
+	test(2, "count", "-bogus")
+	test(1, "convert", "-to", "bogus")
+	test(1, "count", "does-not-exist.ion")
+	test(0, "help")
+}
@@ -0,0 +1,558 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+)
+
+// A generator accumulates the code for a generated file.
+type generator struct {
+	pkg     *pkg
+	buf     bytes.Buffer
+	imports map[string]bool
+}
+
+// NewGenerator creates a generator for a file in the given package.
+func newGenerator(p *pkg) *generator {
+	return &generator{
+		pkg:     p,
+		imports: map[string]bool{ionPath: true},
+	}
+}
+
+// Printf appends to the generated code.
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// Check appends code that calls the given function, returning its error if any.
+func (g *generator) check(call string, args ...interface{}) {
+	g.printf("if err := "+call+"; err != nil {\nreturn err\n}\n", args...)
+}
+
+// Use records that the generated code uses the package with the given path.
+func (g *generator) use(path string) {
+	g.imports[path] = true
+}
+
+// TypeName returns the name of the given type, recording the imports it needs.
+func (g *generator) typeName(t *goType) string {
+	for e := t; e != nil; e = e.elem {
+		switch e.kind {
+		case timeKind:
+			g.use("time")
+		case bigIntKind:
+			g.use("math/big")
+		}
+	}
+	return t.name
+}
+
+// Source returns the formatted source of the generated file.
+func (g *generator) source(cmdline string) ([]byte, error) {
+	src := bytes.Buffer{}
+	fmt.Fprintf(&src, "// Code generated by \"iongen %v\"; DO NOT EDIT.\n\npackage %v\n\nimport (\n", cmdline, g.pkg.name)
+
+	paths := []string{}
+	for path := range g.imports {
+		if path != ionPath {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Fprintf(&src, "%q\n", path)
+	}
+	fmt.Fprintf(&src, "\n%q\n)\n", ionPath)
+
+	src.Write(g.buf.Bytes())
+
+	out, err := format.Source(src.Bytes())
+	if err != nil {
+		// Should never happen, but the unformatted source helps figure out why.
+		return src.Bytes(), fmt.Errorf("generated code does not parse: %v", err)
+	}
+	return out, nil
+}
+
+// Generate returns the source of a file holding MarshalIon and UnmarshalIon methods
+// for each of the named struct types.
+func generate(p *pkg, names []string, cmdline string) ([]byte, error) {
+	g := newGenerator(p)
+
+	for _, name := range names {
+		fs, err := p.fields(name)
+		if err != nil {
+			return nil, err
+		}
+		if err := g.marshal(name, fs); err != nil {
+			return nil, fmt.Errorf("%v: %v", name, err)
+		}
+		g.unmarshal(name, fs)
+	}
+
+	return g.source(cmdline)
+}
+
+// Marshal generates a MarshalIon method for the given type.
+func (g *generator) marshal(name string, fs []field) error {
+	g.printf("\n// MarshalIon writes v to w as an Ion struct.\n")
+	g.printf("func (v %v) MarshalIon(w ion.Writer) error {\n", name)
+
+	for _, f := range fs {
+		if f.annotations {
+			g.printf("for _, a := range v.%v {\nw.Annotation(a)\n}\n", f.expr)
+		}
+	}
+
+	g.printf("w.BeginStruct()\n")
+
+	for i := range fs {
+		f := &fs[i]
+		if f.annotations {
+			continue
+		}
+
+		x := "v." + f.expr
+		conds := []string{}
+		if f.omitEmpty {
+			cond, err := nonEmpty(f.typ, x)
+			if err != nil {
+				return fmt.Errorf("field %v: omitempty %v", f.expr, err)
+			}
+			if cond != "" {
+				conds = append(conds, cond)
+			}
+		}
+		if f.omitZero {
+			cond, err := g.nonZero(f.typ, x)
+			if err != nil {
+				return fmt.Errorf("field %v: omitzero %v", f.expr, err)
+			}
+			conds = append(conds, cond)
+		}
+
+		if len(conds) > 0 {
+			g.printf("if %v {\n", strings.Join(conds, " && "))
+		}
+		g.printf("w.FieldName(%q)\n", f.name)
+		g.encode(f.typ, x, f, 0)
+		if len(conds) > 0 {
+			g.printf("}\n")
+		}
+	}
+
+	g.printf("return w.EndStruct()\n}\n")
+	return nil
+}
+
+// NonEmpty returns a condition that's true if x isn't empty, as the Encoder decides
+// for omitempty, or "" if x can never be empty.
+func nonEmpty(t *goType, x string) (string, error) {
+	switch t.kind {
+	case stringKind, bytesKind, sliceKind, mapKind, arrayKind:
+		return fmt.Sprintf("len(%v) != 0", x), nil
+	case boolKind:
+		return x, nil
+	case intKind, uintKind, floatKind:
+		return fmt.Sprintf("%v != 0", x), nil
+	case ptrKind, interfaceKind:
+		return fmt.Sprintf("%v != nil", x), nil
+	case timeKind, decimalKind, bigIntKind, structKind:
+		return "", nil
+	}
+	return "", fmt.Errorf("is not supported for its type")
+}
+
+// NonZero returns a condition that's true if x isn't the zero value, as the Encoder
+// decides for omitzero.
+func (g *generator) nonZero(t *goType, x string) (string, error) {
+	if t.local != "" && g.pkg.hasMethod(t.local, "IsZero") {
+		return fmt.Sprintf("!%v.IsZero()", x), nil
+	}
+
+	switch t.kind {
+	case timeKind:
+		return fmt.Sprintf("!%v.IsZero()", x), nil
+	case bytesKind, sliceKind, mapKind, ptrKind, interfaceKind:
+		return fmt.Sprintf("%v != nil", x), nil
+	case boolKind, intKind, uintKind, floatKind, stringKind:
+		return nonEmpty(t, x)
+	}
+	return "", fmt.Errorf("is not supported for its type")
+}
+
+// Encode generates code that writes x, of the given type, to w, applying the options
+// of field f (which may be nil).
+func (g *generator) encode(t *goType, x string, f *field, depth int) {
+	if !t.direct() {
+		g.check("ion.MarshalTo(w, %v)", x)
+		return
+	}
+
+	if f == nil {
+		f = &field{}
+	}
+
+	// Only an index expression needs a dereferenced pointer in parentheses.
+	ix, x := x, unparen(x)
+
+	switch t.kind {
+	case boolKind:
+		if f.str {
+			g.use("strconv")
+			g.check("w.WriteString(strconv.FormatBool(%v))", x)
+		} else {
+			g.check("w.WriteBool(%v)", x)
+		}
+
+	case intKind:
+		val := convert("int64", t, x)
+		if f.str {
+			g.use("strconv")
+			g.check("w.WriteString(strconv.FormatInt(%v, 10))", val)
+		} else {
+			g.check("w.WriteInt(%v)", val)
+		}
+
+	case uintKind:
+		switch {
+		case f.str:
+			g.use("strconv")
+			g.check("w.WriteString(strconv.FormatUint(%v, 10))", convert("uint64", t, x))
+		case t.bits == 0 || t.bits == 64:
+			g.check("w.WriteUint(%v)", convert("uint64", t, x))
+		default:
+			g.check("w.WriteInt(int64(%v))", x)
+		}
+
+	case floatKind:
+		switch {
+		case f.str:
+			g.use("strconv")
+			g.check("w.WriteString(strconv.FormatFloat(%v, 'g', -1, %v))", convert("float64", t, x), t.bits)
+		case t.bits == 32:
+			g.check("w.WriteFloat32(%v)", x)
+		default:
+			g.check("w.WriteFloat(%v)", x)
+		}
+
+	case stringKind:
+		switch {
+		case f.symbol:
+			g.check("w.WriteSymbol(%v)", x)
+		case f.lob == "blob":
+			g.check("w.WriteBlob([]byte(%v))", x)
+		case f.lob == "clob":
+			g.check("w.WriteClob([]byte(%v))", x)
+		default:
+			g.check("w.WriteString(%v)", x)
+		}
+
+	case bytesKind:
+		g.printf("if %v == nil {\n", x)
+		g.check("w.WriteNull()")
+		if f.lob == "clob" {
+			g.printf("} else if err := w.WriteClob(%v); err != nil {\nreturn err\n}\n", x)
+		} else {
+			g.printf("} else if err := w.WriteBlob(%v); err != nil {\nreturn err\n}\n", x)
+		}
+
+	case timeKind:
+		g.check("w.WriteTimestamp(%v)", x)
+
+	case decimalKind:
+		g.check("w.WriteDecimal(%v)", addr(x))
+
+	case bigIntKind:
+		g.check("w.WriteBigInt(%v)", addr(x))
+
+	case ptrKind:
+		g.printf("if %v == nil {\n", x)
+		g.check("w.WriteNull()")
+		g.printf("} else {\n")
+		g.encode(t.elem, "(*"+x+")", f, depth)
+		g.printf("}\n")
+
+	case sliceKind:
+		e := fmt.Sprintf("e%v", depth)
+		g.printf("if %v == nil {\n", x)
+		g.check("w.WriteNull()")
+		g.printf("} else {\nw.BeginList()\nfor _, %v := range %v {\n", e, x)
+		g.encode(t.elem, e, f, depth+1)
+		g.printf("}\n")
+		g.check("w.EndList()")
+		g.printf("}\n")
+
+	case mapKind:
+		// Map entries don't get the field's options, and are always sorted.
+		g.use("sort")
+		ks, k, e := fmt.Sprintf("ks%v", depth), fmt.Sprintf("k%v", depth), fmt.Sprintf("e%v", depth)
+		g.printf("if %v == nil {\n", x)
+		g.check("w.WriteNull()")
+		g.printf("} else {\n")
+		g.printf("%v := make([]string, 0, len(%v))\nfor %v := range %v {\n%v = append(%v, %v)\n}\nsort.Strings(%v)\n",
+			ks, x, k, x, ks, ks, k, ks)
+		g.printf("w.BeginStruct()\nfor _, %v := range %v {\nw.FieldName(%v)\n%v := %v[%v]\n", k, ks, k, e, ix, k)
+		g.encode(t.elem, e, nil, depth+1)
+		g.printf("}\n")
+		g.check("w.EndStruct()")
+		g.printf("}\n")
+	}
+}
+
+// Convert returns x converted to the named type, unless it already is one.
+func convert(name string, t *goType, x string) string {
+	if t.name == name {
+		return x
+	}
+	return fmt.Sprintf("%v(%v)", name, x)
+}
+
+// Addr returns an expression for the address of x.
+func addr(x string) string {
+	x = unparen(x)
+	if strings.HasPrefix(x, "*") {
+		return x[1:]
+	}
+	return "&" + x
+}
+
+// Unparen removes the parentheses around a dereferenced pointer, which are only
+// needed to index it.
+func unparen(x string) string {
+	if strings.HasPrefix(x, "(*") && strings.HasSuffix(x, ")") {
+		return x[1 : len(x)-1]
+	}
+	return x
+}
+
+// Unmarshal generates an UnmarshalIon method for the given type.
+func (g *generator) unmarshal(name string, fs []field) {
+	g.use("fmt")
+	g.printf("\n// UnmarshalIon sets v's fields from the Ion struct r is positioned on.\n")
+	g.printf("func (v *%v) UnmarshalIon(r ion.Reader) error {\n", name)
+	g.printf("if r.IsNull() {\n*v = %v{}\nreturn nil\n}\n", name)
+	g.printf("if r.Type() != ion.StructType {\nreturn fmt.Errorf(\"ion: cannot unmarshal %%v to %v\", r.Type())\n}\n", name)
+	g.printf("opts := ion.DecoderOptsOf(r)\n")
+
+	annotations := false
+	for _, f := range fs {
+		if !f.annotations {
+			continue
+		}
+		if !annotations {
+			// The Encoder writes the type's own annotations first, so leave them out.
+			g.printf("\nas := r.Annotations()\nif a, ok := interface{}(v).(ion.Annotated); ok {\n")
+			g.printf("static := a.IonAnnotations()\ni := 0\n")
+			g.printf("for i < len(static) && i < len(as) && as[i] == static[i] {\ni++\n}\n")
+			g.printf("if i == len(static) {\nas = as[i:]\n}\n}\n")
+			annotations = true
+		}
+		g.printf("v.%v = append([]string(nil), as...)\n", f.expr)
+	}
+
+	g.printf("\n")
+	g.check("r.StepIn()")
+
+	names := []string{}
+	for _, f := range fs {
+		if !f.annotations {
+			names = append(names, fmt.Sprintf("%q", f.name))
+		}
+	}
+
+	// Reject unknown and duplicate fields like the Decoder does, if it's told to.
+	unknown := fmt.Sprintf("if opts&ion.DecodeDisallowUnknownFields != 0 {\n"+
+		"return fmt.Errorf(\"ion: unknown field %%q in %v\", r.FieldName())\n}\n", name)
+
+	if len(names) == 0 {
+		g.printf("\nfor r.Next() {\n%v}\n\nreturn r.StepOut()\n}\n", unknown)
+		return
+	}
+
+	g.printf("\nvar seen [%v]bool\nfor r.Next() {\n", len(names))
+
+	// Match the field name exactly if possible, or else case-insensitively.
+	g.use("strings")
+	g.printf("name := r.FieldName()\nswitch name {\ncase %v:\n", strings.Join(names, ", "))
+	g.printf("default:\nif opts&ion.DecodeCaseSensitiveFields == 0 {\nswitch {\n")
+	for _, n := range names {
+		g.printf("case strings.EqualFold(name, %v):\nname = %v\n", n, n)
+	}
+	g.printf("}\n}\n}\n\nswitch name {\n")
+
+	i := 0
+	for _, f := range fs {
+		if f.annotations {
+			continue
+		}
+		g.printf("case %q:\n", f.name)
+		g.printf("if seen[%v] && opts&ion.DecodeRejectDuplicateFields != 0 {\n", i)
+		g.printf("return fmt.Errorf(\"ion: duplicate field %%q in %v\", r.FieldName())\n}\nseen[%v] = true\n", name, i)
+		g.decode(f.typ, "v."+f.expr, f.str, 0)
+		i++
+	}
+	g.printf("default:\n%v}\n}\n\nreturn r.StepOut()\n}\n", unknown)
+}
+
+// Decode generates code that decodes the current value to lv, of the given type,
+// which is a string-encoded bool or number if str is set.
+func (g *generator) decode(t *goType, lv string, str bool, depth int) {
+	lv = unparen(lv)
+	if !t.direct() {
+		g.check("ion.UnmarshalCurrent(r, %v)", addr(lv))
+		return
+	}
+
+	if t.kind == ptrKind {
+		g.printf("if r.IsNull() {\n%v = nil\n} else {\n", lv)
+		g.printf("if %v == nil {\n%v = new(%v)\n}\n", lv, lv, g.typeName(t.elem))
+		g.decodeValue(t.elem, "(*"+lv+")", str, depth)
+		g.printf("}\n")
+		return
+	}
+
+	g.printf("if r.IsNull() {\n%v = %v\n} else ", lv, g.zero(t))
+	g.decodeValue(t, lv, str, depth)
+}
+
+// Zero returns the zero value of the given type.
+func (g *generator) zero(t *goType) string {
+	switch t.kind {
+	case boolKind:
+		return "false"
+	case intKind, uintKind, floatKind:
+		return "0"
+	case stringKind:
+		return `""`
+	case timeKind, decimalKind, bigIntKind:
+		return g.typeName(t) + "{}"
+	}
+	return "nil"
+}
+
+// DecodeValue generates code that decodes the current non-null value to lv, falling
+// back to reflection if it's not of the Ion type that lv's type is encoded as.
+func (g *generator) decodeValue(t *goType, lv string, str bool, depth int) {
+	ix, lv := lv, unparen(lv)
+
+	if str {
+		var parse, parsed string
+		switch t.kind {
+		case boolKind:
+			parse, parsed = "strconv.ParseBool(s)", "bool"
+		case intKind:
+			parse, parsed = fmt.Sprintf("strconv.ParseInt(s, 10, %v)", t.bits), "int64"
+		case uintKind:
+			parse, parsed = fmt.Sprintf("strconv.ParseUint(s, 10, %v)", t.bits), "uint64"
+		case floatKind:
+			parse, parsed = fmt.Sprintf("strconv.ParseFloat(s, %v)", t.bits), "float64"
+		}
+
+		if parse != "" {
+			g.use("strconv")
+			g.printf("if r.Type() == ion.StringType {\ns, err := r.StringValue()\nif err != nil {\nreturn err\n}\n")
+			g.printf("x, err := %v\nif err != nil {\nreturn err\n}\n%v = %v\n} else ",
+				parse, lv, convert(t.name, &goType{name: parsed}, "x"))
+		}
+	}
+
+	switch t.kind {
+	case boolKind:
+		g.scalar(lv, "ion.BoolType", "BoolValue", "", "x")
+
+	case intKind:
+		fits := ""
+		if t.bits != 64 {
+			fits = fmt.Sprintf("int64(%v(x)) == x", t.name)
+		}
+		g.scalar(lv, "ion.IntType", "Int64Value", fits, convert(t.name, &goType{name: "int64"}, "x"))
+
+	case uintKind:
+		if t.bits == 0 || t.bits == 64 {
+			fits := "x.IsUint64()"
+			if t.bits == 0 {
+				fits += fmt.Sprintf(" && uint64(%v(x.Uint64())) == x.Uint64()", t.name)
+			}
+			g.scalar(lv, "ion.IntType", "BigIntValue", fits, convert(t.name, &goType{name: "uint64"}, "x.Uint64()"))
+		} else {
+			fits := fmt.Sprintf("x >= 0 && int64(%v(x)) == x", t.name)
+			g.scalar(lv, "ion.IntType", "Int64Value", fits, fmt.Sprintf("%v(x)", t.name))
+		}
+
+	case floatKind:
+		if t.bits == 32 {
+			g.use("math")
+			fits := "float64(float32(x)) == x || opts&ion.DecodeDisallowLossyNumbers == 0 && " +
+				"(!(math.Abs(x) > math.MaxFloat32) || math.IsInf(x, 0))"
+			g.scalar(lv, "ion.FloatType", "FloatValue", fits, "float32(x)")
+		} else {
+			g.scalar(lv, "ion.FloatType", "FloatValue", "", "x")
+		}
+
+	case stringKind:
+		g.scalar(lv, "ion.StringType || r.Type() == ion.SymbolType", "StringValue", "", "x")
+
+	case bytesKind:
+		g.scalar(lv, "ion.BlobType || r.Type() == ion.ClobType", "ByteValue", "", "x")
+
+	case timeKind:
+		g.scalar(lv, "ion.TimestampType", "TimeValue", "", "x")
+
+	case decimalKind:
+		g.scalar(lv, "ion.DecimalType", "DecimalValue", "", "*x")
+
+	case bigIntKind:
+		g.scalar(lv, "ion.IntType", "BigIntValue", "", "*x")
+
+	case sliceKind:
+		s, n := fmt.Sprintf("s%v", depth), fmt.Sprintf("n%v", depth)
+		g.printf("if r.Type() == ion.ListType || r.Type() == ion.SexpType {\n")
+		g.check("r.StepIn()")
+		g.printf("%v, %v := %v, 0\nfor r.Next() {\n", s, n, lv)
+		g.printf("if %v == len(%v) {\nvar z %v\n%v = append(%v, z)\n}\n", n, s, g.typeName(t.elem), s, s)
+		g.decode(t.elem, fmt.Sprintf("%v[%v]", s, n), str, depth+1)
+		g.printf("%v++\n}\n", n)
+		g.check("r.StepOut()")
+		g.printf("%v = %v[:%v]\n", lv, s, n)
+		g.fallback(lv)
+
+	case mapKind:
+		k, e, seen := fmt.Sprintf("k%v", depth), fmt.Sprintf("e%v", depth), fmt.Sprintf("seen%v", depth)
+		g.printf("if r.Type() == ion.StructType {\n")
+		g.printf("if %v == nil {\n%v = make(%v)\n}\n", lv, lv, g.typeName(t))
+		g.printf("var %v map[string]bool\nif opts&ion.DecodeRejectDuplicateFields != 0 {\n%v = map[string]bool{}\n}\n", seen, seen)
+		g.check("r.StepIn()")
+		g.printf("for r.Next() {\n%v := r.FieldName()\n", k)
+		g.printf("if %v != nil {\nif %v[%v] {\nreturn fmt.Errorf(\"ion: duplicate field %%q in %%v\", %v, %q)\n}\n%v[%v] = true\n}\n",
+			seen, seen, k, k, g.typeName(t), seen, k)
+		g.printf("var %v %v\n", e, g.typeName(t.elem))
+		g.decode(t.elem, e, false, depth+1)
+		g.printf("%v[%v] = %v\n}\n", ix, k, e)
+		g.check("r.StepOut()")
+		g.fallback(lv)
+	}
+}
+
+// Scalar generates code that reads a scalar of the given Ion type with the given
+// Reader method, and if it fits, sets lv to val (an expression using the value x).
+func (g *generator) scalar(lv, typ, read, fits, val string) {
+	g.printf("if r.Type() == %v {\nx, err := r.%v()\nif err != nil {\nreturn err\n}\n", typ, read)
+	if fits != "" {
+		g.printf("if %v {\n%v = %v\n} else if err := ion.UnmarshalCurrent(r, %v); err != nil {\nreturn err\n}\n",
+			fits, lv, val, addr(lv))
+	} else {
+		g.printf("%v = %v\n", lv, val)
+	}
+	g.fallback(lv)
+}
+
+// Fallback closes off the code decoding the Ion type lv's type is normally encoded
+// as, falling back to reflection for anything else.
+func (g *generator) fallback(lv string) {
+	g.printf("} else if err := ion.UnmarshalCurrent(r, %v); err != nil {\nreturn err\n}\n", addr(lv))
+}
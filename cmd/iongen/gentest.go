@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GenerateTest returns the source of a test file checking that the methods generated
+// for the named types encode and decode the same Ion, to the same values, as the
+// Encoder and Decoder do for a copy of the type without them.
+func generateTest(p *pkg, names []string, cmdline string) ([]byte, error) {
+	g := newGenerator(p)
+	g.use("math/rand")
+	g.use("reflect")
+	g.use("testing")
+
+	listed := map[string]bool{}
+	for _, name := range names {
+		listed[name] = true
+	}
+
+	for _, name := range names {
+		fs, err := p.fields(name)
+		if err != nil {
+			return nil, err
+		}
+		g.plainType(name)
+		g.test(name)
+		g.random(name, fs, listed)
+	}
+
+	g.use("strings")
+	g.printf("\nvar iongenOpts = []ion.DecoderOpts{\n0,\n")
+	g.printf("ion.DecodeCaseSensitiveFields | ion.DecodeDisallowUnknownFields,\n")
+	g.printf("ion.DecodeRejectDuplicateFields | ion.DecodeDisallowLossyNumbers,\n")
+	g.printf("ion.DecodeStrictAnnotations | ion.DecodeUseNumber | ion.DecodeConvertNumbers,\n}\n")
+
+	g.printf("\nfunc iongenVariants(t *testing.T, data []byte) [][]byte {\n")
+	g.printf("r := ion.NewReaderBytes(data)\nr.Next()\nv, err := ion.ReadValue(r)\nif err != nil {\nt.Fatal(err)\n}\n\n")
+	g.printf("variants := [][]byte{data}\nvariant := func(fs []ion.Field) {\n")
+	g.printf("s := ion.NewStructValue(fs...)\ns.SetAnnotationSymbols(v.AnnotationSymbols()...)\n")
+	g.printf("variants = append(variants, []byte(s.String()))\n}\n\n")
+	g.printf("fs := v.Fields()\n")
+	g.printf("variant(append(fs, ion.Field{Name: ion.NewSymbolToken(\"iongenUnknown\"), Value: ion.NewIntValue(1)}))\n")
+	g.printf("if len(fs) > 0 {\nname := *fs[0].Name.Text\nother := strings.ToUpper(name)\n")
+	g.printf("if other == name {\nother = strings.ToLower(name)\n}\n")
+	g.printf("variant(append([]ion.Field{{Name: ion.NewSymbolToken(other), Value: fs[0].Value}}, fs[1:]...))\n")
+	g.printf("variant(append(fs, fs[0]))\n}\nreturn variants\n}\n")
+
+	g.printf("\nfunc iongenString(rnd *rand.Rand) string {\n")
+	g.printf("chars := []rune(\"abcXYZ019 _-'\\\"\\\\\\n\\t\\u00e9\\u4e16\\U0001f600\")\n")
+	g.printf("s := make([]rune, rnd.Intn(8))\nfor i := range s {\ns[i] = chars[rnd.Intn(len(chars))]\n}\nreturn string(s)\n}\n")
+
+	return g.source(cmdline)
+}
+
+// PlainType generates a copy of the named type without its methods, which the
+// Encoder and Decoder handle reflectively. It keeps the type's annotations.
+func (g *generator) plainType(name string) {
+	g.printf("\ntype iongenPlain%v %v\n", name, name)
+	if g.pkg.hasMethod(name, "IonAnnotations") {
+		g.printf("\nfunc (v iongenPlain%v) IonAnnotations() []string {\nt := %v(v)\nreturn t.IonAnnotations()\n}\n", name, name)
+	}
+}
+
+// Test generates a test comparing the generated methods of the named type to
+// the Encoder and Decoder, for the zero value and a set of random values.
+func (g *generator) test(name string) {
+	plain := "iongenPlain" + name
+
+	g.printf("\nfunc TestIongen%v(t *testing.T) {\n", name)
+	g.printf("rnd := rand.New(rand.NewSource(1))\nvalues := []%v{{}}\n", name)
+	g.printf("for i := 0; i < 100; i++ {\nvalues = append(values, iongenRand%v(rnd, 0))\n}\n\n", name)
+
+	g.printf("for _, v := range values {\n")
+	g.printf("want, err := ion.MarshalText(%v(v))\nif err != nil {\nt.Fatal(err)\n}\n", plain)
+	g.printf("got, err := ion.MarshalText(v)\nif err != nil {\nt.Fatal(err)\n}\n")
+	g.printf("if string(got) != string(want) {\nt.Fatalf(\"MarshalIon wrote %%s, expected %%s\", got, want)\n}\n\n")
+
+	// Decode the binary, the text, and the text with unknown, differently cased and
+	// duplicate fields, with a few sets of options.
+	g.printf("bin, err := ion.MarshalBinary(%v(v))\nif err != nil {\nt.Fatal(err)\n}\n", plain)
+	g.printf("for _, data := range append([][]byte{bin}, iongenVariants(t, want)...) {\n")
+	g.printf("for _, opts := range iongenOpts {\n")
+	g.printf("wantv := %v{}\nwanterr := ion.NewDecoderOpts(ion.NewReaderBytes(data), opts).DecodeTo(&wantv)\n", plain)
+	g.printf("gotv := %v{}\ngoterr := ion.NewDecoderOpts(ion.NewReaderBytes(data), opts).DecodeTo(&gotv)\n", name)
+	g.printf("if (goterr == nil) != (wanterr == nil) {\n")
+	g.printf("t.Fatalf(\"UnmarshalIon returned %%v decoding %%s with opts %%v, expected %%v\", goterr, data, opts, wanterr)\n}\n")
+	g.printf("if wanterr == nil && !reflect.DeepEqual(gotv, %v(wantv)) {\n", name)
+	g.printf("t.Fatalf(\"UnmarshalIon decoded %%+v with opts %%v, expected %%+v\", gotv, opts, wantv)\n}\n}\n}\n}\n}\n")
+}
+
+// Random generates a function returning a random value of the named type, with
+// random values for the fields whose types it knows how to make up.
+func (g *generator) random(name string, fs []field, listed map[string]bool) {
+	g.printf("\nfunc iongenRand%v(rnd *rand.Rand, depth int) %v {\nv := %v{}\n", name, name, name)
+	for _, f := range fs {
+		x := "v." + f.expr
+		if f.annotations {
+			g.printf("if rnd.Intn(2) == 0 {\n%v = []string{\"x\" + iongenString(rnd)}\n}\n", x)
+			continue
+		}
+		if canRandom(f.typ, listed) {
+			g.randomValue(f.typ, x, listed, 0)
+		}
+	}
+	g.printf("return v\n}\n")
+}
+
+// CanRandom returns true if randomValue can make up values of the given type.
+func canRandom(t *goType, listed map[string]bool) bool {
+	if t.kind == structKind {
+		return listed[t.local]
+	}
+	if t.local != "" || t.name == "" {
+		// Named types might not accept just any value of their underlying type.
+		return false
+	}
+
+	switch t.kind {
+	case ptrKind, sliceKind, mapKind:
+		return canRandom(t.elem, listed)
+	}
+	return t.direct()
+}
+
+// RandomValue generates code that sets x to a random value of the given type.
+func (g *generator) randomValue(t *goType, x string, listed map[string]bool, depth int) {
+	x = unparen(x)
+
+	switch t.kind {
+	case structKind:
+		g.printf("%v = iongenRand%v(rnd, depth+1)\n", x, t.local)
+
+	case boolKind:
+		g.printf("%v = rnd.Intn(2) == 0\n", x)
+
+	case intKind, uintKind:
+		g.printf("%v = %v(rnd.Uint64())\n", x, t.name)
+
+	case floatKind:
+		g.printf("%v = %v\n", x, convert(t.name, &goType{name: "float64"}, "rnd.NormFloat64()"))
+
+	case stringKind:
+		g.printf("%v = iongenString(rnd)\n", x)
+
+	case bytesKind:
+		g.printf("if rnd.Intn(4) > 0 {\n%v = make([]byte, rnd.Intn(8))\nrnd.Read(%v)\n}\n", x, x)
+
+	case timeKind:
+		g.use("time")
+		g.printf("%v = time.Unix(rnd.Int63n(1<<33), rnd.Int63n(1e9)).UTC()\n", x)
+
+	case decimalKind:
+		g.use("math/big")
+		g.printf("%v = *ion.NewDecimal(big.NewInt(rnd.Int63n(2000001)-1000000), int32(rnd.Intn(11)-5))\n", x)
+
+	case bigIntKind:
+		g.printf("%v.SetInt64(rnd.Int63() - rnd.Int63())\n", operand(x))
+
+	case ptrKind:
+		g.printf("if depth < 3 && rnd.Intn(4) > 0 {\n%v = new(%v)\n", x, g.typeName(t.elem))
+		g.randomValue(t.elem, "(*"+x+")", listed, depth)
+		g.printf("}\n")
+
+	case sliceKind:
+		i := fmt.Sprintf("i%v", depth)
+		g.printf("if depth < 3 && rnd.Intn(4) > 0 {\n%v = make(%v, rnd.Intn(4))\n", x, g.typeName(t))
+		g.printf("for %v := range %v {\n", i, x)
+		g.randomValue(t.elem, fmt.Sprintf("%v[%v]", operand(x), i), listed, depth+1)
+		g.printf("}\n}\n")
+
+	case mapKind:
+		i, e := fmt.Sprintf("i%v", depth), fmt.Sprintf("e%v", depth)
+		g.printf("if depth < 3 && rnd.Intn(4) > 0 {\n%v = %v{}\n", x, g.typeName(t))
+		g.printf("for %v := rnd.Intn(4); %v > 0; %v-- {\nvar %v %v\n", i, i, i, e, g.typeName(t.elem))
+		g.randomValue(t.elem, e, listed, depth+1)
+		// Writers take an empty field name to mean none, so keys can't be empty.
+		g.printf("%v[\"k\"+iongenString(rnd)] = %v\n}\n}\n", operand(x), e)
+	}
+}
+
+// Operand returns x in parentheses if it's a dereferenced pointer, so that it can
+// be indexed or have a method called on it.
+func operand(x string) string {
+	if strings.HasPrefix(x, "*") {
+		return "(" + x + ")"
+	}
+	return x
+}
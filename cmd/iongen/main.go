@@ -0,0 +1,111 @@
+// Command iongen generates MarshalIon and UnmarshalIon methods for struct types, so
+// that the Encoder and Decoder can handle them without reflection.
+//
+// Usage:
+//
+// 	iongen -type T[,U...] [-output file] [-test] [dir]
+//
+// Iongen reads the Go package in dir (by default the current directory) and writes
+// methods for each of the named struct types to a single file, by default
+// <t>_ion.go, where <t> is the first type's name in lower case. It's designed to be
+// run by go generate:
+//
+// 	//go:generate iongen -type Order,Item
+//
+// The generated code follows the same field tag rules as the Encoder and Decoder,
+// and writes the same Ion. It decodes the same Ion to the same values as the Decoder
+// calling it, honoring the Decoder's options, which it gets with ion.DecoderOptsOf
+// (so only when decoding from one of package ion's own Readers).
+//
+// The generated code handles bools, numbers, strings, []bytes, time.Times,
+// ion.Decimals, big.Ints, and pointers to them itself, along with slices and
+// string-keyed maps of those or of types declared in the same package. Other values
+// are encoded and decoded reflectively, with ion.MarshalTo and ion.UnmarshalCurrent.
+// Unlike MarshalTo, the generated MarshalIon always writes the entries of the maps
+// it handles sorted by key.
+//
+// The decimal and timestamp= tag options aren't supported, nor are embedded struct
+// pointers or embedded structs from other packages.
+//
+// With -test, iongen also writes a test file, <t>_ion_test.go, that checks the
+// generated methods against the Encoder and Decoder for the zero value and random
+// values of each type, decoding them with several sets of options.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stderr))
+}
+
+// Run runs iongen with the given args, returning the exit status.
+func run(args []string, stderr io.Writer) int {
+	fs := flag.NewFlagSet("iongen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: iongen -type T[,U...] [flags] [dir]\n\nflags:\n")
+		fs.PrintDefaults()
+	}
+	types := fs.String("type", "", "comma-separated list of struct `types` to generate methods for")
+	output := fs.String("output", "", "output `file`; default <dir>/<type>_ion.go")
+	test := fs.Bool("test", false, "also write a test checking the generated methods against the Encoder and Decoder")
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if *types == "" || fs.NArg() > 1 {
+		fs.Usage()
+		return 2
+	}
+
+	if err := generateFiles(strings.Split(*types, ","), *output, fs.Arg(0), *test, strings.Join(args, " ")); err != nil {
+		fmt.Fprintf(stderr, "iongen: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// GenerateFiles generates the methods for the named types in the package in dir,
+// and optionally their test, writing them to output.
+func generateFiles(names []string, output, dir string, test bool, cmdline string) error {
+	if dir == "" {
+		dir = "."
+	}
+	if output == "" {
+		output = filepath.Join(dir, strings.ToLower(names[0])+"_ion.go")
+	}
+	testOutput := strings.TrimSuffix(output, ".go") + "_test.go"
+
+	p, err := parsePackage(dir, output, testOutput)
+	if err != nil {
+		return err
+	}
+
+	src, err := generate(p, names, cmdline)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(output, src, 0644); err != nil {
+		return err
+	}
+
+	if test {
+		src, err := generateTest(p, names, cmdline)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(testOutput, src, 0644)
+	}
+	return nil
+}
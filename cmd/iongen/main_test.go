@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testTypes = `package sample
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/fernomac/ion-go"
+)
+
+type Base struct {
+	ID   int64  ` + "`ion:\"id\"`" + `
+	Kind string ` + "`ion:\"kind,symbol\"`" + `
+}
+
+type Order struct {
+	Base
+	Tags    []string         ` + "`ion:\",annotations\"`" + `
+	Name    string           ` + "`json:\"name,omitempty\"`" + `
+	Count   uint8            ` + "`ion:\"count\"`" + `
+	Big     uint64           ` + "`ion:\"big\"`" + `
+	Small   int16            ` + "`ion:\"small,omitzero\"`" + `
+	Ratio   float32          ` + "`ion:\"ratio\"`" + `
+	Price   float64          ` + "`ion:\"price,string\"`" + `
+	Flag    *bool            ` + "`ion:\"flag\"`" + `
+	Data    []byte           ` + "`ion:\"data,omitempty\"`" + `
+	Text    []byte           ` + "`ion:\"text,clob\"`" + `
+	When    time.Time        ` + "`ion:\"when,omitzero\"`" + `
+	Amount  ion.Decimal      ` + "`ion:\"amount\"`" + `
+	PAmount *ion.Decimal     ` + "`ion:\"pamount\"`" + `
+	Huge    *big.Int         ` + "`ion:\"huge\"`" + `
+	Items   []Item           ` + "`ion:\"items\"`" + `
+	PItems  []*Item          ` + "`ion:\"pitems\"`" + `
+	Attrs   map[string][]int ` + "`ion:\"attrs\"`" + `
+	Nested  map[string]*Item ` + "`ion:\"nested\"`" + `
+	Any     interface{}      ` + "`ion:\"any\"`" + `
+	Color   Color            ` + "`ion:\"color\"`" + `
+	Next    *Order           ` + "`ion:\"next\"`" + `
+	Symbols []string         ` + "`ion:\"symbols,symbol\"`" + `
+	Counts  *[]int           ` + "`ion:\"counts\"`" + `
+	hidden  int
+}
+
+func (Order) IonAnnotations() []string { return []string{"order"} }
+
+type Item struct {
+	SKU   string   ` + "`ion:\"sku\"`" + `
+	Qty   int      ` + "`ion:\"qty,string\"`" + `
+	Price *float64 ` + "`ion:\"price\"`" + `
+	Sizes []*int   ` + "`ion:\"sizes,string\"`" + `
+}
+
+type Color int
+`
+
+func tempDir(t *testing.T, parent string, files map[string]string) string {
+	dir, err := ioutil.TempDir(parent, "iongen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestRun(t *testing.T) {
+	dir := tempDir(t, "", map[string]string{"types.go": testTypes})
+	defer os.RemoveAll(dir)
+
+	stderr := bytes.Buffer{}
+	if status := run([]string{"-type", "Order,Item", dir}, &stderr); status != 0 {
+		t.Fatalf("iongen exited with status %v: %v", status, stderr.String())
+	}
+
+	src, err := ioutil.ReadFile(filepath.Join(dir, "order_ion.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"// Code generated by \"iongen -type Order,Item " + dir + "\"; DO NOT EDIT.",
+		"func (v Order) MarshalIon(w ion.Writer) error {",
+		"func (v *Order) UnmarshalIon(r ion.Reader) error {",
+		"func (v Item) MarshalIon(w ion.Writer) error {",
+		"func (v *Item) UnmarshalIon(r ion.Reader) error {",
+		"sort.Strings(",
+		"ion.MarshalTo(w, v.Any)",
+	} {
+		if !strings.Contains(string(src), s) {
+			t.Errorf("expected generated code to contain %q", s)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "order_ion_test.go")); !os.IsNotExist(err) {
+		t.Errorf("expected no test file without -test, got %v", err)
+	}
+
+	// Regenerating ignores the previous output.
+	if status := run([]string{"-type", "Item", "-output", filepath.Join(dir, "order_ion.go"), dir}, &stderr); status != 0 {
+		t.Fatalf("iongen exited with status %v: %v", status, stderr.String())
+	}
+
+	if status := run([]string{dir}, &stderr); status != 2 {
+		t.Errorf("expected status 2 without -type, got %v", status)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	test := func(src, name, eval string) {
+		t.Run(eval, func(t *testing.T) {
+			dir := tempDir(t, "", map[string]string{"a.go": "package a\n\nimport \"math/big\"\n\nvar _ big.Int\n\n" + src})
+			defer os.RemoveAll(dir)
+
+			p, err := parsePackage(dir)
+			if err == nil {
+				_, err = generate(p, []string{name}, "")
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), eval) {
+				t.Errorf("expected %q, got %q", eval, err.Error())
+			}
+		})
+	}
+
+	test("type A struct{}", "B", "type B not found in package a")
+	test("type A int", "A", "type A is not a struct type")
+	test("type A struct{ X, Y int `ion:\"x\"` }", "A", "A: too many fields named x")
+	test("type A struct{ *B }\ntype B struct{}", "A", "embedded pointer B is not supported")
+	test("type A struct{ big.Int }", "A", "embedded big.Int is not supported")
+	test("type A struct{ X float64 `ion:\",decimal\"` }", "A", "field X: option decimal is not supported")
+	test("type A struct{ X []int `ion:\",annotations\"` }", "A", "annotations field X must be a []string")
+	test("type A struct{ X interface{} `ion:\",symbol\"` }", "A", "field X: options are not supported for its type")
+}
+
+func TestGeneratedCode(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping go test of generated code in short mode")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not found")
+	}
+
+	// The package has to be inside the module to import it.
+	dir := tempDir(t, ".", map[string]string{"types.go": testTypes})
+	defer os.RemoveAll(dir)
+
+	stderr := bytes.Buffer{}
+	if status := run([]string{"-type", "Order,Item", "-test", dir}, &stderr); status != 0 {
+		t.Fatalf("iongen exited with status %v: %v", status, stderr.String())
+	}
+
+	for _, args := range [][]string{{"vet"}, {"test", "-count=1"}} {
+		cmd := exec.Command("go", append(args, "./"+filepath.Base(dir))...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("go %v: %v\n%s", args[0], err, out)
+		}
+	}
+}
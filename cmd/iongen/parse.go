@@ -0,0 +1,521 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// IonPath is the import path of the ion package.
+const ionPath = "github.com/fernomac/ion-go"
+
+// A kind classifies a Go type by how the generated code handles it.
+type kind int
+
+const (
+	// OtherKind types are encoded and decoded reflectively.
+	otherKind kind = iota
+	boolKind
+	intKind
+	uintKind
+	floatKind
+	stringKind
+	bytesKind
+	timeKind
+	decimalKind
+	bigIntKind
+	ptrKind
+	sliceKind
+	mapKind
+	interfaceKind
+	structKind
+	arrayKind
+)
+
+// A goType is the type of a struct field, as far as iongen understands it.
+type goType struct {
+	kind kind
+
+	// Name is the type as written in generated code: a builtin or local type name,
+	// time.Time, big.Int, ion.Decimal, or a pointer to, slice of, or string-keyed map
+	// of one. It's empty for other types.
+	name string
+
+	// Bits is the size of int, uint, and float kinds; 0 for int, uint, and uintptr.
+	bits int
+
+	// Elem is the element type of pointers, slices, and maps.
+	elem *goType
+
+	// Local is the name of the type declared in the package being generated
+	// for, if it is one.
+	local string
+}
+
+// Direct returns true if values of the type are encoded and decoded by the generated
+// code itself rather than reflectively. The elements of slices and maps might still
+// be handled reflectively.
+func (t *goType) direct() bool {
+	switch t.kind {
+	case boolKind, intKind, uintKind, floatKind, stringKind, bytesKind, timeKind, decimalKind, bigIntKind:
+		return t.local == ""
+	case ptrKind:
+		return t.local == "" && t.elem.kind != ptrKind && t.elem.direct()
+	case sliceKind, mapKind:
+		return t.local == "" && t.name != ""
+	}
+	return false
+}
+
+// AllDirect returns true if the type and any elements it has are all direct.
+func (t *goType) allDirect() bool {
+	return t.direct() && (t.elem == nil || t.elem.allDirect())
+}
+
+// A field is a field of a struct type that iongen generates methods for.
+type field struct {
+	// Expr is the Go expression selecting the field, relative to the struct,
+	// including the names of any embedded structs it's promoted from.
+	expr string
+	name string
+	typ  *goType
+
+	omitEmpty   bool
+	omitZero    bool
+	annotations bool
+	symbol      bool
+	lob         string
+	str         bool
+}
+
+// HasEncodingOpts returns true if the field has options that affect how its value
+// is encoded or decoded.
+func (f *field) hasEncodingOpts() bool {
+	return f.symbol || f.lob != "" || f.str
+}
+
+// A pkg holds the declarations of the package being generated for.
+type pkg struct {
+	name  string
+	types map[string]*ast.TypeSpec
+	files map[*ast.TypeSpec]*ast.File
+
+	// Methods maps each type name to the names of its methods.
+	methods map[string]map[string]bool
+
+	// Resolving holds the named types whose underlying types are being worked out,
+	// to stop at recursive types.
+	resolving map[string]bool
+}
+
+// ParsePackage parses the non-test Go files in dir, skipping the named files (which
+// are iongen's own previous output).
+func parsePackage(dir string, skip ...string) (*pkg, error) {
+	skipped := map[string]bool{}
+	for _, file := range skip {
+		skipped[filepath.Base(file)] = true
+	}
+	filter := func(info os.FileInfo) bool {
+		name := info.Name()
+		return !strings.HasSuffix(name, "_test.go") && !skipped[name]
+	}
+
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, filter, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package in %v, found %v", dir, len(pkgs))
+	}
+
+	p := &pkg{
+		types:     map[string]*ast.TypeSpec{},
+		files:     map[*ast.TypeSpec]*ast.File{},
+		methods:   map[string]map[string]bool{},
+		resolving: map[string]bool{},
+	}
+	for name, astPkg := range pkgs {
+		p.name = name
+		for _, file := range astPkg.Files {
+			p.addDecls(file)
+		}
+	}
+	return p, nil
+}
+
+// AddDecls records the type and method declarations in the given file.
+func (p *pkg) addDecls(file *ast.File) {
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				if ts, ok := spec.(*ast.TypeSpec); ok {
+					p.types[ts.Name.Name] = ts
+					p.files[ts] = file
+				}
+			}
+
+		case *ast.FuncDecl:
+			if decl.Recv == nil || len(decl.Recv.List) != 1 {
+				continue
+			}
+			recv := decl.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+			if id, ok := recv.(*ast.Ident); ok {
+				if p.methods[id.Name] == nil {
+					p.methods[id.Name] = map[string]bool{}
+				}
+				p.methods[id.Name][decl.Name.Name] = true
+			}
+		}
+	}
+}
+
+// HasMethod returns true if the named local type (or a pointer to it) has the
+// given method.
+func (p *pkg) hasMethod(typ, method string) bool {
+	return p.methods[typ][method]
+}
+
+// StructType returns the named struct type declared in the package.
+func (p *pkg) structType(name string) (*ast.StructType, *ast.File, error) {
+	ts, ok := p.types[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("type %v not found in package %v", name, p.name)
+	}
+	st, ok := ts.Type.(*ast.StructType)
+	if !ok || ts.Assign.IsValid() {
+		return nil, nil, fmt.Errorf("type %v is not a struct type", name)
+	}
+	return st, p.files[ts], nil
+}
+
+// TypeOf works out the goType of a type expression appearing in the given file.
+func (p *pkg) typeOf(expr ast.Expr, file *ast.File) *goType {
+	switch expr := expr.(type) {
+	case *ast.ParenExpr:
+		return p.typeOf(expr.X, file)
+
+	case *ast.Ident:
+		if t := builtinType(expr.Name); t != nil {
+			return t
+		}
+		if ts, ok := p.types[expr.Name]; ok && !p.resolving[expr.Name] {
+			// A named type behaves like its underlying type for omitempty and omitzero,
+			// but is otherwise handled reflectively in case it has methods of its own.
+			p.resolving[expr.Name] = true
+			t := *p.typeOf(ts.Type, p.files[ts])
+			delete(p.resolving, expr.Name)
+
+			t.name, t.local = expr.Name, expr.Name
+			return &t
+		}
+		return &goType{kind: otherKind}
+
+	case *ast.SelectorExpr:
+		x, ok := expr.X.(*ast.Ident)
+		if !ok {
+			return &goType{kind: otherKind}
+		}
+		switch importPath(file, x.Name) + "." + expr.Sel.Name {
+		case "time.Time":
+			return &goType{kind: timeKind, name: "time.Time"}
+		case "math/big.Int":
+			return &goType{kind: bigIntKind, name: "big.Int"}
+		case ionPath + ".Decimal":
+			return &goType{kind: decimalKind, name: "ion.Decimal"}
+		}
+		return &goType{kind: otherKind}
+
+	case *ast.StarExpr:
+		elem := p.typeOf(expr.X, file)
+		return &goType{kind: ptrKind, name: prefixName("*", elem), elem: elem}
+
+	case *ast.ArrayType:
+		elem := p.typeOf(expr.Elt, file)
+		if expr.Len != nil {
+			return &goType{kind: arrayKind, elem: elem}
+		}
+		if elem.kind == uintKind && elem.bits == 8 && elem.local == "" {
+			return &goType{kind: bytesKind, name: "[]byte"}
+		}
+		return &goType{kind: sliceKind, name: prefixName("[]", elem), elem: elem}
+
+	case *ast.MapType:
+		elem := p.typeOf(expr.Value, file)
+		if key, ok := expr.Key.(*ast.Ident); !ok || key.Name != "string" {
+			// Encoded with the keys' MarshalText, so leave it to reflection.
+			return &goType{kind: otherKind}
+		}
+		return &goType{kind: mapKind, name: prefixName("map[string]", elem), elem: elem}
+
+	case *ast.InterfaceType:
+		return &goType{kind: interfaceKind}
+
+	case *ast.StructType:
+		return &goType{kind: structKind}
+	}
+
+	return &goType{kind: otherKind}
+}
+
+// PrefixName returns the name of a pointer, slice, or map type with the given
+// element type, or "" if the element type has no name.
+func prefixName(prefix string, elem *goType) string {
+	if elem.name == "" {
+		return ""
+	}
+	return prefix + elem.name
+}
+
+// BuiltinType returns the goType of the named builtin type, or nil if it isn't one.
+func builtinType(name string) *goType {
+	switch name {
+	case "bool":
+		return &goType{kind: boolKind, name: name}
+	case "string":
+		return &goType{kind: stringKind, name: name}
+	case "int", "int8", "int16", "int32", "int64", "rune":
+		return &goType{kind: intKind, name: name, bits: bitsOf(name, "int")}
+	case "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte":
+		return &goType{kind: uintKind, name: name, bits: bitsOf(name, "uint")}
+	case "float32", "float64":
+		return &goType{kind: floatKind, name: name, bits: bitsOf(name, "float")}
+	case "error", "any":
+		return &goType{kind: interfaceKind}
+	}
+	return nil
+}
+
+// BitsOf returns the size of the named numeric type.
+func bitsOf(name, prefix string) int {
+	switch name {
+	case "rune":
+		return 32
+	case "byte":
+		return 8
+	}
+	bits, _ := strconv.Atoi(strings.TrimPrefix(name, prefix))
+	return bits
+}
+
+// ImportPath returns the path of the package imported under the given name in
+// file, or "" if there's none. Only the packages iongen cares about are recognized
+// when imported without an explicit name.
+func importPath(file *ast.File, name string) string {
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+
+		local := ""
+		switch {
+		case spec.Name != nil:
+			local = spec.Name.Name
+		case path == ionPath:
+			local = "ion"
+		case path == "math/big":
+			local = "big"
+		default:
+			local = path
+		}
+
+		if local == name {
+			return path
+		}
+	}
+	return ""
+}
+
+// Fields returns the fields of the named struct type in the order the Encoder
+// writes them, following the same tag rules.
+func (p *pkg) fields(name string) ([]field, error) {
+	st, file, err := p.structType(name)
+	if err != nil {
+		return nil, err
+	}
+
+	fs := []field{}
+	if err := p.inspect(st, file, "", &fs); err != nil {
+		return nil, fmt.Errorf("%v: %v", name, err)
+	}
+
+	seen := map[string]bool{}
+	for _, f := range fs {
+		if f.annotations {
+			continue
+		}
+		if seen[f.name] {
+			return nil, fmt.Errorf("%v: too many fields named %v", name, f.name)
+		}
+		seen[f.name] = true
+	}
+	return fs, nil
+}
+
+// Inspect appends the fields of the given struct type to fs, digging in to any
+// embedded structs.
+func (p *pkg) inspect(st *ast.StructType, file *ast.File, prefix string, fs *[]field) error {
+	for _, af := range st.Fields.List {
+		tag := ""
+		if af.Tag != nil {
+			tag, _ = strconv.Unquote(af.Tag.Value)
+		}
+
+		// An ion tag, if present, takes precedence over a json tag.
+		stag := reflect.StructTag(tag)
+		tag, ion := stag.Lookup("ion")
+		if !ion {
+			tag = stag.Get("json")
+		}
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if i := strings.Index(tag, ","); i >= 0 {
+			name, opts = tag[:i], tag[i+1:]
+		}
+
+		if len(af.Names) == 0 {
+			if err := p.inspectEmbedded(af, file, prefix, name, opts, ion, fs); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, id := range af.Names {
+			if !id.IsExported() {
+				continue
+			}
+			f := field{
+				expr: prefix + id.Name,
+				name: name,
+				typ:  p.typeOf(af.Type, file),
+			}
+			if f.name == "" {
+				f.name = id.Name
+			}
+			if err := parseFieldOpts(&f, af.Type, opts, ion); err != nil {
+				return err
+			}
+			*fs = append(*fs, f)
+		}
+	}
+	return nil
+}
+
+// InspectEmbedded handles an embedded field, which is flattened in to its parent if
+// it's a struct and isn't renamed by its tag.
+func (p *pkg) inspectEmbedded(af *ast.Field, file *ast.File, prefix, name, opts string, ion bool, fs *[]field) error {
+	typ := af.Type
+	ptr := false
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ, ptr = star.X, true
+	}
+
+	var id *ast.Ident
+	shown := ""
+	switch t := typ.(type) {
+	case *ast.Ident:
+		id, shown = t, t.Name
+	case *ast.SelectorExpr:
+		id, shown = t.Sel, fmt.Sprintf("%v.%v", t.X, t.Sel)
+	default:
+		return fmt.Errorf("unsupported embedded field %v", typ)
+	}
+
+	local, isLocal := id, false
+	if _, ok := typ.(*ast.Ident); ok {
+		_, isLocal = p.types[local.Name]
+	}
+
+	isStruct := false
+	if isLocal {
+		st, sfile, err := p.structType(local.Name)
+		isStruct = err == nil
+		if isStruct && name == "" {
+			switch {
+			case ptr:
+				return fmt.Errorf("embedded pointer %v is not supported", local.Name)
+			case p.hasMethod(local.Name, "MarshalIon") || p.hasMethod(local.Name, "UnmarshalIon"):
+				return fmt.Errorf("embedded %v has its own MarshalIon or UnmarshalIon method", local.Name)
+			}
+			return p.inspect(st, sfile, prefix+local.Name+".", fs)
+		}
+	} else if name == "" {
+		// It might be a struct, whose fields we can't see.
+		return fmt.Errorf("embedded %v is not supported", shown)
+	}
+
+	// Embedded structs are visible even if their type isn't exported.
+	if !id.IsExported() && !isStruct {
+		return nil
+	}
+	f := field{
+		expr: prefix + id.Name,
+		name: name,
+		typ:  p.typeOf(af.Type, file),
+	}
+	if f.name == "" {
+		f.name = id.Name
+	}
+	if err := parseFieldOpts(&f, af.Type, opts, ion); err != nil {
+		return err
+	}
+	*fs = append(*fs, f)
+	return nil
+}
+
+// ParseFieldOpts parses the options from a field tag, as for the Encoder and
+// Decoder, returning an error for the ones iongen doesn't support.
+func parseFieldOpts(f *field, typ ast.Expr, opts string, ion bool) error {
+	for _, o := range strings.Split(opts, ",") {
+		if o == "omitempty" {
+			f.omitEmpty = true
+		}
+		if !ion {
+			continue
+		}
+
+		switch {
+		case o == "omitzero":
+			f.omitZero = true
+		case o == "symbol":
+			f.symbol = true
+		case o == "blob", o == "clob":
+			f.lob = o
+		case o == "string":
+			f.str = true
+		case o == "annotations":
+			f.annotations = true
+		case o == "decimal", strings.HasPrefix(o, "timestamp="):
+			return fmt.Errorf("field %v: option %v is not supported", f.expr, o)
+		}
+	}
+
+	if f.annotations {
+		if at, ok := typ.(*ast.ArrayType); !ok || at.Len != nil || !isIdent(at.Elt, "string") {
+			return fmt.Errorf("annotations field %v must be a []string", f.expr)
+		}
+	}
+	if f.hasEncodingOpts() && !f.typ.allDirect() {
+		return fmt.Errorf("field %v: options are not supported for its type", f.expr)
+	}
+	return nil
+}
+
+// IsIdent returns true if expr is the given identifier.
+func isIdent(expr ast.Expr, name string) bool {
+	id, ok := expr.(*ast.Ident)
+	return ok && id.Name == name
+}
@@ -1,6 +1,8 @@
 package ion
 
 import (
+	"encoding"
+	"math/big"
 	"reflect"
 	"time"
 )
@@ -50,3 +52,18 @@ var hexChars = []byte{
 
 var timeType = reflect.TypeOf(time.Time{})
 var decimalType = reflect.TypeOf(Decimal{})
+var timestampType = reflect.TypeOf(Timestamp{})
+var bigIntType = reflect.TypeOf(big.Int{})
+var rawValueType = reflect.TypeOf(RawValue{})
+var symbolType = reflect.TypeOf(Symbol(""))
+var sexpType = reflect.TypeOf(Sexp{})
+var clobType = reflect.TypeOf(Clob{})
+var numberType = reflect.TypeOf(Number(""))
+
+var marshalerType = reflect.TypeOf((*Marshaler)(nil)).Elem()
+var unmarshalerType = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+var isZeroerType = reflect.TypeOf((*isZeroer)(nil)).Elem()
+var annotatedType = reflect.TypeOf((*Annotated)(nil)).Elem()
+var stringSliceType = reflect.TypeOf([]string{})
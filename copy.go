@@ -0,0 +1,202 @@
+package ion
+
+import "fmt"
+
+// Copy copies values from r to w until r reaches the end of its current sequence
+// of values, which at the top level is the end of the stream. Annotations, typed
+// nulls, and the distinctions between lists and sexps and between clobs and blobs
+// are preserved. It does not call w.Finish.
+//
+// 	r := NewReader(in)
+// 	w := NewBinaryWriter(out)
+// 	if err := Copy(w, r); err != nil {
+// 		return err
+// 	}
+// 	return w.Finish()
+//
+// When copying from binary to binary with a Writer created by NewBinaryWriterLST,
+// containers encoded against a compatible local symbol table are copied byte for
+// byte instead of being read and rewritten.
+func Copy(w Writer, r Reader) error {
+	return w.WriteValues(r)
+}
+
+// A rawReader is a Reader that can hand over the serialized bytes of its
+// current value.
+type rawReader interface {
+	Reader
+
+	// RawContainer returns the binary type code and serialized contents of the
+	// current value, consuming it, if it is a non-null container. Otherwise it
+	// consumes nothing and returns false.
+	rawContainer() (byte, []byte, bool, error)
+}
+
+// A rawWriter is a Writer that may be able to copy serialized values directly
+// from a rawReader.
+type rawWriter interface {
+	Writer
+
+	// WriteRaw writes the given Reader's current value, returning false if it
+	// can't do so without re-encoding it.
+	writeRaw(r Reader) (bool, error)
+}
+
+// WriteValueFrom writes the given Reader's current value to self, which is the
+// Writer embedding w, taking its field name from the Reader if none has been set.
+func (w *writer) writeValueFrom(self Writer, r Reader) error {
+	if w.err != nil {
+		return w.err
+	}
+	if r.Type() == NoType {
+		return &UsageError{"Writer.WriteValue", "reader is not positioned on a value"}
+	}
+
+	if w.inStruct() && w.fieldName == nil {
+		w.fieldName = r.FieldNameSymbol()
+	}
+	if as := r.AnnotationSymbols(); len(as) > 0 {
+		w.annotations = append(w.annotations, as...)
+	}
+
+	if rw, ok := self.(rawWriter); ok {
+		if done, err := rw.writeRaw(r); done || err != nil {
+			return err
+		}
+	}
+
+	return writeCurrentValue(self, r)
+}
+
+// WriteValues writes the rest of the values in the Reader's current sequence.
+func writeValues(w Writer, r Reader) error {
+	for r.Next() {
+		if err := w.WriteValue(r); err != nil {
+			return err
+		}
+	}
+	return r.Err()
+}
+
+// WriteCurrentValue writes the Reader's current value (but not its field name or
+// annotations, which the caller has already taken care of) to the Writer.
+func writeCurrentValue(w Writer, r Reader) error {
+	t := r.Type()
+	if r.IsNull() {
+		if t == NullType {
+			return w.WriteNull()
+		}
+		return w.WriteNullType(t)
+	}
+
+	switch t {
+	case BoolType:
+		val, err := r.BoolValue()
+		if err != nil {
+			return err
+		}
+		return w.WriteBool(val)
+
+	case IntType:
+		size, err := r.IntSize()
+		if err != nil {
+			return err
+		}
+		if size == Int32 || size == Int64 {
+			val, err := r.Int64Value()
+			if err != nil {
+				return err
+			}
+			return w.WriteInt(val)
+		}
+		val, err := r.BigIntValue()
+		if err != nil {
+			return err
+		}
+		return w.WriteBigInt(val)
+
+	case FloatType:
+		val, err := r.FloatValue()
+		if err != nil {
+			return err
+		}
+		return w.WriteFloat(val)
+
+	case DecimalType:
+		val, err := r.DecimalValue()
+		if err != nil {
+			return err
+		}
+		return w.WriteDecimal(val)
+
+	case TimestampType:
+		val, err := r.TimestampValue()
+		if err != nil {
+			return err
+		}
+		return w.WriteIonTimestamp(val)
+
+	case SymbolType:
+		val, err := r.SymbolValue()
+		if err != nil {
+			return err
+		}
+		return w.WriteSymbolToken(*val)
+
+	case StringType:
+		val, err := r.StringValue()
+		if err != nil {
+			return err
+		}
+		return w.WriteString(val)
+
+	case ClobType:
+		val, err := r.ByteValue()
+		if err != nil {
+			return err
+		}
+		return w.WriteClob(val)
+
+	case BlobType:
+		val, err := r.ByteValue()
+		if err != nil {
+			return err
+		}
+		return w.WriteBlob(val)
+
+	case ListType:
+		w.BeginList()
+		if err := writeContents(w, r); err != nil {
+			return err
+		}
+		return w.EndList()
+
+	case SexpType:
+		w.BeginSexp()
+		if err := writeContents(w, r); err != nil {
+			return err
+		}
+		return w.EndSexp()
+
+	case StructType:
+		w.BeginStruct()
+		if err := writeContents(w, r); err != nil {
+			return err
+		}
+		return w.EndStruct()
+	}
+
+	panic(fmt.Sprintf("unexpected type %v", t))
+}
+
+// WriteContents steps in to the Reader's current container value and writes its
+// contents to the Writer.
+func writeContents(w Writer, r Reader) error {
+	if err := r.StepIn(); err != nil {
+		return err
+	}
+	if err := writeValues(w, r); err != nil {
+		return err
+	}
+	return r.StepOut()
+}
@@ -0,0 +1,158 @@
+package ion
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCopyText(t *testing.T) {
+	test := func(str string) {
+		t.Run(str, func(t *testing.T) {
+			buf := strings.Builder{}
+			w := NewTextWriterOpts(&buf, TextWriterQuietFinish)
+			if err := Copy(w, NewReaderStr(str)); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Finish(); err != nil {
+				t.Fatal(err)
+			}
+
+			if buf.String() != str {
+				t.Errorf("expected %v, got %v", str, buf.String())
+			}
+		})
+	}
+
+	test("a::b::1")
+	test("null\nnull.int\nnull.sexp\nnull.struct")
+	test("(a '+' 1)\n[a,b]")
+	test("{{\"hello\"}}\n{{aGVsbG8=}}")
+	test("{a:1,b:x::[2.5,1.5e+0],c:{}}")
+	test("18446744073709551616\n-9223372036854775808")
+	test("2020-01-02T03:04:05.600-08:00\n2020T")
+	test("'hello world'\n\"hello world\"")
+}
+
+func TestCopyTranscode(t *testing.T) {
+	str := "a::{b:(c [1, 2.0, 3e0] null.list), 'd e':{{Zm9v}}, f:{{\"bar\"}}} 2020-01-01T00:00Z null.symbol"
+	evals := readValuesStr(t, str)
+
+	// Text to binary.
+	bin := bytes.Buffer{}
+	w := NewBinaryWriter(&bin)
+	if err := Copy(w, NewReaderStr(str)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Binary back to text.
+	text := strings.Builder{}
+	w = NewTextWriter(&text)
+	if err := Copy(w, NewReaderBytes(bin.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	vals := readValuesStr(t, text.String())
+	if len(vals) != len(evals) {
+		t.Fatalf("expected %v values, got %v", len(evals), len(vals))
+	}
+	for i := range vals {
+		if !vals[i].Equal(evals[i]) {
+			t.Errorf("expected %v, got %v", evals[i], vals[i])
+		}
+	}
+}
+
+func TestWriteValue(t *testing.T) {
+	r := NewReaderStr("{a:1,b:[2],c:3}")
+	r.Next()
+	r.StepIn()
+
+	actual := writeText(func(w Writer) {
+		w.BeginStruct()
+		for r.Next() {
+			if r.FieldName() == "c" {
+				w.FieldName("renamed")
+			}
+			if err := w.WriteValue(r); err != nil {
+				t.Fatal(err)
+			}
+		}
+		w.EndStruct()
+	})
+
+	if expected := "{a:1,b:[2],renamed:3}"; actual != expected {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+
+	w := NewTextWriter(&strings.Builder{})
+	if err := w.WriteValue(NewReaderStr("1")); err == nil {
+		t.Error("expected an error writing from a reader with no current value")
+	}
+}
+
+func TestWriteValuesRest(t *testing.T) {
+	r := NewReaderStr("[1,2,3,4] 5")
+	r.Next()
+	r.StepIn()
+	r.Next()
+
+	actual := writeText(func(w Writer) {
+		w.BeginList()
+		if err := w.WriteValues(r); err != nil {
+			t.Fatal(err)
+		}
+		w.EndList()
+	})
+	if expected := "[2,3,4]"; actual != expected {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+
+	r.StepOut()
+	_int(t, r, 5)
+}
+
+func TestCopyRawBinary(t *testing.T) {
+	lst := NewLocalSymbolTable(nil, []string{"foo"})
+
+	// A list containing foo and a NOP pad, which only survives a raw copy.
+	in := []byte{
+		0xE0, 0x01, 0x00, 0xEA,
+		0xE9, 0x81, 0x83, 0xD6, 0x87, 0xB4, 0x83, 'f', 'o', 'o',
+		0xB3, 0x71, 0x0A, 0x00,
+	}
+
+	test := func(name string, lst SymbolTable, suffix []byte) {
+		t.Run(name, func(t *testing.T) {
+			out := bytes.Buffer{}
+			w := NewBinaryWriterLST(&out, lst)
+			if err := Copy(w, NewReaderBytes(in)); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Finish(); err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.HasSuffix(out.Bytes(), suffix) {
+				t.Errorf("expected output to end with %X, got %X", suffix, out.Bytes())
+			}
+
+			r := NewReaderBytes(out.Bytes())
+			_list(t, r, func(t *testing.T, r Reader) {
+				_symbol(t, r, "foo")
+				_eof(t, r)
+			})
+			_eof(t, r)
+		})
+	}
+
+	test("compatible", lst, []byte{0xB3, 0x71, 0x0A, 0x00})
+	test("superset", NewLocalSymbolTable(nil, []string{"foo", "bar"}), []byte{0xB3, 0x71, 0x0A, 0x00})
+	test("incompatible", NewLocalSymbolTable(nil, []string{"bar", "foo"}), []byte{0xB2, 0x71, 0x0B})
+}
@@ -21,10 +21,14 @@ func (e *ParseError) Error() string {
 
 // TODO: Explicitly track precision?
 
-// Decimal is an arbitrary-precision decimal value.
+// Decimal is an arbitrary-precision decimal value. The zero Decimal is 0, as far
+// as Writers are concerned; other operations need one made by a constructor.
 type Decimal struct {
 	n     *big.Int
 	scale int32
+
+	// NegZero marks a zero coefficient as negative (-0.), which big.Int can't.
+	negZero bool
 }
 
 // NewDecimal creates a new decimal whose value is equal to n * 10^exp.
@@ -92,11 +96,184 @@ func ParseDecimal(in string) (*Decimal, error) {
 		return nil, &ParseError{in, "cannot parse coefficient"}
 	}
 
-	return NewDecimal(n, exponent), nil
+	dec := NewDecimal(n, exponent)
+	dec.negZero = n.Sign() == 0 && in[0] == '-'
+	return dec, nil
+}
+
+// NewDecimalFloat creates a new decimal from the given float, using the shortest
+// decimal representation that converts back to the same float: 0.1 is 0.1. It
+// returns an error if f is NaN or infinite.
+func NewDecimalFloat(f float64) (*Decimal, error) {
+	return decimalFromFloat(f, 64)
+}
+
+// NewDecimalFloatExact creates a new decimal exactly equal to the given float,
+// which can take many more digits than NewDecimalFloat: 0.1 is
+// 0.1000000000000000055511151231257827021181583404541015625. It returns an error
+// if f is NaN or infinite.
+func NewDecimalFloatExact(f float64) (*Decimal, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("ion: cannot represent %v as a decimal", f)
+	}
+	return NewDecimalBigFloat(new(big.Float).SetFloat64(f))
+}
+
+// NewDecimalBigFloat creates a new decimal exactly equal to the given big.Float.
+// It returns an error if f is infinite.
+func NewDecimalBigFloat(f *big.Float) (*Decimal, error) {
+	if f.IsInf() {
+		return nil, fmt.Errorf("ion: cannot represent %v as a decimal", f)
+	}
+
+	if f.IsInt() {
+		n, _ := f.Int(nil)
+		d := NewDecimal(n, 0)
+		d.negZero = n.Sign() == 0 && f.Signbit()
+		return d, nil
+	}
+
+	// f = m * 2^-k for some integer m, so f = m * 5^k * 10^-k.
+	m := new(big.Float)
+	exp := f.MantExp(m)
+	prec := int64(m.MinPrec())
+	k := prec - int64(exp)
+	if k > math.MaxInt32 {
+		return nil, fmt.Errorf("ion: decimal exponent out of range for %v", f)
+	}
+
+	n, _ := m.SetMantExp(m, int(prec)).Int(nil)
+	n.Mul(n, new(big.Int).Exp(big.NewInt(5), big.NewInt(k), nil))
+
+	return &Decimal{n: n, scale: int32(k)}, nil
+}
+
+// NewDecimalRat creates a new decimal equal to the given big.Rat, rounded to the
+// context's precision if it can't be represented exactly in that many digits.
+func NewDecimalRat(r *big.Rat, ctx DecimalContext) *Decimal {
+	num := NewDecimal(new(big.Int).Set(r.Num()), 0)
+	return num.Quo(NewDecimal(new(big.Int).Set(r.Denom()), 0), ctx)
+}
+
+// NewDecimalRatExact creates a new decimal exactly equal to the given big.Rat. It
+// returns an error if there is no such decimal, like for 1/3.
+func NewDecimalRatExact(r *big.Rat) (*Decimal, error) {
+	// The denominator must be 2^a * 5^b, in which case r = num * 2^(k-a) * 5^(k-b) * 10^-k.
+	den := new(big.Int).Set(r.Denom())
+	twos := int64(den.TrailingZeroBits())
+	den.Rsh(den, uint(twos))
+
+	five := big.NewInt(5)
+	fives := int64(0)
+	for q, m := new(big.Int), new(big.Int); ; fives++ {
+		if q.QuoRem(den, five, m); m.Sign() != 0 {
+			break
+		}
+		den.Set(q)
+	}
+
+	if den.Cmp(big.NewInt(1)) != 0 {
+		return nil, fmt.Errorf("ion: cannot represent %v exactly as a decimal", r)
+	}
+
+	k := twos
+	if fives > k {
+		k = fives
+	}
+
+	n := new(big.Int).Set(r.Num())
+	n.Lsh(n, uint(k-twos))
+	n.Mul(n, new(big.Int).Exp(five, big.NewInt(k-fives), nil))
+
+	return newDecimalScale(n, k), nil
 }
 
-// CoEx returns this decimal's coefficient and exponent.
+// DecimalFromFloat converts a float to a decimal using the shortest decimal
+// representation that round-trips back to the same float.
+func decimalFromFloat(f float64, bitSize int) (*Decimal, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("ion: cannot represent %v as a decimal", f)
+	}
+	str := strconv.FormatFloat(f, 'g', -1, bitSize)
+	return ParseDecimal(strings.Replace(str, "e", "d", 1))
+}
+
+// Float converts the decimal to the nearest float64.
+func (d *Decimal) float() (float64, error) {
+	if d.negZero {
+		return math.Copysign(0, -1), nil
+	}
+	return strconv.ParseFloat(fmt.Sprintf("%ve%v", d.n, -d.scale), 64)
+}
+
+// Float64 returns the float64 nearest to this decimal, and whether it's exactly
+// equal. Values too large for a float64 give an infinity.
+func (d *Decimal) Float64() (float64, bool) {
+	f, err := d.float()
+	if err != nil {
+		return f, false
+	}
+	if f == 0 && d.Sign() != 0 {
+		// Too close to zero, and comparing would rescale to d's (maybe huge) scale.
+		return f, false
+	}
+	exact, err := NewDecimalFloatExact(f)
+	return f, err == nil && exact.Cmp(d) == 0
+}
+
+// BigFloat returns this decimal as a big.Float with the given precision in bits,
+// rounded to nearest even; the result's Acc method reports whether it's exact. A
+// precision of zero uses the largest of 64 and the number of bits in the
+// numerator and denominator of Rat.
+func (d *Decimal) BigFloat(prec uint) *big.Float {
+	f := new(big.Float).SetPrec(prec).SetRat(d.Rat())
+	if d.negZero {
+		f.Neg(f)
+	}
+	return f
+}
+
+// Rat returns this decimal as an exactly-equal big.Rat.
+func (d *Decimal) Rat() *big.Rat {
+	if d.scale <= 0 {
+		return new(big.Rat).SetInt(d.upscale(0).n)
+	}
+	return new(big.Rat).SetFrac(d.n, shiftInt(big.NewInt(1), int64(d.scale)))
+}
+
+// Precision returns the number of digits in this decimal's coefficient. Zero has
+// one digit.
+func (d *Decimal) Precision() int {
+	return numDigits(d.n)
+}
+
+// Scale returns the number of digits after the decimal point, which is the
+// negative of the exponent.
+func (d *Decimal) Scale() int32 {
+	return d.scale
+}
+
+// IsInteger returns true if this decimal has no fractional part, no matter how
+// many zeros follow its decimal point.
+func (d *Decimal) IsInteger() bool {
+	if d.scale <= 0 || d.n.Sign() == 0 {
+		return true
+	}
+	if int64(d.scale) >= int64(numDigits(d.n)) {
+		// Every digit is after the decimal point, and at least one isn't zero.
+		return false
+	}
+
+	m := new(big.Int).Rem(d.n, shiftInt(big.NewInt(1), int64(d.scale)))
+	return m.Sign() == 0
+}
+
+// CoEx returns this decimal's coefficient and exponent. The coefficient of a
+// negative zero is zero; use Signbit to tell the two apart.
 func (d *Decimal) CoEx() (*big.Int, int32) {
+	if d.n == nil {
+		return new(big.Int), -d.scale
+	}
 	return d.n, -d.scale
 }
 
@@ -115,6 +292,9 @@ func (d *Decimal) Add(o *Decimal) *Decimal {
 	return &Decimal{
 		n:     new(big.Int).Add(dd.n, oo.n),
 		scale: dd.scale,
+
+		// Only -0 + -0 gives a negative zero.
+		negZero: d.negZero && o.negZero,
 	}
 }
 
@@ -124,14 +304,18 @@ func (d *Decimal) Sub(o *Decimal) *Decimal {
 	return &Decimal{
 		n:     new(big.Int).Sub(dd.n, oo.n),
 		scale: dd.scale,
+
+		// Only -0 - 0 gives a negative zero.
+		negZero: d.negZero && o.n.Sign() == 0 && !o.negZero,
 	}
 }
 
-// Neg returns the negative of this Decimal.
+// Neg returns the negative of this Decimal. The negative of zero is -0.
 func (d *Decimal) Neg() *Decimal {
 	return &Decimal{
-		n:     new(big.Int).Neg(d.n),
-		scale: d.scale,
+		n:       new(big.Int).Neg(d.n),
+		scale:   d.scale,
+		negZero: d.n.Sign() == 0 && !d.negZero,
 	}
 }
 
@@ -143,9 +327,11 @@ func (d *Decimal) Mul(o *Decimal) *Decimal {
 		panic("exponent out of bounds")
 	}
 
+	n := new(big.Int).Mul(d.n, o.n)
 	return &Decimal{
-		n:     new(big.Int).Mul(d.n, o.n),
-		scale: int32(scale),
+		n:       n,
+		scale:   int32(scale),
+		negZero: n.Sign() == 0 && d.Signbit() != o.Signbit(),
 	}
 }
 
@@ -159,8 +345,9 @@ func (d *Decimal) ShiftL(shift int) *Decimal {
 	}
 
 	return &Decimal{
-		n:     d.n,
-		scale: int32(scale),
+		n:       d.n,
+		scale:   int32(scale),
+		negZero: d.negZero,
 	}
 }
 
@@ -174,19 +361,287 @@ func (d *Decimal) ShiftR(shift int) *Decimal {
 	}
 
 	return &Decimal{
-		n:     d.n,
+		n:       d.n,
+		scale:   int32(scale),
+		negZero: d.negZero,
+	}
+}
+
+// A RoundingMode determines how a Decimal is rounded when digits are dropped.
+type RoundingMode uint8
+
+const (
+	// RoundHalfEven rounds to the nearest value, and to the value with an even
+	// last digit if it's exactly halfway between two. This is the default.
+	RoundHalfEven RoundingMode = iota
+
+	// RoundHalfUp rounds to the nearest value, and away from zero if it's
+	// exactly halfway between two.
+	RoundHalfUp
+
+	// RoundDown rounds towards zero (truncates).
+	RoundDown
+
+	// RoundCeiling rounds towards positive infinity.
+	RoundCeiling
+
+	// RoundFloor rounds towards negative infinity.
+	RoundFloor
+)
+
+// A DecimalContext controls the precision of operations like Quo whose results
+// can't always be represented exactly.
+type DecimalContext struct {
+	// Precision is the maximum number of significant digits in a result. It
+	// must be positive.
+	Precision int
+
+	// Rounding is how results with too many digits are rounded.
+	Rounding RoundingMode
+}
+
+// DefaultDecimalContext has the precision of an IEEE 754 decimal128, 34 digits,
+// and rounds half-even.
+var DefaultDecimalContext = DecimalContext{Precision: 34, Rounding: RoundHalfEven}
+
+// Quo returns the quotient of this Decimal and another Decimal, rounded to the
+// context's precision. Exact quotients are returned with the smallest number of
+// digits that keeps the exponent at least the difference of the operands'
+// exponents, so 1/4 = 0.25 and 10/2 = 5. It panics if o is zero.
+func (d *Decimal) Quo(o *Decimal, ctx DecimalContext) *Decimal {
+	if ctx.Precision <= 0 {
+		panic("precision must be positive")
+	}
+	if o.n.Sign() == 0 {
+		panic("division by zero")
+	}
+
+	// a*10^x / b*10^y = (a/b) * 10^(x-y)
+	ideal := int64(o.scale) - int64(d.scale)
+	if d.n.Sign() == 0 {
+		zero := newDecimalScale(new(big.Int), -ideal)
+		zero.negZero = d.Signbit() != o.Signbit()
+		return zero
+	}
+
+	// Compute a*10^k / b for a k that gives the integer quotient exactly the
+	// context's precision (possibly one digit more, after rounding).
+	quo := func(k int64, mode RoundingMode) *big.Int {
+		if k >= 0 {
+			return roundQuo(shiftInt(d.n, k), o.n, mode)
+		}
+		return roundQuo(d.n, shiftInt(o.n, -k), mode)
+	}
+
+	k := int64(ctx.Precision) + int64(numDigits(o.n)) - int64(numDigits(d.n)) - 1
+	if numDigits(quo(k, RoundDown)) < ctx.Precision {
+		k++
+	}
+
+	q := quo(k, ctx.Rounding)
+	exp := ideal - k
+
+	if numDigits(q) > ctx.Precision {
+		// Rounding carried into a new digit; the one it pushed out is a zero.
+		q.Quo(q, ten)
+		exp++
+	}
+
+	// Drop trailing zeros until we reach the ideal exponent.
+	r := new(big.Int)
+	for exp < ideal {
+		qq, rr := new(big.Int).QuoRem(q, ten, r)
+		if rr.Sign() != 0 {
+			break
+		}
+		q = qq
+		exp++
+	}
+
+	return newDecimalScale(q, -exp)
+}
+
+// QuoRem returns the integer quotient of this Decimal and another Decimal,
+// truncated towards zero, and the remainder, such that d = q*o + r. The
+// quotient has an exponent of zero. It panics if o is zero.
+func (d *Decimal) QuoRem(o *Decimal) (*Decimal, *Decimal) {
+	if o.n.Sign() == 0 {
+		panic("division by zero")
+	}
+
+	dd, oo := rescale(d, o)
+	q, r := new(big.Int).QuoRem(dd.n, oo.n, new(big.Int))
+
+	return &Decimal{n: q, negZero: q.Sign() == 0 && d.Signbit() != o.Signbit()},
+		&Decimal{n: r, scale: dd.scale, negZero: r.Sign() == 0 && d.Signbit()}
+}
+
+// Round returns a new decimal, rounded to the given number of digits of
+// precision using the given rounding mode, so 19.Round(1, RoundHalfEven)
+// = 2d1.
+func (d *Decimal) Round(precision int, mode RoundingMode) *Decimal {
+	if precision <= 0 {
+		panic("precision must be positive")
+	}
+
+	diff := numDigits(d.n) - precision
+	if diff <= 0 {
+		// Already small enough, nothing to round.
+		return d
+	}
+
+	n := roundQuo(d.n, shiftInt(big.NewInt(1), int64(diff)), mode)
+	scale := int64(d.scale) - int64(diff)
+
+	if numDigits(n) > precision {
+		// Rounding carried into a new digit; the one it pushed out is a zero.
+		n.Quo(n, ten)
+		scale--
+	}
+
+	return newDecimalScale(n, scale)
+}
+
+// Rescale returns a new decimal with the given exponent, rounding with the given
+// mode if that means dropping digits. 1.25.Rescale(-1, RoundHalfEven) = 1.2, and
+// 1.25.Rescale(-3, RoundHalfEven) = 1.250.
+func (d *Decimal) Rescale(exp int32, mode RoundingMode) *Decimal {
+	scale := -int64(exp)
+	if scale > math.MaxInt32 {
+		panic("exponent out of bounds")
+	}
+	if scale >= int64(d.scale) {
+		return d.upscale(int32(scale))
+	}
+
+	diff := int64(d.scale) - scale
+	n := roundQuo(d.n, shiftInt(big.NewInt(1), diff), mode)
+	return &Decimal{
+		n:       n,
+		scale:   int32(scale),
+		negZero: n.Sign() == 0 && d.Signbit(),
+	}
+}
+
+// Pow returns this Decimal raised to the given integer power, rounded to the
+// context's precision. It squares and multiplies, rounding intermediate
+// results to a few guard digits past the context's precision, so the cost
+// grows with log(n) rather than n. A negative power gives the reciprocal of
+// the positive power. It panics if d is zero and n is negative.
+func (d *Decimal) Pow(n int, ctx DecimalContext) *Decimal {
+	if ctx.Precision <= 0 {
+		panic("precision must be positive")
+	}
+
+	// Negate in unsigned arithmetic so the most negative int doesn't overflow.
+	abs := uint64(n)
+	if n < 0 {
+		abs = -abs
+	}
+
+	// Each rounding is off by at most half a unit in the last guard digit, and
+	// there are at most two per bit of abs; len(abs)+2 guard digits keeps the
+	// accumulated error well below a unit in the last place of the result.
+	prec := ctx.Precision + len(strconv.FormatUint(abs, 10)) + 2
+
+	p := NewDecimalInt(1)
+	for b := d; abs > 0; abs >>= 1 {
+		if abs&1 == 1 {
+			p = p.Mul(b).Round(prec, RoundHalfEven)
+		}
+		if abs > 1 {
+			b = b.Mul(b).Round(prec, RoundHalfEven)
+		}
+	}
+
+	if n < 0 {
+		return NewDecimalInt(1).Quo(p, ctx)
+	}
+	return p.Round(ctx.Precision, ctx.Rounding)
+}
+
+// NewDecimalScale creates a new decimal with the given coefficient and scale,
+// panicing if the scale doesn't fit in an int32.
+func newDecimalScale(n *big.Int, scale int64) *Decimal {
+	if scale > math.MaxInt32 || scale < math.MinInt32 {
+		panic("exponent out of bounds")
+	}
+	return &Decimal{
+		n:     n,
 		scale: int32(scale),
 	}
 }
 
-// TODO: Div, Exp, etc?
+// NumDigits returns the number of decimal digits in n, ignoring its sign. Zero
+// has one digit.
+func numDigits(n *big.Int) int {
+	if n.Sign() == 0 {
+		return 1
+	}
+
+	// Estimate from the bit length, then correct by at most one.
+	digits := int(float64(n.BitLen()-1)*math.Log10(2)) + 1
+	if new(big.Int).Abs(n).Cmp(shiftInt(big.NewInt(1), int64(digits-1))) < 0 {
+		digits--
+	} else if new(big.Int).Abs(n).Cmp(shiftInt(big.NewInt(1), int64(digits))) >= 0 {
+		digits++
+	}
+	return digits
+}
+
+// ShiftInt returns n * 10^shift, for a non-negative shift.
+func shiftInt(n *big.Int, shift int64) *big.Int {
+	pow := new(big.Int).Exp(ten, big.NewInt(shift), nil)
+	return pow.Mul(n, pow)
+}
+
+// RoundQuo returns n/div, rounded to an integer using the given mode.
+func roundQuo(n, div *big.Int, mode RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(n, div, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+
+	// The sign of the (inexact) quotient, and so the direction to round away from zero.
+	sign := n.Sign() * div.Sign()
+
+	up := false
+	switch mode {
+	case RoundHalfEven, RoundHalfUp:
+		half := new(big.Int).Abs(r)
+		half.Lsh(half, 1)
+		switch half.CmpAbs(div) {
+		case 1:
+			up = true
+		case 0:
+			up = mode == RoundHalfUp || q.Bit(0) == 1
+		}
+	case RoundCeiling:
+		up = sign > 0
+	case RoundFloor:
+		up = sign < 0
+	}
+
+	if up {
+		q.Add(q, big.NewInt(int64(sign)))
+	}
+	return q
+}
 
 // Sign returns -1 if the value is less than 0, 0 if it is equal to zero,
 // and +1 if it is greater than zero.
 func (d *Decimal) Sign() int {
+	if d.n == nil {
+		return 0
+	}
 	return d.n.Sign()
 }
 
+// Signbit returns true if the value is negative or negative zero.
+func (d *Decimal) Signbit() bool {
+	return d.negZero || d.Sign() < 0
+}
+
 // Cmp compares two decimals, returning -1 if d is smaller, +1 if d is
 // larger, and 0 if they are equal (ignoring precision).
 func (d *Decimal) Cmp(o *Decimal) int {
@@ -226,8 +681,9 @@ func (d *Decimal) upscale(scale int32) *Decimal {
 	n := new(big.Int).Mul(d.n, pow)
 
 	return &Decimal{
-		n:     n,
-		scale: scale,
+		n:       n,
+		scale:   scale,
+		negZero: d.negZero,
 	}
 }
 
@@ -256,6 +712,20 @@ func (d *Decimal) Trunc() (int64, error) {
 	return strconv.ParseInt(str[:want], 10, 64)
 }
 
+// TruncBig truncates this decimal to a big.Int, dropping any fractional digits.
+func (d *Decimal) truncBig() *big.Int {
+	if d.scale <= 0 {
+		return d.upscale(0).n
+	}
+	if int64(d.scale) >= int64(numDigits(d.n)) {
+		// Every digit is after the decimal point; don't work out 10^scale to find
+		// out, since the scale could be huge.
+		return new(big.Int)
+	}
+	pow := new(big.Int).Exp(ten, big.NewInt(int64(d.scale)), nil)
+	return new(big.Int).Quo(d.n, pow)
+}
+
 // Truncate returns a new decimal, truncated to the given number of
 // decimal digits of precision. It does not round, so 19.Truncate(1)
 // = 1d1.
@@ -299,6 +769,13 @@ func (d *Decimal) Truncate(precision int) *Decimal {
 
 // String formats the decimal as a string in Ion text format.
 func (d *Decimal) String() string {
+	if d.n == nil {
+		return "0."
+	}
+	if d.negZero {
+		return "-" + d.Abs().String()
+	}
+
 	switch {
 	case d.scale == 0:
 		// Value is an unscaled integer. Just mark it as a decimal.
@@ -340,3 +817,209 @@ func (d *Decimal) String() string {
 		return b.String()
 	}
 }
+
+// Format implements fmt.Formatter. The %v and %s verbs format the decimal in Ion
+// text format, like String. The %f, %e, %E, %g and %G verbs format it like a float,
+// but with exactly the digits the decimal has unless a precision is given, in
+// which case it's rounded half-even. The width and the '+', '-', ' ' and '0' flags
+// work as they do for floats.
+func (d *Decimal) Format(s fmt.State, verb rune) {
+	prec, hasPrec := s.Precision()
+
+	switch verb {
+	case 'v', 's':
+		fmtPad(s, "", d.String(), false)
+
+	case 'f', 'F':
+		r := d
+		if hasPrec {
+			r = d.Rescale(int32(-prec), RoundHalfEven)
+		}
+		fmtNumber(s, r.Signbit(), r.fmtF())
+
+	case 'e', 'E':
+		r := d
+		if hasPrec {
+			r = d.Round(prec+1, RoundHalfEven)
+		}
+		str := r.fmtE(prec+1, hasPrec)
+		if verb == 'E' {
+			str = strings.ToUpper(str)
+		}
+		fmtNumber(s, r.Signbit(), str)
+
+	case 'g', 'G':
+		if hasPrec && prec == 0 {
+			prec = 1
+		}
+
+		r := d
+		if hasPrec {
+			r = d.Round(prec, RoundHalfEven)
+		}
+
+		// Like strconv, use %e if the exponent is less than -4 or at least the
+		// precision (or 6 if none is given).
+		nd := r.Precision()
+		exp := int64(nd) - 1 - int64(r.scale)
+		if r.n.Sign() == 0 {
+			exp = 0
+		}
+		eprec := int64(6)
+		if hasPrec {
+			eprec = int64(prec)
+			if eprec > int64(nd) && int64(nd) >= exp+1 {
+				eprec = int64(nd)
+			}
+		}
+
+		var str string
+		if exp < -4 || exp >= eprec {
+			str = r.fmtE(0, false)
+			if verb == 'G' {
+				str = strings.ToUpper(str)
+			}
+		} else {
+			str = r.fmtF()
+		}
+		fmtNumber(s, r.Signbit(), str)
+
+	default:
+		fmt.Fprintf(s, "%%!%c(*ion.Decimal=%v)", verb, d.String())
+	}
+}
+
+// FmtF formats the absolute value of the decimal without an exponent.
+func (d *Decimal) fmtF() string {
+	if d.scale < 0 {
+		d = d.upscale(0)
+	}
+
+	str := new(big.Int).Abs(d.n).String()
+	if d.scale == 0 {
+		return str
+	}
+
+	if pad := int(d.scale) + 1 - len(str); pad > 0 {
+		str = strings.Repeat("0", pad) + str
+	}
+	idx := len(str) - int(d.scale)
+	return str[:idx] + "." + str[idx:]
+}
+
+// FmtE formats the absolute value of the decimal with one digit before the
+// decimal point and an exponent, padding it with zeros to the given number of
+// digits if pad is true.
+func (d *Decimal) fmtE(digits int, pad bool) string {
+	str := new(big.Int).Abs(d.n).String()
+	exp := int64(len(str)) - 1 - int64(d.scale)
+	if d.n.Sign() == 0 {
+		// Zero has no leading digit to scale; always write it as 0e+00.
+		exp = 0
+	}
+
+	if pad && len(str) < digits {
+		str += strings.Repeat("0", digits-len(str))
+	}
+
+	b := strings.Builder{}
+	b.WriteString(str[:1])
+	if len(str) > 1 {
+		b.WriteString(".")
+		b.WriteString(str[1:])
+	}
+
+	b.WriteString("e")
+	if exp < 0 {
+		b.WriteString("-")
+		exp = -exp
+	} else {
+		b.WriteString("+")
+	}
+	if exp < 10 {
+		b.WriteString("0")
+	}
+	b.WriteString(strconv.FormatInt(exp, 10))
+
+	return b.String()
+}
+
+// FmtNumber writes a formatted number with the appropriate sign and padding.
+func fmtNumber(s fmt.State, neg bool, str string) {
+	sign := ""
+	switch {
+	case neg:
+		sign = "-"
+	case s.Flag('+'):
+		sign = "+"
+	case s.Flag(' '):
+		sign = " "
+	}
+	fmtPad(s, sign, str, s.Flag('0'))
+}
+
+// FmtPad writes a sign and a string, padded to the state's width with spaces, or
+// with zeros between the sign and the string if zeros is true.
+func fmtPad(s fmt.State, sign, str string, zeros bool) {
+	width, ok := s.Width()
+	pad := width - len(sign) - len(str)
+	if !ok || pad <= 0 {
+		fmt.Fprint(s, sign, str)
+		return
+	}
+
+	switch {
+	case s.Flag('-'):
+		fmt.Fprint(s, sign, str, strings.Repeat(" ", pad))
+	case zeros:
+		fmt.Fprint(s, sign, strings.Repeat("0", pad), str)
+	default:
+		fmt.Fprint(s, strings.Repeat(" ", pad), sign, str)
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler, formatting the decimal in Ion
+// text format.
+func (d *Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, parsing the decimal with
+// ParseDecimal.
+func (d *Decimal) UnmarshalText(text []byte) error {
+	dec, err := ParseDecimal(string(text))
+	if err != nil {
+		return err
+	}
+	*d = *dec
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, formatting the decimal as a JSON number.
+func (d *Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(formatJSONDecimal(d)), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, parsing a JSON number or a string
+// containing one. A JSON null leaves the decimal unchanged.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	str := string(data)
+	if str == "null" {
+		return nil
+	}
+	if len(str) >= 2 && str[0] == '"' && str[len(str)-1] == '"' {
+		str = str[1 : len(str)-1]
+	}
+
+	dec, err := ParseDecimal(strings.Map(func(r rune) rune {
+		if r == 'e' || r == 'E' {
+			return 'd'
+		}
+		return r
+	}, str))
+	if err != nil {
+		return err
+	}
+	*d = *dec
+	return nil
+}
@@ -1,8 +1,11 @@
 package ion
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
 	"math/big"
+	"math/bits"
 	"testing"
 )
 
@@ -310,3 +313,475 @@ func TestUpscale(t *testing.T) {
 		t.Errorf("expected 10.0000, got %v", actual)
 	}
 }
+
+func TestQuo(t *testing.T) {
+	test := func(a, b string, p int, mode RoundingMode, expected string) {
+		t.Run(fmt.Sprintf("%v/%v(%v,%v)", a, b, p, mode), func(t *testing.T) {
+			actual := MustParseDecimal(a).Quo(MustParseDecimal(b), DecimalContext{p, mode})
+			if actual.String() != expected {
+				t.Errorf("expected %v, got %v", expected, actual)
+			}
+		})
+	}
+
+	test("1", "4", 34, RoundHalfEven, "2.5d-1")
+	test("10", "2", 34, RoundHalfEven, "5.")
+	test("1.00", "2", 34, RoundHalfEven, "5.0d-1")
+	test("0", "7", 34, RoundHalfEven, "0.")
+	test("1d3", "1d1", 34, RoundHalfEven, "1d2")
+	test("1", "3", 5, RoundHalfEven, "3.3333d-1")
+	test("2", "3", 5, RoundHalfEven, "6.6667d-1")
+	test("2", "3", 5, RoundDown, "6.6666d-1")
+	test("-2", "3", 5, RoundHalfEven, "-6.6667d-1")
+	test("-2", "3", 5, RoundCeiling, "-6.6666d-1")
+	test("-2", "3", 5, RoundFloor, "-6.6667d-1")
+	test("1", "-3", 5, RoundFloor, "-3.3334d-1")
+	test("1", "-3", 5, RoundCeiling, "-3.3333d-1")
+	test("1", "8", 2, RoundHalfEven, "1.2d-1")
+	test("3", "8", 2, RoundHalfEven, "3.8d-1")
+	test("1", "8", 2, RoundHalfUp, "1.3d-1")
+	test("99999", "1", 3, RoundHalfEven, "100d3")
+	test("123456789", "3", 4, RoundHalfEven, "4115d4")
+	test("1d-100", "3d100", 2, RoundHalfEven, "3.3d-201")
+}
+
+func TestQuoRem(t *testing.T) {
+	test := func(a, b, eq, er string) {
+		t.Run(a+"/"+b, func(t *testing.T) {
+			q, r := MustParseDecimal(a).QuoRem(MustParseDecimal(b))
+			if q.String() != eq || r.String() != er {
+				t.Errorf("expected %v r %v, got %v r %v", eq, er, q, r)
+			}
+		})
+	}
+
+	test("7", "2", "3.", "1.")
+	test("-7", "2", "-3.", "-1.")
+	test("7.5", "2", "3.", "1.5")
+	test("1", "0.3", "3.", "1d-1")
+	test("1d2", "7", "14.", "2.")
+	test("0.5", "2", "0.", "5d-1")
+}
+
+func TestRound(t *testing.T) {
+	test := func(a string, p int, mode RoundingMode, expected string) {
+		t.Run(fmt.Sprintf("round(%v,%v,%v)", a, p, mode), func(t *testing.T) {
+			actual := MustParseDecimal(a).Round(p, mode).String()
+			if actual != expected {
+				t.Errorf("expected %v, got %v", expected, actual)
+			}
+		})
+	}
+
+	test("1", 1, RoundHalfEven, "1.")
+	test("19", 1, RoundHalfEven, "2d1")
+	test("19", 1, RoundDown, "1d1")
+	test("1.2345", 3, RoundHalfEven, "1.23")
+	test("1.2350", 3, RoundHalfEven, "1.24")
+	test("1.2450", 3, RoundHalfEven, "1.24")
+	test("1.2450", 3, RoundHalfUp, "1.25")
+	test("1.2451", 3, RoundHalfEven, "1.25")
+	test("-1.2450", 3, RoundHalfUp, "-1.25")
+	test("1.2401", 3, RoundCeiling, "1.25")
+	test("-1.2401", 3, RoundCeiling, "-1.24")
+	test("1.2499", 3, RoundFloor, "1.24")
+	test("-1.2401", 3, RoundFloor, "-1.25")
+	test("999", 2, RoundHalfEven, "10d2")
+	test("-999", 2, RoundHalfUp, "-10d2")
+	test("1.2345d-100", 2, RoundHalfEven, "1.2d-100")
+}
+
+func TestRescale(t *testing.T) {
+	test := func(a string, exp int32, mode RoundingMode, expected string) {
+		t.Run(fmt.Sprintf("rescale(%v,%v,%v)", a, exp, mode), func(t *testing.T) {
+			actual := MustParseDecimal(a).Rescale(exp, mode).String()
+			if actual != expected {
+				t.Errorf("expected %v, got %v", expected, actual)
+			}
+		})
+	}
+
+	test("1.25", -1, RoundHalfEven, "1.2")
+	test("1.35", -1, RoundHalfEven, "1.4")
+	test("1.25", -1, RoundHalfUp, "1.3")
+	test("1.25", -3, RoundHalfEven, "1.250")
+	test("1.25", 0, RoundHalfEven, "1.")
+	test("1.5", 0, RoundHalfEven, "2.")
+	test("-1.5", 0, RoundDown, "-1.")
+	test("0.004", -2, RoundCeiling, "1d-2")
+	test("-0.004", -2, RoundFloor, "-1d-2")
+	test("1250", 2, RoundHalfEven, "12d2")
+	test("1d2", 0, RoundHalfEven, "100.")
+}
+
+func TestPow(t *testing.T) {
+	test := func(a string, n int, p int, expected string) {
+		t.Run(fmt.Sprintf("%v^%v", a, n), func(t *testing.T) {
+			actual := MustParseDecimal(a).Pow(n, DecimalContext{p, RoundHalfEven}).String()
+			if actual != expected {
+				t.Errorf("expected %v, got %v", expected, actual)
+			}
+		})
+	}
+
+	test("2", 0, 34, "1.")
+	test("0", 0, 34, "1.")
+	test("2", 10, 34, "1024.")
+	test("1.1", 2, 34, "1.21")
+	test("-1.5", 3, 34, "-3.375")
+	test("1.1", 10, 5, "2.5937")
+	test("2", -2, 34, "2.5d-1")
+	test("3", -1, 5, "3.3333d-1")
+	test("1d10", 3, 34, "1d30")
+	test("1d10", -3, 34, "1d-30")
+	test("-0", 3, 34, "-0.")
+	test("-0", 2, 34, "0.")
+	test("1.0000001", 3000000, 10, "1.349858787")
+	test("1.0000001", -3000000, 10, "7.408182318d-1")
+	test("1", math.MaxInt32, 34, "1.")
+	test("1", -1<<(bits.UintSize-1), 34, "1.")
+	test("-1", -1<<(bits.UintSize-1), 34, "1.")
+	test("2", 100, 10, "1267650600d21")
+}
+
+func TestNegativeZero(t *testing.T) {
+	test := func(name string, d *Decimal, expected string) {
+		t.Run(name, func(t *testing.T) {
+			if actual := d.String(); actual != expected {
+				t.Errorf("expected %v, got %v", expected, actual)
+			}
+		})
+	}
+
+	nz := MustParseDecimal("-0")
+	z := MustParseDecimal("0")
+
+	test("parse", nz, "-0.")
+	test("parse exp", MustParseDecimal("-0d5"), "-0d5")
+	test("parse frac", MustParseDecimal("-0.00"), "-0d-2")
+	test("neg", z.Neg(), "-0.")
+	test("negneg", nz.Neg(), "0.")
+	test("abs", nz.Abs(), "0.")
+	test("-0+-0", nz.Add(nz), "-0.")
+	test("-0+0", nz.Add(z), "0.")
+	test("-0-0", nz.Sub(z), "-0.")
+	test("-0--0", nz.Sub(nz), "0.")
+	test("1-1", NewDecimalInt(1).Sub(NewDecimalInt(1)), "0.")
+	test("-0*1", nz.Mul(NewDecimalInt(1)), "-0.")
+	test("0*-1", z.Mul(NewDecimalInt(-1)), "-0.")
+	test("-0*-1", nz.Mul(NewDecimalInt(-1)), "0.")
+	test("-0/3", nz.Quo(NewDecimalInt(3), DefaultDecimalContext), "-0.")
+	test("shiftl", nz.ShiftL(2), "-0d2")
+	test("rescale", MustParseDecimal("-0.004").Rescale(-2, RoundHalfEven), "-0d-2")
+	test("upscale", nz.Rescale(-1, RoundHalfEven), "-0d-1")
+
+	if nz.Sign() != 0 || !nz.Signbit() || z.Signbit() {
+		t.Error("expected -0 to have sign 0 and signbit set")
+	}
+	if !nz.Equal(z) {
+		t.Error("expected -0 to equal 0")
+	}
+
+	f, err := nz.float()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f != 0 || !math.Signbit(f) {
+		t.Errorf("expected -0e0, got %v", f)
+	}
+
+	d, err := decimalFromFloat(math.Copysign(0, -1), 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Signbit() {
+		t.Errorf("expected -0., got %v", d)
+	}
+}
+
+func TestDecimalFromFloat(t *testing.T) {
+	test := func(f float64, eshort, eexact string) {
+		t.Run(fmt.Sprint(f), func(t *testing.T) {
+			short, err := NewDecimalFloat(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if short.String() != eshort {
+				t.Errorf("expected %v, got %v", eshort, short)
+			}
+
+			exact, err := NewDecimalFloatExact(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if exact.String() != eexact {
+				t.Errorf("expected %v, got %v", eexact, exact)
+			}
+		})
+	}
+
+	test(0, "0.", "0.")
+	test(math.Copysign(0, -1), "-0.", "-0.")
+	test(1, "1.", "1.")
+	test(-2.5, "-2.5", "-2.5")
+	test(0.1, "1d-1", "1.000000000000000055511151231257827021181583404541015625d-1")
+	test(1e20, "1d20", "100000000000000000000.")
+
+	tiny, err := NewDecimalFloatExact(math.SmallestNonzeroFloat64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tiny.Rat().Cmp(new(big.Rat).SetFloat64(math.SmallestNonzeroFloat64)) != 0 {
+		t.Errorf("expected 2^-1074, got %v", tiny)
+	}
+
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if _, err := NewDecimalFloat(f); err == nil {
+			t.Errorf("expected an error for %v", f)
+		}
+		if _, err := NewDecimalFloatExact(f); err == nil {
+			t.Errorf("expected an error for %v", f)
+		}
+	}
+}
+
+func TestDecimalFloat64(t *testing.T) {
+	test := func(d string, ef float64, eexact bool) {
+		t.Run(d, func(t *testing.T) {
+			f, exact := MustParseDecimal(d).Float64()
+			if f != ef || math.Signbit(f) != math.Signbit(ef) || exact != eexact {
+				t.Errorf("expected %v (exact=%v), got %v (exact=%v)", ef, eexact, f, exact)
+			}
+		})
+	}
+
+	test("0", 0, true)
+	test("-0", math.Copysign(0, -1), true)
+	test("1.5", 1.5, true)
+	test("0.1", 0.1, false)
+	test("1d400", math.Inf(1), false)
+	test("-1d400", math.Inf(-1), false)
+	test("1d-400", 0, false)
+	test("-1d-2147483647", math.Copysign(0, -1), false)
+	test("1.000000000000000055511151231257827021181583404541015625d-1", 0.1, true)
+}
+
+func TestDecimalBigFloat(t *testing.T) {
+	test := func(f *big.Float, expected string) {
+		t.Run(expected, func(t *testing.T) {
+			d, err := NewDecimalBigFloat(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d.String() != expected {
+				t.Errorf("expected %v, got %v", expected, d)
+			}
+
+			back := d.BigFloat(f.Prec())
+			if back.Cmp(f) != 0 || back.Acc() != big.Exact {
+				t.Errorf("expected %v, got %v (%v)", f, back, back.Acc())
+			}
+		})
+	}
+
+	test(big.NewFloat(0.75), "7.5d-1")
+	test(big.NewFloat(-1024), "-1024.")
+	test(new(big.Float).SetMantExp(big.NewFloat(1), -100), "7.888609052210118054117285652827862296732064351090230047702789306640625d-31")
+	test(new(big.Float).SetMantExp(big.NewFloat(3), 100), "3802951800684688204490109616128.")
+
+	if _, err := NewDecimalBigFloat(new(big.Float).SetInf(false)); err == nil {
+		t.Error("expected an error for +Inf")
+	}
+
+	f := MustParseDecimal("0.1").BigFloat(53)
+	if f.Acc() == big.Exact {
+		t.Error("expected 0.1 to be inexact")
+	}
+	if ff, _ := f.Float64(); ff != 0.1 {
+		t.Errorf("expected 0.1, got %v", ff)
+	}
+}
+
+func TestDecimalRat(t *testing.T) {
+	test := func(r *big.Rat, eround, eexact string) {
+		t.Run(r.String(), func(t *testing.T) {
+			round := NewDecimalRat(r, DecimalContext{10, RoundHalfEven})
+			if round.String() != eround {
+				t.Errorf("expected %v, got %v", eround, round)
+			}
+
+			exact, err := NewDecimalRatExact(r)
+			if eexact == "" {
+				if err == nil {
+					t.Errorf("expected an error, got %v", exact)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if exact.String() != eexact {
+				t.Errorf("expected %v, got %v", eexact, exact)
+			}
+			if exact.Rat().Cmp(r) != 0 {
+				t.Errorf("expected %v, got %v", r, exact.Rat())
+			}
+		})
+	}
+
+	test(big.NewRat(0, 1), "0.", "0.")
+	test(big.NewRat(1, 4), "2.5d-1", "2.5d-1")
+	test(big.NewRat(-3, 8), "-3.75d-1", "-3.75d-1")
+	test(big.NewRat(1, 80), "1.25d-2", "1.25d-2")
+	test(big.NewRat(12, 1), "12.", "12.")
+	test(big.NewRat(1, 3), "3.333333333d-1", "")
+	test(big.NewRat(-2, 7), "-2.857142857d-1", "")
+
+	if r := MustParseDecimal("1.5d3").Rat(); r.Cmp(big.NewRat(1500, 1)) != 0 {
+		t.Errorf("expected 1500, got %v", r)
+	}
+}
+
+func TestDecimalPrecisionScale(t *testing.T) {
+	test := func(d string, eprec int, escale int32, eint bool) {
+		t.Run(d, func(t *testing.T) {
+			dd := MustParseDecimal(d)
+			if p := dd.Precision(); p != eprec {
+				t.Errorf("expected precision %v, got %v", eprec, p)
+			}
+			if s := dd.Scale(); s != escale {
+				t.Errorf("expected scale %v, got %v", escale, s)
+			}
+			if i := dd.IsInteger(); i != eint {
+				t.Errorf("expected IsInteger=%v, got %v", eint, i)
+			}
+		})
+	}
+
+	test("0", 1, 0, true)
+	test("0.000", 1, 3, true)
+	test("123", 3, 0, true)
+	test("-1.50", 3, 2, false)
+	test("2.000", 4, 3, true)
+	test("1d5", 1, -5, true)
+	test("1d-5", 1, 5, false)
+	test("1.23d2", 3, 0, true)
+	test("1.234d2", 4, 1, false)
+}
+
+func TestDecimalFormat(t *testing.T) {
+	test := func(format, d, expected string) {
+		t.Run(format+"("+d+")", func(t *testing.T) {
+			actual := fmt.Sprintf(format, MustParseDecimal(d))
+			if actual != expected {
+				t.Errorf("expected %q, got %q", expected, actual)
+			}
+		})
+	}
+
+	test("%v", "1.50", "1.50")
+	test("%s", "1d3", "1d3")
+	test("%8v", "-1.5", "    -1.5")
+
+	test("%f", "1.50", "1.50")
+	test("%f", "-1d3", "-1000")
+	test("%f", "1.5d-3", "0.0015")
+	test("%f", "-0", "-0")
+	test("%.2f", "1.005", "1.00")
+	test("%.2f", "1.015", "1.02")
+	test("%.2f", "12", "12.00")
+	test("%.0f", "2.5", "2")
+	test("%.2f", "-0.004", "-0.00")
+	test("%+.1f", "3.14", "+3.1")
+	test("% .1f", "3.14", " 3.1")
+	test("%8.2f", "-3.14159", "   -3.14")
+	test("%-8.2f|", "3.14159", "3.14    |")
+	test("%08.2f", "-3.14159", "-0003.14")
+
+	test("%e", "123.45", "1.2345e+02")
+	test("%e", "0.00100", "1.00e-03")
+	test("%e", "0", "0e+00")
+	test("%e", "0.0", "0e+00")
+	test("%e", "-0.0", "-0e+00")
+	test("%e", "0d5", "0e+00")
+	test("%.2e", "-0.000", "-0.00e+00")
+	test("%E", "-1d100", "-1E+100")
+	test("%.2e", "123.45", "1.23e+02")
+	test("%.3e", "1", "1.000e+00")
+	test("%.1e", "9.96", "1.0e+01")
+
+	test("%g", "123.45", "123.45")
+	test("%g", "1.5d-5", "1.5e-05")
+	test("%g", "1234567", "1.234567e+06")
+	test("%g", "0.0001", "0.0001")
+	test("%g", "-0.0", "-0.0")
+	test("%g", "0d-6", "0.000000")
+	test("%.3g", "123.45", "123")
+	test("%.2g", "123.45", "1.2e+02")
+	test("%G", "1.5d-5", "1.5E-05")
+	test("%10.3g", "3.14159", "      3.14")
+
+	test("%d", "1", "%!d(*ion.Decimal=1.)")
+}
+
+func TestDecimalText(t *testing.T) {
+	d := MustParseDecimal("-1.50d3")
+
+	text, err := d.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(text) != "-150d1" {
+		t.Errorf("expected -150d1, got %v", string(text))
+	}
+
+	dd := Decimal{}
+	if err := dd.UnmarshalText(text); err != nil {
+		t.Fatal(err)
+	}
+	if !decimalEquiv(d, &dd) {
+		t.Errorf("expected %v, got %v", d, &dd)
+	}
+
+	if err := dd.UnmarshalText([]byte("abc")); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	type price struct {
+		Amount *Decimal `json:"amount"`
+		Tax    *Decimal `json:"tax"`
+	}
+
+	bs, err := json.Marshal(price{MustParseDecimal("12.50"), MustParseDecimal("1.5d-3")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"amount":12.50,"tax":1.5e-3}`; string(bs) != expected {
+		t.Errorf("expected %v, got %v", expected, string(bs))
+	}
+
+	test := func(in, eamount, etax string) {
+		t.Run(in, func(t *testing.T) {
+			p := price{}
+			if err := json.Unmarshal([]byte(in), &p); err != nil {
+				t.Fatal(err)
+			}
+			if p.Amount.String() != eamount {
+				t.Errorf("expected %v, got %v", eamount, p.Amount)
+			}
+			if (p.Tax == nil && etax != "") || (p.Tax != nil && p.Tax.String() != etax) {
+				t.Errorf("expected %v, got %v", etax, p.Tax)
+			}
+		})
+	}
+
+	test(string(bs), "12.50", "1.5d-3")
+	test(`{"amount":"7.25","tax":null}`, "7.25", "")
+	test(`{"amount":-1E+2}`, "-1d2", "")
+
+	if err := json.Unmarshal([]byte(`{"amount":true}`), &price{}); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -1,6 +1,10 @@
 package ion
 
-import "fmt"
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
 
 // A UsageError is returned when you use a Reader or Writer in an inappropriate way.
 type UsageError struct {
@@ -22,25 +26,42 @@ func (e *IOError) Error() string {
 	return fmt.Sprintf("ion: i/o error: %v", e.Err)
 }
 
+// Unwrap returns the underlying error.
+func (e *IOError) Unwrap() error {
+	return e.Err
+}
+
 // A SyntaxError is returned when a Reader encounters invalid input for which no more
 // specific error type is defined.
 type SyntaxError struct {
 	Msg    string
 	Offset uint64
+
+	// Line and Column are the one-based line and column of Offset in text input,
+	// or zero for binary input. Path is the path to the value being read when the
+	// error occurred, e.g. orders[17].items[2].price, or empty at the top level.
+	Line   int
+	Column int
+	Path   string
 }
 
 func (e *SyntaxError) Error() string {
-	return fmt.Sprintf("ion: syntax error: %v (offset %v)", e.Msg, e.Offset)
+	return fmt.Sprintf("ion: syntax error: %v (%v)", e.Msg, where(e.Offset, e.Line, e.Column, e.Path))
 }
 
 // An UnexpectedEOFError is returned when a Reader unexpectedly encounters an
 // io.EOF error.
 type UnexpectedEOFError struct {
 	Offset uint64
+
+	// Line, Column, and Path locate the error as for a SyntaxError.
+	Line   int
+	Column int
+	Path   string
 }
 
 func (e *UnexpectedEOFError) Error() string {
-	return fmt.Sprintf("ion: unexpected end of input (offset %v)", e.Offset)
+	return fmt.Sprintf("ion: unexpected end of input (%v)", where(e.Offset, e.Line, e.Column, e.Path))
 }
 
 // An UnsupportedVersionError is returned when a Reader encounters a binary version
@@ -49,10 +70,15 @@ type UnsupportedVersionError struct {
 	Major  int
 	Minor  int
 	Offset uint64
+
+	// Line, Column, and Path locate the error as for a SyntaxError.
+	Line   int
+	Column int
+	Path   string
 }
 
 func (e *UnsupportedVersionError) Error() string {
-	return fmt.Sprintf("ion: unsupported version %v.%v (offset %v)", e.Major, e.Minor, e.Offset)
+	return fmt.Sprintf("ion: unsupported version %v.%v (%v)", e.Major, e.Minor, where(e.Offset, e.Line, e.Column, e.Path))
 }
 
 // An InvalidTagByteError is returned when a binary Reader encounters an invalid
@@ -60,20 +86,30 @@ func (e *UnsupportedVersionError) Error() string {
 type InvalidTagByteError struct {
 	Byte   byte
 	Offset uint64
+
+	// Line, Column, and Path locate the error as for a SyntaxError.
+	Line   int
+	Column int
+	Path   string
 }
 
 func (e *InvalidTagByteError) Error() string {
-	return fmt.Sprintf("ion: invalid tag byte 0x%02X (offset %v)", e.Byte, e.Offset)
+	return fmt.Sprintf("ion: invalid tag byte 0x%02X (%v)", e.Byte, where(e.Offset, e.Line, e.Column, e.Path))
 }
 
 // An UnexpectedRuneError is returned when a text Reader encounters an unexpected rune.
 type UnexpectedRuneError struct {
 	Rune   rune
 	Offset uint64
+
+	// Line, Column, and Path locate the error as for a SyntaxError.
+	Line   int
+	Column int
+	Path   string
 }
 
 func (e *UnexpectedRuneError) Error() string {
-	return fmt.Sprintf("ion: unexpected rune %q (offset %v)", e.Rune, e.Offset)
+	return fmt.Sprintf("ion: unexpected rune %q (%v)", e.Rune, where(e.Offset, e.Line, e.Column, e.Path))
 }
 
 // An UnexpectedTokenError is returned when a text Reader encounters an unexpected
@@ -81,8 +117,104 @@ func (e *UnexpectedRuneError) Error() string {
 type UnexpectedTokenError struct {
 	Token  string
 	Offset uint64
+
+	// Line, Column, and Path locate the error as for a SyntaxError.
+	Line   int
+	Column int
+	Path   string
 }
 
 func (e *UnexpectedTokenError) Error() string {
-	return fmt.Sprintf("ion: unexpected token '%v' (offset %v)", e.Token, e.Offset)
+	return fmt.Sprintf("ion: unexpected token '%v' (%v)", e.Token, where(e.Offset, e.Line, e.Column, e.Path))
+}
+
+// A DecodeError is returned when a Decoder cannot decode an Ion value to the Go
+// value it was asked to decode it to.
+type DecodeError struct {
+	Path   string       // The path to the Ion value, e.g. orders[17].items[2].price.
+	Type   Type         // The type of the Ion value.
+	GoType reflect.Type // The Go type it was being decoded to.
+	Struct string       // The name of the Go struct type containing the field, if any.
+	Field  string       // The name of the Go struct field, if any.
+	Offset uint64       // The offset of the Ion value in the input.
+
+	Msg string // What went wrong, if more specific than a type mismatch.
+	Err error  // The underlying error, e.g. from an Unmarshaler, if any.
+}
+
+func (e *DecodeError) Error() string {
+	b := strings.Builder{}
+	b.WriteString("ion: ")
+
+	switch {
+	case e.Msg != "":
+		b.WriteString(e.Msg)
+	case e.Err != nil:
+		fmt.Fprintf(&b, "cannot decode %v to %v: %v", e.Type, e.GoType, e.Err)
+	default:
+		fmt.Fprintf(&b, "cannot decode %v to %v", e.Type, e.GoType)
+	}
+
+	fmt.Fprintf(&b, " (offset %v", e.Offset)
+	if e.Path != "" {
+		fmt.Fprintf(&b, ", at %v", e.Path)
+	}
+	if e.Field != "" {
+		fmt.Fprintf(&b, ", field %v.%v", e.Struct, e.Field)
+	}
+	b.WriteString(")")
+
+	return b.String()
+}
+
+// Unwrap returns the underlying error, if any.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Where formats the position at which an error occurred.
+func where(offset uint64, line, col int, path string) string {
+	b := strings.Builder{}
+	fmt.Fprintf(&b, "offset %v", offset)
+	if line > 0 {
+		fmt.Fprintf(&b, ", line %v, column %v", line, col)
+	}
+	if path != "" {
+		fmt.Fprintf(&b, ", at %v", path)
+	}
+	return b.String()
+}
+
+// WithPosition fills in the line, column, and path of an error that has an
+// offset. Other errors are returned as-is. The lineCol func, if non-nil, maps
+// an offset to a line and column.
+func withPosition(err error, lineCol func(uint64) (int, int), path string) error {
+	var off uint64
+	var line, col *int
+	var p *string
+
+	switch e := err.(type) {
+	case *SyntaxError:
+		off, line, col, p = e.Offset, &e.Line, &e.Column, &e.Path
+	case *UnexpectedEOFError:
+		off, line, col, p = e.Offset, &e.Line, &e.Column, &e.Path
+	case *UnsupportedVersionError:
+		off, line, col, p = e.Offset, &e.Line, &e.Column, &e.Path
+	case *InvalidTagByteError:
+		off, line, col, p = e.Offset, &e.Line, &e.Column, &e.Path
+	case *UnexpectedRuneError:
+		off, line, col, p = e.Offset, &e.Line, &e.Column, &e.Path
+	case *UnexpectedTokenError:
+		off, line, col, p = e.Offset, &e.Line, &e.Column, &e.Path
+	default:
+		return err
+	}
+
+	if lineCol != nil && *line == 0 {
+		*line, *col = lineCol(off)
+	}
+	if *p == "" {
+		*p = path
+	}
+	return err
 }
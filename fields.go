@@ -4,28 +4,134 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
 )
 
 // A field is a reflectively-accessed field of a struct type.
 type field struct {
-	name      string
-	typ       reflect.Type
-	path      []int
+	name string
+	typ  reflect.Type
+	path []int
+
 	omitEmpty bool
+	omitZero  bool
+
+	// If set, the field holds the annotations of the struct rather than a field.
+	annotations bool
+
+	// Ion-specific options, only settable via an `ion:"..."` tag.
+	symbol    bool
+	decimal   bool
+	lob       Type
+	precision TimestampPrecision
+	fraction  int
+	str       bool
 }
 
-// A fielder maps out the fields of a type.
-type fielder struct {
-	fields []field
-	index  map[string]bool
+// HasEncodingOpts returns true if the field has options that affect how its value
+// is encoded or decoded.
+func (f *field) hasEncodingOpts() bool {
+	return f.symbol || f.decimal || f.lob != NoType || f.precision != 0 || f.str
 }
 
+// StructFields is the compiled form of a struct type's fields, built once per
+// type and shared by every Encoder and Decoder.
+type structFields struct {
+	list []field
+
+	// Annotations holds the indexes of fields with the annotations option.
+	annotations []int
+
+	// ByName maps each field's name to its index; byFold maps the case-folded
+	// name to the index of the first field with that folded name.
+	byName map[string]int
+	byFold map[string]int
+}
+
+// Find returns the index of the field with the given name, falling back to a
+// case-insensitive match unless caseSensitive is true, or -1 if there is none.
+func (fs *structFields) find(name string, caseSensitive bool) int {
+	if i, ok := fs.byName[name]; ok {
+		return i
+	}
+	if !caseSensitive {
+		if i, ok := fs.byFold[foldName(name)]; ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// FieldCache maps each struct type that's been encoded or decoded to its
+// *structFields.
+var fieldCache sync.Map
+
 // FieldsFor returns the fields of the given struct type.
-// TODO: cache me.
-func fieldsFor(t reflect.Type) []field {
+func fieldsFor(t reflect.Type) *structFields {
+	if fs, ok := fieldCache.Load(t); ok {
+		return fs.(*structFields)
+	}
+
 	fldr := fielder{index: map[string]bool{}}
 	fldr.inspect(t, nil)
-	return fldr.fields
+
+	fs := &structFields{
+		list:   fldr.fields,
+		byName: make(map[string]int, len(fldr.fields)),
+		byFold: make(map[string]int, len(fldr.fields)),
+	}
+	for i := range fs.list {
+		f := &fs.list[i]
+		if f.annotations {
+			fs.annotations = append(fs.annotations, i)
+			continue
+		}
+
+		fs.byName[f.name] = i
+		fold := foldName(f.name)
+		if _, ok := fs.byFold[fold]; !ok {
+			fs.byFold[fold] = i
+		}
+	}
+
+	actual, _ := fieldCache.LoadOrStore(t, fs)
+	return actual.(*structFields)
+}
+
+// FoldName returns a key that's the same for any two names that are equal under
+// Unicode case-folding, as with strings.EqualFold.
+func foldName(name string) string {
+	b := strings.Builder{}
+	b.Grow(len(name))
+
+	// Map each rune to the smallest rune in its folding orbit, which for ASCII
+	// letters is the upper-case one.
+	for _, r := range name {
+		if r < utf8.RuneSelf {
+			if 'a' <= r && r <= 'z' {
+				r -= 'a' - 'A'
+			}
+		} else {
+			min := r
+			for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+				if f < min {
+					min = f
+				}
+			}
+			r = min
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// A fielder maps out the fields of a type.
+type fielder struct {
+	fields []field
+	index  map[string]bool
 }
 
 // Inspect recursively inspects a type to determine all of its fields.
@@ -37,7 +143,11 @@ func (f *fielder) inspect(t reflect.Type, path []int) {
 			continue
 		}
 
-		tag := sf.Tag.Get("json")
+		// An ion tag, if present, takes precedence over a json tag.
+		tag, ion := sf.Tag.Lookup("ion")
+		if !ion {
+			tag = sf.Tag.Get("json")
+		}
 		if tag == "-" {
 			// Skip fields that are explicitly hidden by tag.
 			continue
@@ -62,17 +172,25 @@ func (f *fielder) inspect(t reflect.Type, path []int) {
 				name = sf.Name
 			}
 
-			if f.index[name] {
-				panic(fmt.Sprintf("too many fields named %v", name))
+			fld := field{
+				name: name,
+				typ:  ft,
+				path: newpath,
+			}
+			parseFieldOpts(&fld, opts, ion)
+
+			if fld.annotations {
+				if ft != stringSliceType {
+					panic(fmt.Sprintf("annotations field %v must be a []string", sf.Name))
+				}
+			} else {
+				if f.index[name] {
+					panic(fmt.Sprintf("too many fields named %v", name))
+				}
+				f.index[name] = true
 			}
-			f.index[name] = true
-
-			f.fields = append(f.fields, field{
-				name:      name,
-				typ:       ft,
-				path:      newpath,
-				omitEmpty: omitEmpty(opts),
-			})
+
+			f.fields = append(f.fields, fld)
 		}
 	}
 }
@@ -93,17 +211,28 @@ func visible(sf *reflect.StructField) bool {
 	return exported
 }
 
-// ParseJSONTag parses a `json:"..."` field tag, returning the name and opts.
+// ParseJSONTag parses a `json:"..."` or `ion:"..."` field tag, returning the name and opts.
 func parseJSONTag(tag string) (string, string) {
 	if idx := strings.Index(tag, ","); idx != -1 {
-		// Ignore additional JSON options, at least for now.
 		return tag[:idx], tag[idx+1:]
 	}
 	return tag, ""
 }
 
-// OmitEmpty returns true if opts includes "omitempty".
-func omitEmpty(opts string) bool {
+// ParseFieldOpts parses the options from a field tag. JSON tags only support
+// omitempty; other JSON options are ignored. Ion tags additionally support:
+//
+// 	omitzero           omit the field if its value is the zero value for its type
+// 	symbol             write strings as Ion symbols instead of strings
+// 	decimal            write floats and ints as Ion decimals
+// 	blob, clob         write strings and []bytes as the given lob type
+// 	timestamp=<prec>   write time.Times with the given precision: year, month, day,
+// 	                   minute, second, millisecond, microsecond, or nanosecond
+// 	string             write bools and numbers as Ion strings, and read them back
+// 	annotations        the field is a []string holding the struct's annotations
+//
+// Options that don't make sense for a field's type are ignored.
+func parseFieldOpts(f *field, opts string, ion bool) {
 	for opts != "" {
 		var o string
 
@@ -115,8 +244,54 @@ func omitEmpty(opts string) bool {
 		}
 
 		if o == "omitempty" {
-			return true
+			f.omitEmpty = true
 		}
+		if !ion {
+			continue
+		}
+
+		switch {
+		case o == "omitzero":
+			f.omitZero = true
+		case o == "symbol":
+			f.symbol = true
+		case o == "decimal":
+			f.decimal = true
+		case o == "blob":
+			f.lob = BlobType
+		case o == "clob":
+			f.lob = ClobType
+		case o == "string":
+			f.str = true
+		case o == "annotations":
+			f.annotations = true
+		case strings.HasPrefix(o, "timestamp="):
+			f.precision, f.fraction = parsePrecisionOpt(o[len("timestamp="):])
+		}
+	}
+}
+
+// ParsePrecisionOpt parses the value of a timestamp=<prec> tag option, returning the
+// timestamp precision and, for fractional precisions, the number of fractional digits.
+// It returns a zero precision, meaning no option, for precisions it doesn't know.
+func parsePrecisionOpt(prec string) (TimestampPrecision, int) {
+	switch prec {
+	case "year":
+		return TimestampPrecisionYear, 0
+	case "month":
+		return TimestampPrecisionMonth, 0
+	case "day":
+		return TimestampPrecisionDay, 0
+	case "minute":
+		return TimestampPrecisionMinute, 0
+	case "second":
+		return TimestampPrecisionSecond, 0
+	case "millisecond":
+		return TimestampPrecisionFraction, 3
+	case "microsecond":
+		return TimestampPrecisionFraction, 6
+	case "nanosecond":
+		return TimestampPrecisionFraction, 9
 	}
-	return false
+	return 0, 0
 }
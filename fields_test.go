@@ -0,0 +1,68 @@
+package ion
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestFieldsForCached(t *testing.T) {
+	type cached struct {
+		A int
+		B string `ion:"b,omitempty"`
+	}
+	typ := reflect.TypeOf(cached{})
+
+	wg := sync.WaitGroup{}
+	results := make([]*structFields, 8)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = fieldsFor(typ)
+		}(i)
+	}
+	wg.Wait()
+
+	first := fieldsFor(typ)
+	for _, fs := range results {
+		if fs != first {
+			t.Error("expected every call to return the cached fields")
+		}
+	}
+	if len(first.list) != 2 || first.list[1].name != "b" || !first.list[1].omitEmpty {
+		t.Errorf("unexpected fields %+v", first.list)
+	}
+}
+
+func TestFindField(t *testing.T) {
+	type fields struct {
+		Annotations []string `ion:",annotations"`
+		Name        string
+		NAME        string `ion:"naMe"`
+		Kelvin      int    `ion:"k"`
+		Long        int    `ion:"ſ"`
+	}
+	fs := fieldsFor(reflect.TypeOf(fields{}))
+
+	test := func(name string, caseSensitive bool, eidx int) {
+		t.Run(name, func(t *testing.T) {
+			if i := fs.find(name, caseSensitive); i != eidx {
+				t.Errorf("expected %v, got %v", eidx, i)
+			}
+		})
+	}
+
+	test("Name", false, 1)
+	test("naMe", false, 2)
+	test("NAME", false, 1)
+	test("NAME", true, -1)
+	test("k", true, 3)
+	test("K", false, 3)
+	test("K", false, 3) // Kelvin sign
+	test("\u017F", true, 4)
+	test("s", false, 4)
+	test("S", false, 4)
+	test("Annotations", false, -1)
+	test("bogus", false, -1)
+}
@@ -0,0 +1,133 @@
+package ion
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// A Symbol is a string that the Encoder writes as an Ion symbol rather than an Ion
+// string. Decoder.Decode returns symbols as Symbols, so they round-trip as symbols.
+type Symbol string
+
+// A Sexp is a list of values that the Encoder writes as an Ion sexp rather than an
+// Ion list. Decoder.Decode returns sexps as Sexps.
+//
+// 	Encode(Sexp{Symbol("+"), 1, 2}) // (+ 1 2)
+type Sexp []interface{}
+
+// A Clob is a byte slice that the Encoder writes as an Ion clob rather than an Ion
+// blob. Decoder.Decode returns clobs as Clobs.
+type Clob []byte
+
+// A Number is the text of an Ion int, decimal or float, like 42, 4.20 or 4.2e1,
+// which keeps the exact value and the Ion type of a number that's just passing
+// through. The Encoder writes a Number as whichever of the three it looks like, or
+// as a null if it's empty; the Decoder decodes any of them to one, and, with
+// DecodeUseNumber, Decoder.Decode returns them as Numbers.
+type Number string
+
+// String returns the number's text.
+func (n Number) String() string {
+	return string(n)
+}
+
+// Type returns the Ion type the number's text looks like: IntType, DecimalType or
+// FloatType, or NoType if it's empty. It doesn't check that the text is valid.
+func (n Number) Type() Type {
+	s := strings.ToLower(strings.TrimLeft(string(n), "+-"))
+	switch {
+	case s == "":
+		return NoType
+	case s == "nan" || s == "inf":
+		return FloatType
+	case strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0b"):
+		// Hex digits include an 'e', which isn't an exponent.
+		return IntType
+	case strings.Contains(s, "e"):
+		return FloatType
+	case strings.ContainsAny(s, ".d"):
+		return DecimalType
+	}
+	return IntType
+}
+
+// BigInt returns the number as a big.Int, if it's an int.
+func (n Number) BigInt() (*big.Int, error) {
+	if n.Type() == IntType {
+		if i, ok := new(big.Int).SetString(string(n), 0); ok {
+			return i, nil
+		}
+	}
+	return nil, fmt.Errorf("ion: cannot parse %q as an int", string(n))
+}
+
+// Int64 returns the number as an int64, if it's an int that fits in one.
+func (n Number) Int64() (int64, error) {
+	i, err := n.BigInt()
+	if err != nil {
+		return 0, err
+	}
+	if !i.IsInt64() {
+		return 0, fmt.Errorf("ion: int %v overflows an int64", i)
+	}
+	return i.Int64(), nil
+}
+
+// Float64 returns the float64 nearest to the number, whatever its type.
+func (n Number) Float64() (float64, error) {
+	switch n.Type() {
+	case IntType:
+		i, err := n.BigInt()
+		if err != nil {
+			return 0, err
+		}
+		f, _ := new(big.Float).SetInt(i).Float64()
+		return f, nil
+
+	case DecimalType:
+		d, err := ParseDecimal(string(n))
+		if err != nil {
+			return 0, err
+		}
+		return d.float()
+
+	case FloatType:
+		if f, err := strconv.ParseFloat(string(n), 64); err == nil || isRangeError(err) {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("ion: cannot parse %q as a number", string(n))
+}
+
+// Decimal returns the number as a Decimal. Floats are converted to the shortest
+// decimal that converts back to the same float, and NaN and infinities give an
+// error.
+func (n Number) Decimal() (*Decimal, error) {
+	switch n.Type() {
+	case IntType:
+		i, err := n.BigInt()
+		if err != nil {
+			return nil, err
+		}
+		return NewDecimal(i, 0), nil
+
+	case DecimalType:
+		return ParseDecimal(string(n))
+
+	case FloatType:
+		f, err := n.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return NewDecimalFloat(f)
+	}
+	return nil, fmt.Errorf("ion: cannot parse %q as a number", string(n))
+}
+
+// IsRangeError returns true if err is a strconv error for a value out of range.
+func isRangeError(err error) bool {
+	ne, ok := err.(*strconv.NumError)
+	return ok && ne.Err == strconv.ErrRange
+}
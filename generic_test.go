@@ -0,0 +1,69 @@
+package ion
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNumberType(t *testing.T) {
+	test := func(n Number, eval Type) {
+		t.Run(string(n), func(t *testing.T) {
+			if typ := n.Type(); typ != eval {
+				t.Errorf("expected %v, got %v", eval, typ)
+			}
+		})
+	}
+
+	test("", NoType)
+	test("42", IntType)
+	test("-0x1E", IntType)
+	test("0b101", IntType)
+	test("4.2", DecimalType)
+	test("42d-1", DecimalType)
+	test("4.2e1", FloatType)
+	test("-4E1", FloatType)
+	test("nan", FloatType)
+	test("+inf", FloatType)
+}
+
+func TestNumberConversions(t *testing.T) {
+	test := func(n Number, i int64, f float64, d string) {
+		t.Run(string(n), func(t *testing.T) {
+			if ival, err := n.Int64(); err != nil || ival != i {
+				t.Errorf("expected Int64 %v, got %v, %v", i, ival, err)
+			}
+			if fval, err := n.Float64(); err != nil || fval != f {
+				t.Errorf("expected Float64 %v, got %v, %v", f, fval, err)
+			}
+			if dval, err := n.Decimal(); err != nil || dval.String() != d {
+				t.Errorf("expected Decimal %v, got %v, %v", d, dval, err)
+			}
+		})
+	}
+
+	test("42", 42, 42, "42.")
+	test("-0x1F", -31, -31, "-31.")
+	test("1_000", 1000, 1000, "1000.")
+
+	if _, err := Number("4.2").Int64(); err == nil {
+		t.Error("expected an error getting a decimal as an int64")
+	}
+	if _, err := Number("9223372036854775808").Int64(); err == nil {
+		t.Error("expected an error getting a big int as an int64")
+	}
+	if f, err := Number("4.25").Float64(); err != nil || f != 4.25 {
+		t.Errorf("expected 4.25, got %v, %v", f, err)
+	}
+	if f, err := Number("1e400").Float64(); err != nil || !math.IsInf(f, 1) {
+		t.Errorf("expected +inf, got %v, %v", f, err)
+	}
+	if d, err := Number("4.25e0").Decimal(); err != nil || d.String() != "4.25" {
+		t.Errorf("expected 4.25, got %v, %v", d, err)
+	}
+	if _, err := Number("nan").Decimal(); err == nil {
+		t.Error("expected an error getting nan as a decimal")
+	}
+	if _, err := Number("bogus").Float64(); err == nil {
+		t.Error("expected an error parsing a bogus number")
+	}
+}
@@ -2,14 +2,30 @@ package ion
 
 import (
 	"bytes"
+	"encoding"
 	"fmt"
 	"io"
 	"math/big"
 	"reflect"
 	"sort"
+	"strconv"
 	"time"
 )
 
+// A Marshaler is a type that can marshal itself to Ion. MarshalIon must write
+// exactly one value to the Writer; any field name or annotations for the value
+// will already have been set by the Encoder.
+type Marshaler interface {
+	MarshalIon(w Writer) error
+}
+
+// An Annotated type has a fixed set of annotations, which the Encoder writes on
+// each of its values. The Decoder can optionally reject values that are not
+// annotated accordingly; see DecodeStrictAnnotations.
+type Annotated interface {
+	IonAnnotations() []string
+}
+
 // EncoderOpts holds bit-flag options for an Encoder.
 type EncoderOpts uint
 
@@ -130,6 +146,20 @@ func (m *Encoder) encodeValue(v reflect.Value) error {
 		return nil
 	}
 
+	if v.Kind() != reflect.Ptr {
+		// Pointers pick these up from the value they point to.
+		m.w.Annotations(staticAnnotations(v)...)
+	}
+
+	if i, ok := implementer(v, marshalerType); ok {
+		return i.(Marshaler).MarshalIon(m.w)
+	}
+	if !isNativeType(v.Type()) {
+		if i, ok := implementer(v, textMarshalerType); ok {
+			return m.encodeText(i.(encoding.TextMarshaler))
+		}
+	}
+
 	t := v.Type()
 	switch t.Kind() {
 	case reflect.Bool:
@@ -146,11 +176,14 @@ func (m *Encoder) encodeValue(v reflect.Value) error {
 		i.SetUint64(v.Uint())
 		return m.w.WriteBigInt(&i)
 
-	case reflect.Float32, reflect.Float64:
+	case reflect.Float32:
+		return m.w.WriteFloat32(float32(v.Float()))
+
+	case reflect.Float64:
 		return m.w.WriteFloat(v.Float())
 
 	case reflect.String:
-		return m.w.WriteString(v.String())
+		return m.encodeString(v)
 
 	case reflect.Interface, reflect.Ptr:
 		return m.encodePtr(v)
@@ -172,6 +205,15 @@ func (m *Encoder) encodeValue(v reflect.Value) error {
 	}
 }
 
+// EncodeText encodes an encoding.TextMarshaler to the output writer as an Ion string.
+func (m *Encoder) encodeText(tm encoding.TextMarshaler) error {
+	text, err := tm.MarshalText()
+	if err != nil {
+		return err
+	}
+	return m.w.WriteString(string(text))
+}
+
 // EncodePtr encodes an Ion null if the pointer is nil, and otherwise encodes the value that
 // the pointer is pointing to.
 func (m *Encoder) encodePtr(v reflect.Value) error {
@@ -187,13 +229,16 @@ func (m *Encoder) encodeMap(v reflect.Value) error {
 		return m.w.WriteNull()
 	}
 
-	m.w.BeginStruct()
-
-	keys := keysFor(v)
+	keys, err := keysFor(v)
+	if err != nil {
+		return err
+	}
 	if m.opts&EncodeSortMaps != 0 {
 		sort.Slice(keys, func(i, j int) bool { return keys[i].s < keys[j].s })
 	}
 
+	m.w.BeginStruct()
+
 	for _, key := range keys {
 		m.w.FieldName(key.s)
 		value := v.MapIndex(key.v)
@@ -212,22 +257,89 @@ type mapkey struct {
 }
 
 // KeysFor returns the stringified keys for the given map.
-func keysFor(v reflect.Value) []mapkey {
+func keysFor(v reflect.Value) ([]mapkey, error) {
 	keys := v.MapKeys()
 	res := make([]mapkey, len(keys))
 
 	for i, key := range keys {
-		// TODO: Handle other kinds of keys.
-		if key.Kind() != reflect.String {
-			panic("unexpected map key type")
+		s, err := keyString(key)
+		if err != nil {
+			return nil, err
 		}
 		res[i] = mapkey{
 			v: key,
-			s: key.String(),
+			s: s,
 		}
 	}
 
-	return res
+	return res, nil
+}
+
+// KeyString returns the stringified form of a map key: strings as they are, then
+// anything implementing encoding.TextMarshaler, then integers and bools.
+func keyString(key reflect.Value) (string, error) {
+	if key.Kind() == reflect.String {
+		return key.String(), nil
+	}
+
+	if i, ok := implementer(key, textMarshalerType); ok {
+		text, err := i.(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return "", err
+		}
+		return string(text), nil
+	}
+
+	switch key.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(key.Uint(), 10), nil
+	case reflect.Bool:
+		return strconv.FormatBool(key.Bool()), nil
+	}
+
+	return "", fmt.Errorf("ion: unsupported map key type: %v", key.Type().String())
+}
+
+// EncodeString encodes a string to the output writer as an Ion string, or as a
+// symbol or number if it's a Symbol or Number.
+func (m *Encoder) encodeString(v reflect.Value) error {
+	switch v.Type() {
+	case symbolType:
+		return m.w.WriteSymbol(v.String())
+	case numberType:
+		return m.encodeNumber(Number(v.String()))
+	}
+	return m.w.WriteString(v.String())
+}
+
+// EncodeNumber encodes a Number to the output writer as the type of Ion number it
+// looks like.
+func (m *Encoder) encodeNumber(n Number) error {
+	switch n.Type() {
+	case IntType:
+		i, err := n.BigInt()
+		if err != nil {
+			return err
+		}
+		return m.w.WriteBigInt(i)
+
+	case DecimalType:
+		d, err := n.Decimal()
+		if err != nil {
+			return err
+		}
+		return m.w.WriteDecimal(d)
+
+	case FloatType:
+		f, err := n.Float64()
+		if err != nil {
+			return err
+		}
+		return m.w.WriteFloat(f)
+	}
+	return m.w.WriteNull()
 }
 
 // EncodeSlice encodes a slice to the output writer as an appropriate Ion type.
@@ -240,17 +352,37 @@ func (m *Encoder) encodeSlice(v reflect.Value) error {
 		return m.w.WriteNull()
 	}
 
+	if v.Type() == sexpType {
+		return m.encodeSexp(v)
+	}
 	return m.encodeArray(v)
 }
 
-// EncodeBlob encodes a []byte to the output writer as an Ion blob.
+// EncodeBlob encodes a []byte to the output writer as an Ion blob, or as a clob if
+// it's a Clob.
 func (m *Encoder) encodeBlob(v reflect.Value) error {
 	if v.IsNil() {
 		return m.w.WriteNull()
 	}
+	if v.Type() == clobType {
+		return m.w.WriteClob(v.Bytes())
+	}
 	return m.w.WriteBlob(v.Bytes())
 }
 
+// EncodeSexp encodes a Sexp to the output writer as an Ion sexp.
+func (m *Encoder) encodeSexp(v reflect.Value) error {
+	m.w.BeginSexp()
+
+	for i := 0; i < v.Len(); i++ {
+		if err := m.encodeValue(v.Index(i)); err != nil {
+			return err
+		}
+	}
+
+	return m.w.EndSexp()
+}
+
 // EncodeArray encodes an array to the output writer as an Ion list.
 func (m *Encoder) encodeArray(v reflect.Value) error {
 	m.w.BeginList()
@@ -273,32 +405,45 @@ func (m *Encoder) encodeStruct(v reflect.Value) error {
 	if t == decimalType {
 		return m.encodeDecimal(v)
 	}
+	if t == timestampType {
+		return m.encodeTimestamp(v)
+	}
+	if t == bigIntType {
+		return m.encodeBigInt(v)
+	}
 
 	fields := fieldsFor(v.Type())
 
+	for _, i := range fields.annotations {
+		if fv, ok := fieldValue(v, &fields.list[i]); ok {
+			for j := 0; j < fv.Len(); j++ {
+				m.w.Annotation(fv.Index(j).String())
+			}
+		}
+	}
+
 	m.w.BeginStruct()
 
-FieldLoop:
-	for i := range fields {
-		f := &fields[i]
+	for i := range fields.list {
+		f := &fields.list[i]
+		if f.annotations {
+			continue
+		}
 
-		fv := v
-		for _, i := range f.path {
-			if fv.Kind() == reflect.Ptr {
-				if fv.IsNil() {
-					continue FieldLoop
-				}
-				fv = fv.Elem()
-			}
-			fv = fv.Field(i)
+		fv, ok := fieldValue(v, f)
+		if !ok {
+			continue
 		}
 
 		if f.omitEmpty && emptyValue(fv) {
 			continue
 		}
+		if f.omitZero && zeroValue(fv) {
+			continue
+		}
 
 		m.w.FieldName(f.name)
-		if err := m.encodeValue(fv); err != nil {
+		if err := m.encodeField(fv, f); err != nil {
 			return err
 		}
 	}
@@ -306,6 +451,146 @@ FieldLoop:
 	return m.w.EndStruct()
 }
 
+// StaticAnnotations returns the annotations of the given value's type, if it is Annotated.
+func staticAnnotations(v reflect.Value) []string {
+	if i, ok := implementer(v, annotatedType); ok {
+		return i.(Annotated).IonAnnotations()
+	}
+	return nil
+}
+
+// FieldValue returns the value of the given field of a struct, or false if the
+// field is inside a nil embedded pointer.
+func fieldValue(v reflect.Value, f *field) (reflect.Value, bool) {
+	for _, i := range f.path {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v, true
+}
+
+// EncodeField encodes the value of a struct field, applying any options from the
+// field's tag.
+func (m *Encoder) encodeField(v reflect.Value, f *field) error {
+	if !f.hasEncodingOpts() {
+		return m.encodeValue(v)
+	}
+	if _, ok := implementer(v, marshalerType); ok {
+		// Custom marshalers know best.
+		return m.encodeValue(v)
+	}
+
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			return m.w.WriteNull()
+		}
+		return m.encodeField(v.Elem(), f)
+
+	case reflect.Bool:
+		if f.str {
+			return m.w.WriteString(strconv.FormatBool(v.Bool()))
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if f.decimal {
+			return m.w.WriteDecimal(NewDecimalInt(v.Int()))
+		}
+		if f.str {
+			return m.w.WriteString(strconv.FormatInt(v.Int(), 10))
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if f.decimal {
+			return m.w.WriteDecimal(NewDecimal(new(big.Int).SetUint64(v.Uint()), 0))
+		}
+		if f.str {
+			return m.w.WriteString(strconv.FormatUint(v.Uint(), 10))
+		}
+
+	case reflect.Float32, reflect.Float64:
+		bits := v.Type().Bits()
+		if f.decimal {
+			d, err := decimalFromFloat(v.Float(), bits)
+			if err != nil {
+				return err
+			}
+			return m.w.WriteDecimal(d)
+		}
+		if f.str {
+			return m.w.WriteString(strconv.FormatFloat(v.Float(), 'g', -1, bits))
+		}
+
+	case reflect.String:
+		switch {
+		case f.symbol:
+			return m.w.WriteSymbol(v.String())
+		case f.lob == BlobType:
+			return m.w.WriteBlob([]byte(v.String()))
+		case f.lob == ClobType:
+			return m.w.WriteClob([]byte(v.String()))
+		}
+
+	case reflect.Struct:
+		if v.Type() == timeType && f.precision != 0 {
+			ts := timestampWithPrecision(v.Interface().(time.Time), f.precision, f.fraction)
+			return m.w.WriteIonTimestamp(ts)
+		}
+
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if f.lob == ClobType {
+				if v.Kind() == reflect.Slice && v.IsNil() {
+					return m.w.WriteNull()
+				}
+				b := make([]byte, v.Len())
+				reflect.Copy(reflect.ValueOf(b), v)
+				return m.w.WriteClob(b)
+			}
+			break
+		}
+
+		// Apply the field's options to each element of the list.
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return m.w.WriteNull()
+		}
+		m.w.BeginList()
+		for i := 0; i < v.Len(); i++ {
+			if err := m.encodeField(v.Index(i), f); err != nil {
+				return err
+			}
+		}
+		return m.w.EndList()
+	}
+
+	return m.encodeValue(v)
+}
+
+// TimestampWithPrecision converts a time.Time to a Timestamp with the given precision
+// and (for fractional-second precision) number of fractional digits.
+func timestampWithPrecision(t time.Time, p TimestampPrecision, digits int) *Timestamp {
+	if p != TimestampPrecisionFraction {
+		return NewTimestamp(t, p)
+	}
+
+	nsec := int64(t.Nanosecond())
+	for i := digits; i < 9; i++ {
+		nsec /= 10
+	}
+
+	ts, err := NewTimestamp(t, TimestampPrecisionSecond).WithFraction(NewDecimal(big.NewInt(nsec), int32(-digits)))
+	if err != nil {
+		// Should never happen, since the fraction is always in [0, 1).
+		panic(err)
+	}
+	return ts
+}
+
 // EncodeTime encodes a time.Time to the output writer as an Ion timestamp.
 func (m *Encoder) encodeTime(v reflect.Value) error {
 	t := v.Interface().(time.Time)
@@ -314,8 +599,97 @@ func (m *Encoder) encodeTime(v reflect.Value) error {
 
 // EncodeDecimal encodes an ion.Decimal to the output writer as an Ion decimal.
 func (m *Encoder) encodeDecimal(v reflect.Value) error {
-	d := v.Addr().Interface().(*Decimal)
-	return m.w.WriteDecimal(d)
+	d := v.Interface().(Decimal)
+	return m.w.WriteDecimal(&d)
+}
+
+// EncodeTimestamp encodes an ion.Timestamp to the output writer as an Ion timestamp.
+func (m *Encoder) encodeTimestamp(v reflect.Value) error {
+	ts := v.Interface().(Timestamp)
+	return m.w.WriteIonTimestamp(&ts)
+}
+
+// EncodeBigInt encodes a big.Int to the output writer as an Ion int.
+func (m *Encoder) encodeBigInt(v reflect.Value) error {
+	i := v.Interface().(big.Int)
+	return m.w.WriteBigInt(&i)
+}
+
+// Implementer returns the given value as an instance of the given interface type
+// if it (or, failing that, a pointer to it) implements that interface. Nil pointers
+// and interfaces are not considered implementers, so that they get encoded as nulls.
+// Pointer methods of read-only interfaces are called on a copy when the value isn't
+// addressable; the unmarshaler interfaces aren't, since the decoded data would be lost.
+func implementer(v reflect.Value, it reflect.Type) (interface{}, bool) {
+	t := v.Type()
+	if t.Kind() == reflect.Interface || !v.CanInterface() {
+		return nil, false
+	}
+
+	if t.Implements(it) {
+		if t.Kind() == reflect.Ptr && v.IsNil() {
+			return nil, false
+		}
+		return v.Interface(), true
+	}
+
+	if t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(it) {
+		if v.CanAddr() {
+			return v.Addr().Interface(), true
+		}
+		if it == unmarshalerType || it == textUnmarshalerType {
+			return nil, false
+		}
+		// Make an addressable copy so we can call the pointer method.
+		pv := reflect.New(t)
+		pv.Elem().Set(v)
+		return pv.Interface(), true
+	}
+
+	return nil, false
+}
+
+// IsNativeType returns true if values of the given type (or the type it points to)
+// are encoded to and decoded from a native Ion type, even if they happen to
+// implement encoding.TextMarshaler or encoding.TextUnmarshaler.
+func isNativeType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t == timeType || t == decimalType || t == timestampType || t == bigIntType
+}
+
+// ZeroValue returns true if the given value is the zero value for its type. Types with
+// an IsZero method (like time.Time) get to decide for themselves.
+func zeroValue(v reflect.Value) bool {
+	if i, ok := implementer(v, isZeroerType); ok {
+		return i.(isZeroer).IsZero()
+	}
+
+	switch v.Kind() {
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !zeroValue(v.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !zeroValue(v.Field(i)) {
+				return false
+			}
+		}
+		return true
+	}
+	return emptyValue(v)
+}
+
+// An isZeroer is a type that knows whether it's the zero value.
+type isZeroer interface {
+	IsZero() bool
 }
 
 // EmptyValue returns true if the given value is the empty value for its type.
@@ -2,7 +2,9 @@ package ion
 
 import (
 	"bytes"
+	"fmt"
 	"math"
+	"math/big"
 	"testing"
 	"time"
 )
@@ -35,6 +37,10 @@ func TestMarshalText(t *testing.T) {
 	test(math.NaN(), "nan")
 
 	test(MustParseDecimal("1.20"), "1.20")
+	test(*MustParseDecimal("1.20"), "1.20")
+	test(struct{ D Decimal }{*MustParseDecimal("-0.5")}, "{D:-5d-1}")
+	test(struct{ D Decimal }{}, "{D:0.}")
+	test(big.NewInt(42), "42")
 	test(time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC), "2010-01-01T00:00:00Z")
 
 	test("hello\tworld", "\"hello\\tworld\"")
@@ -64,6 +70,21 @@ func TestMarshalText(t *testing.T) {
 	test(struct{ V []byte }{[]byte{4, 2}}, "{V:{{BAI=}}}")
 
 	test(struct{ V [2]byte }{[2]byte{4, 2}}, "{V:[4,2]}")
+
+	test(Symbol("hello world"), "'hello world'")
+	test(Sexp{Symbol("+"), 1, "two"}, "('+' 1 \"two\")")
+	test(struct{ V Sexp }{}, "{V:null}")
+	test(Clob("hello"), "{{\"hello\"}}")
+	test(struct{ V Clob }{}, "{V:null}")
+
+	test(Number("-42"), "-42")
+	test(Number("0x1F"), "31")
+	test(Number("18446744073709551616"), "18446744073709551616")
+	test(Number("4.20"), "4.20")
+	test(Number("-0d3"), "-0d3")
+	test(Number("4.2e1"), "4.2e+1")
+	test(Number("nan"), "nan")
+	test(Number(""), "null")
 }
 
 func TestMarshalBinary(t *testing.T) {
@@ -87,6 +108,10 @@ func TestMarshalBinary(t *testing.T) {
 		0x8A, 0x21, 0x2A,
 		0x8B, 0x20,
 	})
+	test(float32(1.5), "1.5e0 (float32)", []byte{0xE0, 0x01, 0x00, 0xEA, 0x44, 0x3F, 0xC0, 0x00, 0x00})
+	test(float64(1.5), "1.5e0", []byte{0xE0, 0x01, 0x00, 0xEA, 0x44, 0x3F, 0xC0, 0x00, 0x00})
+	test(float64(0.1), "1e-1", []byte{0xE0, 0x01, 0x00, 0xEA, 0x48, 0x3F, 0xB9, 0x99, 0x99, 0x99, 0x99, 0x99, 0x9A})
+	test(Decimal{}, "0.", []byte{0xE0, 0x01, 0x00, 0xEA, 0x50})
 }
 
 func TestMarshalBinaryLST(t *testing.T) {
@@ -160,3 +185,269 @@ func TestMarshalNestedStructs(t *testing.T) {
 		t.Errorf("expected %v, got %v", eval, string(val))
 	}
 }
+
+// A money marshals itself as an (amount currency) sexp.
+type money struct {
+	Amount   int
+	Currency string
+}
+
+func (m *money) MarshalIon(w Writer) error {
+	w.BeginSexp()
+	w.WriteInt(int64(m.Amount))
+	w.WriteSymbol(m.Currency)
+	return w.EndSexp()
+}
+
+func (m *money) UnmarshalIon(r Reader) error {
+	if r.Type() != SexpType {
+		return fmt.Errorf("expected a sexp, got %v", r.Type())
+	}
+	if err := r.StepIn(); err != nil {
+		return err
+	}
+
+	r.Next()
+	amt, err := r.IntValue()
+	if err != nil {
+		return err
+	}
+	r.Next()
+	cur, err := r.StringValue()
+	if err != nil {
+		return err
+	}
+
+	m.Amount = amt
+	m.Currency = cur
+	return r.StepOut()
+}
+
+// A color marshals itself as text.
+type color int
+
+var colorNames = []string{"red", "green", "blue"}
+
+func (c color) MarshalText() ([]byte, error) {
+	return []byte(colorNames[c]), nil
+}
+
+func (c *color) UnmarshalText(text []byte) error {
+	for i, name := range colorNames {
+		if name == string(text) {
+			*c = color(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown color %v", string(text))
+}
+
+func TestMarshalMarshalers(t *testing.T) {
+	test := func(v interface{}, eval string) {
+		t.Run(eval, func(t *testing.T) {
+			val, err := MarshalText(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(val) != eval {
+				t.Errorf("expected '%v', got '%v'", eval, string(val))
+			}
+		})
+	}
+
+	test(&money{42, "USD"}, "(42 USD)")
+	test(money{42, "USD"}, "(42 USD)")
+	test(struct{ Price money }{money{1, "EUR"}}, "{Price:(1 EUR)}")
+	test(struct{ Price *money }{}, "{Price:null}")
+	test([]money{{1, "EUR"}, {2, "GBP"}}, "[(1 EUR),(2 GBP)]")
+
+	test(color(1), "\"green\"")
+	test(struct{ C color }{2}, "{C:\"blue\"}")
+	test(map[color]int{0: 1, 2: 3}, "{blue:3,red:1}")
+}
+
+func TestMarshalMapKeys(t *testing.T) {
+	test := func(v interface{}, eval string) {
+		t.Run(eval, func(t *testing.T) {
+			val, err := MarshalText(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(val) != eval {
+				t.Errorf("expected '%v', got '%v'", eval, string(val))
+			}
+		})
+	}
+
+	type name string
+
+	test(map[name]int{"b": 2, "a": 1}, "{a:1,b:2}")
+	test(map[int]string{-1: "a", 10: "b"}, "{'-1':\"a\",'10':\"b\"}")
+	test(map[uint8]bool{255: true}, "{'255':true}")
+	test(map[bool]int{true: 1, false: 0}, "{'false':0,'true':1}")
+}
+
+func TestMarshalUnsupportedMapKey(t *testing.T) {
+	if _, err := MarshalText(map[struct{}]int{{}: 1}); err == nil {
+		t.Error("expected an error marshaling a map with struct keys")
+	}
+}
+
+func TestMarshalIonTags(t *testing.T) {
+	test := func(v interface{}, eval string) {
+		t.Run(eval, func(t *testing.T) {
+			val, err := MarshalText(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(val) != eval {
+				t.Errorf("expected '%v', got '%v'", eval, string(val))
+			}
+		})
+	}
+
+	test(struct {
+		A int `ion:"a" json:"b"`
+		B int `ion:"-" json:"bb"`
+		C int `ion:"c,omitempty" json:"-"`
+		D int `json:"d"`
+	}{1, 2, 0, 4}, "{a:1,d:4}")
+
+	test(struct {
+		S  string   `ion:"s,symbol"`
+		SS []string `ion:"ss,symbol"`
+		PS *string  `ion:"ps,symbol"`
+	}{"foo", []string{"bar", "baz"}, nil}, "{s:foo,ss:[bar,baz],ps:null}")
+
+	test(struct {
+		F float64 `ion:"f,decimal"`
+		I int     `ion:"i,decimal"`
+		U uint8   `ion:"u,decimal"`
+	}{1.5, 42, 7}, "{f:1.5,i:42.,u:7.}")
+
+	test(struct {
+		B  string `ion:"b,blob"`
+		C  string `ion:"c,clob"`
+		CB []byte `ion:"cb,clob"`
+	}{"hi", "hi", []byte("hi")}, "{b:{{aGk=}},c:{{\"hi\"}},cb:{{\"hi\"}}}")
+
+	tt := time.Date(2020, 5, 1, 12, 34, 56, 789123456, time.UTC)
+	test(struct {
+		D  time.Time `ion:"d,timestamp=day"`
+		M  time.Time `ion:"m,timestamp=minute"`
+		MS time.Time `ion:"ms,timestamp=millisecond"`
+		NS time.Time `ion:"ns,timestamp=nanosecond"`
+	}{tt, tt, tt, tt}, "{d:2020-05-01,m:2020-05-01T12:34Z,ms:2020-05-01T12:34:56.789Z,ns:2020-05-01T12:34:56.789123456Z}")
+	test(struct {
+		H time.Time `ion:"h,timestamp=hours"`
+	}{tt}, "{h:2020-05-01T12:34:56.789123456Z}")
+
+	test(struct {
+		B bool    `ion:"b,string"`
+		I int     `ion:"i,string"`
+		F float32 `ion:"f,string"`
+	}{true, -42, 1.5}, "{b:\"true\",i:\"-42\",f:\"1.5\"}")
+
+	type inner struct{ A int }
+	test(struct {
+		E []int     `ion:"e,omitempty"`
+		Z []int     `ion:"z,omitzero"`
+		T time.Time `ion:"t,omitzero"`
+		S inner     `ion:"s,omitzero"`
+		N inner     `ion:"n,omitzero"`
+	}{[]int{}, []int{}, time.Time{}, inner{}, inner{1}}, "{z:[],n:{A:1}}")
+}
+
+// An order is always annotated with "order".
+type order struct {
+	ID   int      `ion:"id"`
+	Tags []string `ion:",annotations"`
+}
+
+func (o order) IonAnnotations() []string {
+	return []string{"order"}
+}
+
+func TestMarshalAnnotations(t *testing.T) {
+	test := func(v interface{}, eval string) {
+		t.Run(eval, func(t *testing.T) {
+			val, err := MarshalText(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(val) != eval {
+				t.Errorf("expected '%v', got '%v'", eval, string(val))
+			}
+		})
+	}
+
+	test(order{ID: 1}, "order::{id:1}")
+	test(&order{ID: 1, Tags: []string{"rush", "gift"}}, "order::rush::gift::{id:1}")
+	test([]order{{ID: 1}}, "[order::{id:1}]")
+	test(struct {
+		A  []string `ion:"as,annotations"`
+		As int      `ion:"as"`
+	}{[]string{"a"}, 1}, "a::{as:1}")
+}
+
+type benchItem struct {
+	SKU      string   `ion:"sku"`
+	Quantity int      `ion:"quantity"`
+	Price    float64  `ion:"price"`
+	Tags     []string `ion:"tags,omitempty"`
+}
+
+type benchOrder struct {
+	ID       int64       `ion:"id"`
+	Customer string      `ion:"customer"`
+	Email    string      `ion:"email,omitempty"`
+	Express  bool        `ion:"express"`
+	Notes    string      `ion:"notes,omitempty"`
+	Items    []benchItem `ion:"items"`
+	Total    float64     `ion:"total"`
+	Currency string      `ion:"currency,symbol"`
+}
+
+var benchOrderValue = benchOrder{
+	ID:       1234567,
+	Customer: "Jane Doe",
+	Email:    "jane@example.com",
+	Express:  true,
+	Items: []benchItem{
+		{"A-1", 2, 9.99, []string{"sale"}},
+		{"B-22", 1, 24.5, nil},
+		{"C-333", 5, 1.25, []string{"bulk", "sale"}},
+	},
+	Total:    50.73,
+	Currency: "USD",
+}
+
+func BenchmarkEncodeText(b *testing.B) {
+	buf := bytes.Buffer{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		e := NewTextEncoder(&buf)
+		if err := e.Encode(benchOrderValue); err != nil {
+			b.Fatal(err)
+		}
+		if err := e.Finish(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncodeBinary(b *testing.B) {
+	buf := bytes.Buffer{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		e := NewBinaryEncoder(&buf)
+		if err := e.Encode(benchOrderValue); err != nil {
+			b.Fatal(err)
+		}
+		if err := e.Finish(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
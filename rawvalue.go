@@ -0,0 +1,69 @@
+package ion
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// A RawValue is a single encoded Ion value, including its annotations. It can be
+// used to delay decoding part of a larger value, or to pass it through untouched.
+//
+// The Decoder fills a RawValue with a self-contained binary encoding of the value
+// it is positioned on: a binary version marker and local symbol table, then the
+// value itself. Unlike other Unmarshalers, a RawValue keeps null values, along with
+// their types and annotations. The Encoder writes a RawValue's value back out (see
+// MarshalIon); a nil RawValue is written as null.
+//
+// 	type Envelope struct {
+// 		Route   string
+// 		Payload RawValue
+// 	}
+//
+// 	env := Envelope{}
+// 	err := Unmarshal(data, &env)
+// 	// ...
+// 	err = Unmarshal(env.Payload, &order)
+type RawValue []byte
+
+// MarshalIon writes the encoded value to the given Writer. A container is copied
+// byte-for-byte if w is a binary Writer with a fixed local symbol table that gives
+// every symbol the value uses the same ID; anything else is read back and re-encoded.
+func (v RawValue) MarshalIon(w Writer) error {
+	if len(v) == 0 {
+		return w.WriteNull()
+	}
+
+	r := NewReaderBytes(v)
+	if !r.Next() {
+		if err := r.Err(); err != nil {
+			return err
+		}
+		return fmt.Errorf("ion: RawValue contains no value")
+	}
+
+	if err := w.WriteValue(r); err != nil {
+		return err
+	}
+
+	if r.Next() {
+		return fmt.Errorf("ion: RawValue contains more than one value")
+	}
+	return r.Err()
+}
+
+// UnmarshalIon encodes the value the given Reader is positioned on, replacing
+// the contents of v.
+func (v *RawValue) UnmarshalIon(r Reader) error {
+	buf := bytes.Buffer{}
+
+	w := NewBinaryWriter(&buf)
+	if err := w.WriteValue(r); err != nil {
+		return err
+	}
+	if err := w.Finish(); err != nil {
+		return err
+	}
+
+	*v = buf.Bytes()
+	return nil
+}
@@ -0,0 +1,130 @@
+package ion
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+type rawEnvelope struct {
+	Route   string   `ion:"route"`
+	Payload RawValue `ion:"payload"`
+	Tail    int      `ion:"tail"`
+}
+
+func TestDecodeRawValue(t *testing.T) {
+	test := func(payload string) {
+		t.Run(payload, func(t *testing.T) {
+			env := rawEnvelope{}
+			err := UnmarshalStr("{route:\"orders\", payload:"+payload+", tail:1}", &env)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if env.Route != "orders" || env.Tail != 1 {
+				t.Errorf("expected route=orders, tail=1, got %+v", env)
+			}
+			if !bytes.HasPrefix(env.Payload, []byte{0xE0, 0x01, 0x00, 0xEA}) {
+				t.Errorf("expected binary Ion, got %X", []byte(env.Payload))
+			}
+
+			vals, err := ReadValues(NewReaderBytes(env.Payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			evals := readValuesStr(t, payload)
+			if len(vals) != 1 || !vals[0].Equal(evals[0]) {
+				t.Errorf("expected %v, got %v", evals, vals)
+			}
+		})
+	}
+
+	test("order::{id:17, items:[a, (b c)], 'x y':{{aGk=}}}")
+	test("a::b::null.int")
+	test("null")
+	test("$ion_symbol_table")
+}
+
+func TestDecodeRawValuePayload(t *testing.T) {
+	type order struct {
+		ID    int      `ion:"id"`
+		Items []string `ion:"items"`
+	}
+
+	env := rawEnvelope{}
+	if err := UnmarshalStr("{route:r, payload:{id:17, items:[a, b]}}", &env); err != nil {
+		t.Fatal(err)
+	}
+
+	o := order{}
+	if err := Unmarshal(env.Payload, &o); err != nil {
+		t.Fatal(err)
+	}
+	if o.ID != 17 || len(o.Items) != 2 || o.Items[0] != "a" || o.Items[1] != "b" {
+		t.Errorf("unexpected order %+v", o)
+	}
+}
+
+func TestEncodeRawValue(t *testing.T) {
+	test := func(env rawEnvelope, expected string) {
+		t.Run(expected, func(t *testing.T) {
+			actual, err := MarshalText(env)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(actual) != expected {
+				t.Errorf("expected %v, got %v", expected, string(actual))
+			}
+		})
+	}
+
+	binary := rawEnvelope{}
+	if err := UnmarshalStr("{route:r, payload:a::{b:[c, 1]}, tail:2}", &binary); err != nil {
+		t.Fatal(err)
+	}
+
+	test(binary, "{route:\"r\",payload:a::{b:[c,1]},tail:2}")
+	test(rawEnvelope{"r", RawValue("(x 1.5)"), 0}, "{route:\"r\",payload:(x 1.5),tail:0}")
+	test(rawEnvelope{"r", nil, 0}, "{route:\"r\",payload:null,tail:0}")
+
+	bad := func(raw RawValue) {
+		t.Run(string(raw), func(t *testing.T) {
+			if _, err := MarshalText(rawEnvelope{"r", raw, 0}); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	bad(RawValue("1 2"))
+	bad(RawValue("/* nothing */"))
+	bad(RawValue("[1,"))
+}
+
+func TestEncodeRawValueCopy(t *testing.T) {
+	// A list padded with a NOP, which re-encoding would drop.
+	raw := RawValue{0xE0, 0x01, 0x00, 0xEA, 0xB3, 0x21, 0x01, 0x00}
+
+	test := func(name string, newWriter func(out io.Writer) Writer, eval []byte) {
+		t.Run(name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			w := newWriter(&buf)
+			if err := MarshalTo(w, raw); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Finish(); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasSuffix(buf.Bytes(), eval) {
+				t.Errorf("expected %v to end with %v", fmtbytes(buf.Bytes()), fmtbytes(eval))
+			}
+		})
+	}
+
+	test("copied", func(out io.Writer) Writer {
+		return NewBinaryWriterLST(out, NewLocalSymbolTable(nil, nil))
+	}, []byte{0xB3, 0x21, 0x01, 0x00})
+
+	test("re-encoded", func(out io.Writer) Writer {
+		return NewBinaryWriter(out)
+	}, []byte{0xB2, 0x21, 0x01})
+}
@@ -3,6 +3,7 @@ package ion
 import (
 	"bufio"
 	"bytes"
+	"fmt"
 	"io"
 	"math"
 	"math/big"
@@ -61,8 +62,9 @@ import (
 type Reader interface {
 
 	// SymbolTable returns the current symbol table, or nil if there isn't one.
-	// Text Readers do not, generally speaking, have an associated symbol table.
-	// Binary Readers do.
+	// Both text and binary Readers start with the system symbol table and install
+	// any local symbol tables ($ion_symbol_table structs) found in the stream,
+	// resolving their imports using the Reader's Catalog.
 	SymbolTable() SymbolTable
 
 	// Next advances the Reader to the next position in the current value stream.
@@ -87,10 +89,21 @@ type Reader interface {
 	// name.
 	FieldName() string
 
+	// FieldNameSymbol returns the field name associated with the current value as a
+	// SymbolToken, which distinguishes a field name with unknown text from one whose
+	// text merely looks like a symbol ID. It returns nil if there is no current value
+	// or the current value has no field name.
+	FieldNameSymbol() *SymbolToken
+
 	// Annotations returns the set of annotations associated with the current value.
 	// It returns nil if there is no current value or the current value has no annotations.
 	Annotations() []string
 
+	// AnnotationSymbols returns the set of annotations associated with the current value
+	// as SymbolTokens. It returns nil if there is no current value or the current value
+	// has no annotations.
+	AnnotationSymbols() []SymbolToken
+
 	// StepIn steps in to the current value if it is a container. It returns an error if there
 	// is no current value or if the value is not a container. On success, the Reader is
 	// positioned before the first value in the container.
@@ -137,14 +150,25 @@ type Reader interface {
 	// makes sense). It returns an error if the current value is not an Ion decimal.
 	DecimalValue() (*Decimal, error)
 
-	// TimeValue returns the current value as a timestamp (if that makes sense). It returns
-	// an error if the current value is not an Ion timestamp.
+	// TimeValue returns the current value as a time.Time (if that makes sense). It returns
+	// an error if the current value is not an Ion timestamp. Precision beyond nanoseconds
+	// and the distinction between known and unknown offsets are lost; use TimestampValue
+	// to preserve them.
 	TimeValue() (time.Time, error)
 
+	// TimestampValue returns the current value as a Timestamp (if that makes sense),
+	// preserving its precision and offset. It returns an error if the current value is
+	// not an Ion timestamp.
+	TimestampValue() (*Timestamp, error)
+
 	// StringValue returns the current value as a string (if that makes sense). It returns
 	// an error if the current value is not an Ion symbol or an Ion string.
 	StringValue() (string, error)
 
+	// SymbolValue returns the current value as a SymbolToken (if that makes sense). It
+	// returns an error if the current value is not an Ion symbol.
+	SymbolValue() (*SymbolToken, error)
+
 	// ByteValue returns the current value as a byte slice (if that makes sense). It returns
 	// an error if the current value is not an Ion clob or an Ion blob.
 	ByteValue() ([]byte, error)
@@ -166,7 +190,8 @@ func NewReaderBytes(in []byte) Reader {
 	return NewReader(bytes.NewReader(in))
 }
 
-// NewReaderCat creates a new reader with the given catalog.
+// NewReaderCat creates a new reader with the given catalog, which is used to resolve
+// shared symbol tables imported by the stream's local symbol tables.
 func NewReaderCat(in io.Reader, cat Catalog) Reader {
 	br := bufio.NewReader(in)
 
@@ -175,7 +200,193 @@ func NewReaderCat(in io.Reader, cat Catalog) Reader {
 		return newBinaryReaderBuf(br, cat)
 	}
 
-	return newTextReaderBuf(br)
+	return newTextReaderBuf(br, cat)
+}
+
+// IsIonSymbolTable returns true if the given annotations mark a local symbol table.
+func isIonSymbolTable(as []string) bool {
+	return len(as) > 0 && as[0] == "$ion_symbol_table"
+}
+
+// ReadLocalSymbolTable reads a local symbol table from the given Reader, which
+// must be positioned on a $ion_symbol_table struct. Imported shared symbol tables
+// are resolved using the given Catalog, which may be nil.
+func readLocalSymbolTable(r Reader, cat Catalog) (SymbolTable, error) {
+	if r.IsNull() {
+		return V1SystemSymbolTable, nil
+	}
+
+	if err := r.StepIn(); err != nil {
+		return nil, err
+	}
+
+	imps := []SharedSymbolTable{}
+	syms := []string{}
+
+	for r.Next() {
+		var err error
+		switch r.FieldName() {
+		case "imports":
+			imps, err = readImports(r, cat)
+		case "symbols":
+			syms, err = readSymbols(r)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := r.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.StepOut(); err != nil {
+		return nil, err
+	}
+
+	return NewLocalSymbolTable(imps, syms), nil
+}
+
+// ReadImports reads the imports field of a local symbol table.
+func readImports(r Reader, cat Catalog) ([]SharedSymbolTable, error) {
+	if r.Type() == SymbolType && !r.IsNull() {
+		if sym, _ := r.StringValue(); sym == "$ion_symbol_table" {
+			// Special case that imports the current local symbol table.
+			cur := r.SymbolTable()
+			if cur == nil || cur == V1SystemSymbolTable {
+				return nil, nil
+			}
+
+			imps := cur.Imports()
+			lsst := NewSharedSymbolTable("", 0, cur.Symbols())
+			return append(imps, lsst), nil
+		}
+	}
+
+	if r.Type() != ListType || r.IsNull() {
+		return nil, nil
+	}
+	if err := r.StepIn(); err != nil {
+		return nil, err
+	}
+
+	imps := []SharedSymbolTable{}
+	for r.Next() {
+		imp, err := readImport(r, cat)
+		if err != nil {
+			return nil, err
+		}
+		if imp != nil {
+			imps = append(imps, imp)
+		}
+	}
+
+	err := r.StepOut()
+	return imps, err
+}
+
+// ReadImport reads an import definition.
+func readImport(r Reader, cat Catalog) (SharedSymbolTable, error) {
+	if r.Type() != StructType || r.IsNull() {
+		return nil, nil
+	}
+	if err := r.StepIn(); err != nil {
+		return nil, err
+	}
+
+	name := ""
+	version := 0
+	maxID := uint64(0)
+
+	for r.Next() {
+		var err error
+		switch r.FieldName() {
+		case "name":
+			if r.Type() == StringType {
+				name, err = r.StringValue()
+			}
+		case "version":
+			if r.Type() == IntType {
+				version, err = r.IntValue()
+			}
+		case "max_id":
+			if r.Type() == IntType {
+				var i int64
+				i, err = r.Int64Value()
+				if i < 0 {
+					i = 0
+				}
+				maxID = uint64(i)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.StepOut(); err != nil {
+		return nil, err
+	}
+
+	if name == "" || name == "$ion" {
+		return nil, nil
+	}
+	if version < 1 {
+		version = 1
+	}
+
+	var imp SharedSymbolTable
+	if cat != nil {
+		imp = cat.FindExact(name, version)
+		if imp == nil {
+			imp = cat.FindLatest(name)
+		}
+	}
+
+	if maxID == 0 {
+		if imp == nil || version != imp.Version() {
+			return nil, fmt.Errorf("ion: import of shared table %v/%v lacks a valid max_id, but an exact "+
+				"match was not found in the catalog", name, version)
+		}
+		maxID = imp.MaxID()
+	}
+
+	if imp == nil {
+		imp = &bogusSST{
+			name:    name,
+			version: version,
+			maxID:   maxID,
+		}
+	} else {
+		imp = imp.Adjust(maxID)
+	}
+
+	return imp, nil
+}
+
+// ReadSymbols reads the symbols from a symbol table.
+func readSymbols(r Reader) ([]string, error) {
+	if r.Type() != ListType {
+		return nil, nil
+	}
+	if err := r.StepIn(); err != nil {
+		return nil, err
+	}
+
+	syms := []string{}
+	for r.Next() {
+		if r.Type() == StringType {
+			sym, err := r.StringValue()
+			if err != nil {
+				return nil, err
+			}
+			syms = append(syms, sym)
+		} else {
+			syms = append(syms, "")
+		}
+	}
+
+	err := r.StepOut()
+	return syms, err
 }
 
 // A reader holds common implementation stuff to both the text and binary readers.
@@ -188,6 +399,33 @@ type reader struct {
 	annotations []string
 	valueType   Type
 	value       interface{}
+
+	// Symbol tokens for the field name, annotations, and (symbol) value, if the
+	// underlying reader knows more about them than their text.
+	fieldNameSym   *SymbolToken
+	annotationSyms []SymbolToken
+	symbolValue    *SymbolToken
+
+	// Where the current value is, for spans and error messages: its start and end
+	// offsets in the input, the containers we've stepped in to, and how many values
+	// we've read from the innermost one.
+	offset uint64
+	end    uint64
+	path   []pathElem
+	count  int
+
+	// Whether we've been seeked to a single value.
+	hoisted bool
+
+	// The options of the Decoder calling an Unmarshaler with this reader, if any.
+	opts DecoderOpts
+}
+
+// A pathElem is a container on the path to the current value.
+type pathElem struct {
+	ctx       ctx    // The context the container is in.
+	fieldName string // Its field name, if it's in a struct.
+	count     int    // The number of values read up to and including it.
 }
 
 // Err returns the current error.
@@ -210,11 +448,36 @@ func (r *reader) FieldName() string {
 	return r.fieldName
 }
 
+// FieldNameSymbol returns the current value's field name as a SymbolToken.
+func (r *reader) FieldNameSymbol() *SymbolToken {
+	if r.fieldNameSym != nil {
+		return r.fieldNameSym
+	}
+	if r.fieldName == "" {
+		return nil
+	}
+	tok := NewSymbolToken(r.fieldName)
+	return &tok
+}
+
 // Annotations returns the current value's annotations.
 func (r *reader) Annotations() []string {
 	return r.annotations
 }
 
+// AnnotationSymbols returns the current value's annotations as SymbolTokens.
+func (r *reader) AnnotationSymbols() []SymbolToken {
+	if r.annotationSyms != nil || r.annotations == nil {
+		return r.annotationSyms
+	}
+
+	toks := make([]SymbolToken, len(r.annotations))
+	for i, a := range r.annotations {
+		toks[i] = NewSymbolToken(a)
+	}
+	return toks
+}
+
 // BoolValue returns the current value as a bool.
 func (r *reader) BoolValue() (bool, error) {
 	if r.valueType != BoolType {
@@ -354,7 +617,18 @@ func (r *reader) TimeValue() (time.Time, error) {
 	if r.value == nil {
 		return time.Time{}, nil
 	}
-	return r.value.(time.Time), nil
+	return r.value.(*Timestamp).Time(), nil
+}
+
+// TimestampValue returns the current value as a Timestamp.
+func (r *reader) TimestampValue() (*Timestamp, error) {
+	if r.valueType != TimestampType {
+		return nil, &UsageError{"Reader.TimestampValue", "value is not a timestamp"}
+	}
+	if r.value == nil {
+		return nil, nil
+	}
+	return r.value.(*Timestamp), nil
 }
 
 // StringValue returns the current value as a string.
@@ -368,6 +642,21 @@ func (r *reader) StringValue() (string, error) {
 	return r.value.(string), nil
 }
 
+// SymbolValue returns the current value as a SymbolToken.
+func (r *reader) SymbolValue() (*SymbolToken, error) {
+	if r.valueType != SymbolType {
+		return nil, &UsageError{"Reader.SymbolValue", "value is not a symbol"}
+	}
+	if r.value == nil {
+		return nil, nil
+	}
+	if r.symbolValue != nil {
+		return r.symbolValue, nil
+	}
+	tok := NewSymbolToken(r.value.(string))
+	return &tok, nil
+}
+
 // ByteValue returns the current value as a byte slice.
 func (r *reader) ByteValue() ([]byte, error) {
 	if r.valueType != BlobType && r.valueType != ClobType {
@@ -379,10 +668,82 @@ func (r *reader) ByteValue() ([]byte, error) {
 	return r.value.([]byte), nil
 }
 
+// PushPath records that we're stepping in to the current value.
+func (r *reader) pushPath() {
+	r.path = append(r.path, pathElem{r.ctx.peek(), r.fieldName, r.count})
+	r.count = 0
+}
+
+// PopPath records that we're stepping out of the current container.
+func (r *reader) popPath() {
+	e := r.path[len(r.path)-1]
+	r.path = r.path[:len(r.path)-1]
+	r.count = e.count
+}
+
+// ValueOffset returns the offset of the current value in the input.
+func (r *reader) valueOffset() uint64 {
+	return r.offset
+}
+
+// ValuePath returns the path to the current value.
+func (r *reader) valuePath() string {
+	return r.pathTo(r.count - 1)
+}
+
+// ErrPath returns the path to the value currently being read, which is the
+// one after the last value successfully read, for use in error messages.
+func (r *reader) errPath() string {
+	return r.pathTo(r.count)
+}
+
+// PathTo returns the path to the value at the given index in the current
+// container, e.g. orders[17].items[2].price.
+func (r *reader) pathTo(index int) string {
+	b := strings.Builder{}
+
+	elem := func(c ctx, fieldName string, index int) {
+		switch c {
+		case ctxInStruct:
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(fieldName)
+		case ctxInList, ctxInSexp:
+			fmt.Fprintf(&b, "[%v]", index)
+		}
+	}
+
+	for _, e := range r.path {
+		elem(e.ctx, e.fieldName, e.count-1)
+	}
+	if r.ctx.peek() != ctxInStruct || r.fieldName != "" {
+		elem(r.ctx.peek(), r.fieldName, index)
+	}
+
+	return b.String()
+}
+
+// DecoderOpts returns the options of the Decoder calling an Unmarshaler with
+// this reader.
+func (r *reader) decoderOpts() DecoderOpts {
+	return r.opts
+}
+
+// SetDecoderOpts sets the options returned by decoderOpts, returning the old ones.
+func (r *reader) setDecoderOpts(opts DecoderOpts) DecoderOpts {
+	old := r.opts
+	r.opts = opts
+	return old
+}
+
 // Clear clears the current value from the reader.
 func (r *reader) clear() {
 	r.fieldName = ""
 	r.annotations = nil
 	r.valueType = NoType
 	r.value = nil
+	r.fieldNameSym = nil
+	r.annotationSyms = nil
+	r.symbolValue = nil
 }
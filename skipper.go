@@ -753,12 +753,14 @@ type commentHandler func() (bool, error)
 // Returns the first non-whitespace character it reads, and whether it
 // actually skipped anything to find it.
 func (t *tokenizer) skipWhitespaceWith(handler commentHandler) (int, bool, error) {
+	start := t.pos
 	skipped := false
 	for {
 		c, err := t.read()
 		if err != nil {
 			return 0, skipped, err
 		}
+		t.triviaStart, t.triviaEnd = start, t.pos-1
 
 		switch c {
 		case ' ', '\t', '\n', '\r':
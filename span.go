@@ -0,0 +1,143 @@
+package ion
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// A Span locates a value within a Reader's input. Start is the offset of the
+// value's first byte, including any annotations but not its field name, and End
+// is the offset just past its last byte. SymbolTable is the symbol table in effect
+// for the value, which is needed to read it again.
+type Span struct {
+	Start       uint64
+	End         uint64
+	SymbolTable SymbolTable
+}
+
+// A SpanReader is a Reader that can report where its current value is. The text
+// and binary Readers returned by NewReader and friends are SpanReaders.
+//
+// 	sr := r.(SpanReader)
+// 	for sr.Next() {
+// 		span, err := sr.Span()
+// 		// ...
+// 	}
+type SpanReader interface {
+	Reader
+
+	// Span returns the Span of the current value. It returns an error if the Reader
+	// is not positioned on a value. A text Reader doesn't know where a container ends
+	// until it has read past it, so it reads ahead to find out, holding on to the
+	// container's text until it reads it again.
+	Span() (Span, error)
+}
+
+// A SeekableReader is a SpanReader over seekable input that can go back to a value
+// it has read before.
+//
+// 	r := NewSeekableReaderBytes(data)
+// 	// ... record span ...
+// 	if err := r.Seek(span); err != nil {
+// 		return err
+// 	}
+// 	r.Next()
+type SeekableReader interface {
+	SpanReader
+
+	// Seek positions the Reader before the value at the given Span, which must have
+	// come from a Reader over the same input. The value is read as if it were the only
+	// top-level value in the stream: the next call to Next moves to it, and the one
+	// after returns false. Its field name, if it had one, is not restored, and errors
+	// from a text Reader no longer report line numbers.
+	Seek(s Span) error
+}
+
+// NewSeekableReader creates a new SeekableReader of the appropriate type by peeking
+// at the first several bytes of input for a binary version marker.
+func NewSeekableReader(in io.ReadSeeker) SeekableReader {
+	return NewSeekableReaderCat(in, nil)
+}
+
+// NewSeekableReaderBytes creates a new SeekableReader for the given bytes.
+func NewSeekableReaderBytes(in []byte) SeekableReader {
+	return NewSeekableReader(bytes.NewReader(in))
+}
+
+// NewSeekableReaderCat creates a new SeekableReader with the given catalog, which is
+// used to resolve shared symbol tables imported by the stream's local symbol tables.
+func NewSeekableReaderCat(in io.ReadSeeker, cat Catalog) SeekableReader {
+	br := bufio.NewReader(in)
+	return &seekableReader{
+		seeker: NewReaderCat(br, cat).(seeker),
+		in:     in,
+		buf:    br,
+	}
+}
+
+// A seeker is a SpanReader that can start over at a given Span.
+type seeker interface {
+	SpanReader
+	seek(in *bufio.Reader, s Span)
+}
+
+// A seekableReader adds seeking to a text or binary Reader.
+type seekableReader struct {
+	seeker
+
+	in  io.ReadSeeker
+	buf *bufio.Reader
+}
+
+// Seek positions the reader before the value at the given span.
+func (r *seekableReader) Seek(s Span) error {
+	if _, err := r.in.Seek(int64(s.Start), io.SeekStart); err != nil {
+		return &IOError{err}
+	}
+
+	r.buf.Reset(r.in)
+	r.seeker.seek(r.buf, s)
+	return nil
+}
+
+// Span returns the span of the current value, which was read using the given
+// symbol table.
+func (r *reader) span(lst SymbolTable) (Span, error) {
+	if r.valueType == NoType {
+		return Span{}, &UsageError{"SpanReader.Span", "no current value"}
+	}
+	return Span{
+		Start:       r.offset,
+		End:         r.end,
+		SymbolTable: lst,
+	}, nil
+}
+
+// Hoist resets the reader's state to read the single value at the given span.
+func (r *reader) hoist(s Span) {
+	r.clear()
+	r.ctx = ctxstack{}
+	r.eof = false
+	r.err = nil
+	r.offset = s.Start
+	r.end = 0
+	r.path = nil
+	r.count = 0
+	r.hoisted = true
+}
+
+// HoistDone returns true if the reader has been seeked to a value and has moved
+// past it.
+func (r *reader) hoistDone() bool {
+	return r.hoisted && r.ctx.peek() == ctxAtTopLevel && r.count > 0
+}
+
+// SymbolTableOrSystem returns the span's symbol table, or the system symbol table if
+// it doesn't have one.
+func (s Span) symbolTableOrSystem() SymbolTable {
+	if s.SymbolTable == nil {
+		return V1SystemSymbolTable
+	}
+	return s.SymbolTable
+}
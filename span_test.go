@@ -0,0 +1,271 @@
+package ion
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSpanText(t *testing.T) {
+	in := "a::{x:1} 'foo' /* c */ [1, two // c\n, (3 [4] /* ) */)]\n" +
+		`$ion_symbol_table::{symbols:["s"]} $10 "end" '''a''' '''b''' // c` + "\n"
+	r := NewSeekableReaderBytes([]byte(in))
+
+	if _, err := r.Span(); err == nil {
+		t.Error("expected an error before the first value")
+	}
+
+	spans := []Span{}
+	span := func() {
+		s, err := r.Span()
+		if err != nil {
+			t.Fatal(err)
+		}
+		spans = append(spans, s)
+	}
+
+	_nextAF(t, r, StructType, "", []string{"a"})
+	span()
+	_next(t, r, SymbolType)
+	span()
+	_next(t, r, ListType)
+	span()
+	if err := r.StepIn(); err != nil {
+		t.Fatal(err)
+	}
+	_next(t, r, IntType)
+	_next(t, r, SymbolType)
+	span()
+	_next(t, r, SexpType)
+	span()
+	_eof(t, r)
+	if err := r.StepOut(); err != nil {
+		t.Fatal(err)
+	}
+	_next(t, r, SymbolType)
+	span()
+	_next(t, r, StringType)
+	span()
+	_next(t, r, StringType)
+	span()
+	_eof(t, r)
+
+	test := func(i int, etext string) {
+		t.Run(etext, func(t *testing.T) {
+			s := spans[i]
+			if estart := uint64(strings.Index(in, etext)); s.Start != estart {
+				t.Errorf("expected start=%v, got %v", estart, s.Start)
+			}
+			if s.End < s.Start || s.End > uint64(len(in)) {
+				t.Fatalf("bad end=%v for start=%v", s.End, s.Start)
+			}
+			if text := in[s.Start:s.End]; text != etext {
+				t.Errorf("expected %q, got %q", etext, text)
+			}
+		})
+	}
+
+	test(0, "a::{x:1}")
+	test(1, "'foo'")
+	test(2, "[1, two // c\n, (3 [4] /* ) */)]")
+	test(3, "two")
+	test(4, "(3 [4] /* ) */)")
+	test(5, "$10")
+	test(6, `"end"`)
+	test(7, "'''a''' '''b'''")
+
+	if spans[5].SymbolTable == spans[1].SymbolTable {
+		t.Error("expected a new symbol table for $10")
+	}
+
+	seek := func(i int) {
+		if err := r.Seek(spans[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	seek(6)
+	_string(t, r, "end")
+	_eof(t, r)
+
+	seek(3)
+	_symbol(t, r, "two")
+	_eof(t, r)
+
+	seek(5)
+	_symbol(t, r, "s")
+	_eof(t, r)
+
+	seek(0)
+	_structAF(t, r, "", []string{"a"}, func(t *testing.T, r Reader) {
+		_intAF(t, r, "x", nil, 1)
+	})
+	_eof(t, r)
+
+	seek(4)
+	_sexp(t, r, func(t *testing.T, r Reader) {
+		_int(t, r, 3)
+		_list(t, r, func(t *testing.T, r Reader) {
+			_int(t, r, 4)
+		})
+	})
+	_eof(t, r)
+
+	seek(7)
+	_string(t, r, "ab")
+	_eof(t, r)
+
+	seek(1)
+	_symbol(t, r, "foo")
+	_eof(t, r)
+}
+
+func TestSpanBinary(t *testing.T) {
+	buf := bytes.Buffer{}
+	w := NewBinaryWriter(&buf)
+	w.Annotation("a")
+	w.BeginStruct()
+	w.FieldName("x")
+	w.WriteSymbol("foo")
+	w.EndStruct()
+	w.WriteString("bar")
+	w.BeginList()
+	w.WriteInt(1)
+	w.WriteNull()
+	w.EndList()
+	if err := w.Finish(); err != nil {
+		t.Fatal(err)
+	}
+	in := buf.Bytes()
+
+	r := NewSeekableReaderBytes(in)
+
+	spans := []Span{}
+	span := func() {
+		s, err := r.Span()
+		if err != nil {
+			t.Fatal(err)
+		}
+		spans = append(spans, s)
+	}
+
+	_nextAF(t, r, StructType, "", []string{"a"})
+	span()
+	_next(t, r, StringType)
+	span()
+	_list(t, r, func(t *testing.T, r Reader) {
+		_next(t, r, IntType)
+		_next(t, r, NullType)
+		span()
+	})
+	_eof(t, r)
+
+	test := func(i int, elen uint64) {
+		s := spans[i]
+		if s.End-s.Start != elen {
+			t.Errorf("expected span %v to be %v bytes, got %v", i, elen, s.End-s.Start)
+		}
+		if s.End > uint64(len(in)) {
+			t.Errorf("span %v ends past the end of the input", i)
+		}
+	}
+
+	// a::{x:foo} is a 7-byte annotation wrapper around a 3-byte struct.
+	test(0, 7)
+	test(1, 4)
+	test(2, 1)
+
+	if in[spans[1].Start] != 0x83 {
+		t.Errorf("expected span 1 to start with 0x83, got 0x%02X", in[spans[1].Start])
+	}
+
+	seek := func(i int) {
+		if err := r.Seek(spans[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	seek(2)
+	_null(t, r, NullType)
+	_eof(t, r)
+
+	seek(0)
+	_structAF(t, r, "", []string{"a"}, func(t *testing.T, r Reader) {
+		_symbolAF(t, r, "x", nil, "foo")
+	})
+	_eof(t, r)
+
+	seek(1)
+	_string(t, r, "bar")
+	_eof(t, r)
+
+	if _, err := r.Span(); err == nil {
+		t.Error("expected an error after the last value")
+	}
+}
+
+func TestSpanTextUnterminated(t *testing.T) {
+	r := NewSeekableReaderBytes([]byte("[1, 2"))
+	_next(t, r, ListType)
+	if _, err := r.Span(); err == nil {
+		t.Error("expected an error for an unterminated list")
+	}
+
+	// The reader can still read what's there.
+	if err := r.StepIn(); err != nil {
+		t.Fatal(err)
+	}
+	_int(t, r, 1)
+	_int(t, r, 2)
+	if r.Next() || r.Err() == nil {
+		t.Error("expected an error at the end of the input")
+	}
+}
+
+func TestSpanTextCRLF(t *testing.T) {
+	in := "a\r\n[1,\r\n 2]\r\n// c\r\n'''x\r\ny''' \"end\"\r\n"
+	r := NewSeekableReaderBytes([]byte(in))
+
+	spans := []Span{}
+	for r.Next() {
+		s, err := r.Span()
+		if err != nil {
+			t.Fatal(err)
+		}
+		spans = append(spans, s)
+	}
+	if err := r.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	etexts := []string{"a", "[1,\r\n 2]", "'''x\r\ny'''", `"end"`}
+	if len(spans) != len(etexts) {
+		t.Fatalf("expected %v spans, got %v", len(etexts), len(spans))
+	}
+	for i, etext := range etexts {
+		if text := in[spans[i].Start:spans[i].End]; text != etext {
+			t.Errorf("expected %q, got %q", etext, text)
+		}
+	}
+
+	if err := r.Seek(spans[1]); err != nil {
+		t.Fatal(err)
+	}
+	_list(t, r, func(t *testing.T, r Reader) {
+		_int(t, r, 1)
+		_int(t, r, 2)
+	})
+	_eof(t, r)
+
+	if err := r.Seek(spans[2]); err != nil {
+		t.Fatal(err)
+	}
+	_string(t, r, "x\ny")
+	_eof(t, r)
+
+	if err := r.Seek(spans[3]); err != nil {
+		t.Fatal(err)
+	}
+	_string(t, r, "end")
+	_eof(t, r)
+}
@@ -0,0 +1,136 @@
+package ion
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// SymbolIDUnknown is the local symbol ID of a SymbolToken whose ID is not known,
+// for example because it was read from text Ion.
+const SymbolIDUnknown int64 = -1
+
+// An ImportSource is the location of a symbol in an imported shared symbol table.
+type ImportSource struct {
+	// Table is the name of the shared symbol table.
+	Table string
+	// SID is the ID of the symbol within the shared symbol table.
+	SID int64
+}
+
+// A SymbolToken is an Ion symbol as it appears in a stream: as a field name, an
+// annotation, or a symbol value. Its text may be unknown, for example if it was
+// read from binary Ion that references a shared symbol table missing from the
+// Reader's Catalog. Tokens with unknown text can still be passed through a Writer
+// using their import location; a binary Writer rejects a bare local symbol ID
+// outside the system symbol table, since it belongs to the Reader's symbol table.
+// The string-based Writer APIs' $<integer> syntax refers to the Writer's own table.
+type SymbolToken struct {
+	// Text is the text of the symbol, or nil if it is unknown.
+	Text *string
+	// LocalSID is the ID of the symbol in the local symbol table it was read with,
+	// or SymbolIDUnknown.
+	LocalSID int64
+	// Source is the location of the symbol in an imported shared symbol table, or
+	// nil if it was not imported.
+	Source *ImportSource
+
+	// ref is set for tokens parsed from a $<integer> string passed to a Writer,
+	// whose LocalSID refers to the Writer's own symbol table.
+	ref bool
+}
+
+// NewSymbolToken creates a new symbol token with the given text.
+func NewSymbolToken(text string) SymbolToken {
+	return SymbolToken{
+		Text:     &text,
+		LocalSID: SymbolIDUnknown,
+	}
+}
+
+// NewSymbolTokenSID creates a new symbol token with unknown text and the given local
+// symbol ID.
+func NewSymbolTokenSID(sid int64) SymbolToken {
+	return SymbolToken{
+		LocalSID: sid,
+	}
+}
+
+// symbolTokenFromString converts a string passed to the string-based Reader and Writer
+// APIs, which use $<integer> to refer to a symbol by ID, to a SymbolToken.
+func symbolTokenFromString(sym string) SymbolToken {
+	if isSymbolRef(sym) {
+		if id, err := strconv.ParseInt(sym[1:], 10, 64); err == nil {
+			return SymbolToken{LocalSID: id, ref: true}
+		}
+	}
+	return NewSymbolToken(sym)
+}
+
+// String returns the text of the symbol, or $<sid> if its text is unknown.
+func (t SymbolToken) String() string {
+	if t.Text != nil {
+		return *t.Text
+	}
+	if t.LocalSID == SymbolIDUnknown {
+		return "$0"
+	}
+	return fmt.Sprintf("$%v", t.LocalSID)
+}
+
+// Equal returns true if two symbol tokens represent the same symbol: they have the
+// same text or, if their text is unknown, the same import location. Symbols with
+// unknown text and no import location are all equivalent to $0.
+func (t SymbolToken) Equal(o SymbolToken) bool {
+	if t.Text != nil || o.Text != nil {
+		return t.Text != nil && o.Text != nil && *t.Text == *o.Text
+	}
+	if t.Source != nil || o.Source != nil {
+		return t.Source != nil && o.Source != nil && *t.Source == *o.Source
+	}
+	return true
+}
+
+// newSymbolTokenFromTable creates a symbol token for the given ID, resolving its text
+// and import location using the given symbol table.
+func newSymbolTokenFromTable(st SymbolTable, id uint64) SymbolToken {
+	tok := SymbolToken{
+		LocalSID: SymbolIDUnknown,
+	}
+	if id <= math.MaxInt64 {
+		tok.LocalSID = int64(id)
+	}
+
+	if st == nil {
+		return tok
+	}
+
+	if text, ok := st.FindByID(id); ok {
+		tok.Text = &text
+	}
+
+	// Figure out which import (if any) the symbol came from.
+	off := uint64(0)
+	for _, imp := range importsOf(st) {
+		max := imp.MaxID()
+		if id > off && id <= off+max {
+			tok.Source = &ImportSource{
+				Table: imp.Name(),
+				SID:   int64(id - off),
+			}
+			break
+		}
+		off += max
+	}
+
+	return tok
+}
+
+// importsOf returns the shared symbol tables occupying the start of the given symbol
+// table's ID space. A shared symbol table occupies its own ID space.
+func importsOf(st SymbolTable) []SharedSymbolTable {
+	if sst, ok := st.(SharedSymbolTable); ok {
+		return []SharedSymbolTable{sst}
+	}
+	return st.Imports()
+}
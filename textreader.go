@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"strings"
 )
 
 // trs is the state of the text reader.
@@ -42,25 +43,89 @@ type textReader struct {
 
 	tok   tokenizer
 	state trs
+	cat   Catalog
+	lst   SymbolTable
 }
 
-func newTextReaderBuf(in *bufio.Reader) Reader {
+func newTextReaderBuf(in *bufio.Reader, cat Catalog) Reader {
 	return &textReader{
 		tok: tokenizer{
 			in: in,
 		},
 		state: trsBeforeTypeAnnotations,
+		cat:   cat,
+		lst:   V1SystemSymbolTable,
 	}
 }
 
 // SymbolTable returns the current symbol table.
 func (t *textReader) SymbolTable() SymbolTable {
-	// TODO: Include me if present in the input stream?
-	return nil
+	return t.lst
+}
+
+// Span returns the span of the current value. If it's a container we haven't stepped
+// in to, we read ahead to find its end.
+func (t *textReader) Span() (Span, error) {
+	if t.state == trsBeforeContainer && t.end == 0 && t.valueType != NoType {
+		end, err := t.tok.ContainerEnd(t.valueType)
+		if err != nil {
+			return Span{}, err
+		}
+		t.end = end
+	}
+	return t.span(t.lst)
+}
+
+// Seek resets the reader to read the value at the given span from in, which
+// must be positioned at its start.
+func (t *textReader) seek(in *bufio.Reader, s Span) {
+	t.hoist(s)
+	t.tok = tokenizer{
+		in:  in,
+		pos: s.Start,
+	}
+	t.state = trsBeforeTypeAnnotations
+	t.lst = s.symbolTableOrSystem()
 }
 
 // Next moves the reader to the next value.
 func (t *textReader) Next() bool {
+	if t.hoistDone() {
+		t.clear()
+		t.eof = true
+		return false
+	}
+
+	for t.next() {
+		// If it's a local symbol table, install it and keep going.
+		if t.ctx.peek() == ctxAtTopLevel && t.valueType == StructType && isIonSymbolTable(t.annotations) {
+			if err := t.readLocalSymbolTable(); err != nil {
+				t.explode(err)
+				return false
+			}
+			continue
+		}
+		t.count++
+		return true
+	}
+	return false
+}
+
+// ReadLocalSymbolTable reads and installs a new local symbol table.
+func (t *textReader) readLocalSymbolTable() error {
+	lst, err := readLocalSymbolTable(t, t.cat)
+	if err != nil {
+		return err
+	}
+
+	t.clear()
+	t.lst = lst
+	return nil
+}
+
+// Next moves the reader to the next raw value, which may turn out to be
+// a local symbol table.
+func (t *textReader) next() bool {
 	if t.state == trsDone || t.eof {
 		return false
 	}
@@ -81,6 +146,11 @@ func (t *textReader) Next() bool {
 			return false
 		}
 
+		if t.state == trsBeforeTypeAnnotations && len(t.annotations) == 0 {
+			// This token's either an annotation or the value itself.
+			t.offset = t.tok.Start()
+		}
+
 		var done bool
 		var err error
 
@@ -101,7 +171,13 @@ func (t *textReader) Next() bool {
 
 		if done {
 			// We're done reading tokens. If we hit the end of the current sequence,
-			// return false. Otherwise, we've got a value for the caller.
+			// return false. Otherwise, we've got a value for the caller, and unless
+			// it's a container we've read all the way to its end.
+			if t.state == trsBeforeContainer {
+				t.end = 0
+			} else {
+				t.end = t.tok.ValueEnd()
+			}
 			return !t.eof
 		}
 	}
@@ -131,7 +207,7 @@ func (t *textReader) nextAfterValue() (bool, error) {
 			t.eof = true
 			return true, nil
 		}
-		return false, &UnexpectedTokenError{"}", t.tok.Pos() - 1}
+		return false, &UnexpectedTokenError{Token: "}", Offset: t.tok.Pos() - 1}
 
 	case tokenCloseBracket:
 		// No more values in this list.
@@ -139,10 +215,10 @@ func (t *textReader) nextAfterValue() (bool, error) {
 			t.eof = true
 			return true, nil
 		}
-		return false, &UnexpectedTokenError{"]", t.tok.Pos() - 1}
+		return false, &UnexpectedTokenError{Token: "]", Offset: t.tok.Pos() - 1}
 
 	default:
-		return false, &UnexpectedTokenError{tok.String(), t.tok.Pos() - 1}
+		return false, &UnexpectedTokenError{Token: tok.String(), Offset: t.tok.Pos() - 1}
 	}
 }
 
@@ -162,10 +238,12 @@ func (t *textReader) nextBeforeFieldName() (bool, error) {
 		if err != nil {
 			return false, err
 		}
+		sym := NewSymbolToken(val)
 		if tok == tokenSymbol {
 			if err := t.verifyUnquotedSymbol(val, "field name"); err != nil {
 				return false, err
 			}
+			sym, val = t.resolve(val)
 		}
 
 		// Skip over the following colon.
@@ -173,16 +251,17 @@ func (t *textReader) nextBeforeFieldName() (bool, error) {
 			return false, err
 		}
 		if tok = t.tok.Token(); tok != tokenColon {
-			return false, &UnexpectedTokenError{tok.String(), t.tok.Pos() - 1}
+			return false, &UnexpectedTokenError{Token: tok.String(), Offset: t.tok.Pos() - 1}
 		}
 
 		t.fieldName = val
+		t.fieldNameSym = &sym
 		t.state = trsBeforeTypeAnnotations
 
 		return false, nil
 
 	default:
-		return false, &UnexpectedTokenError{tok.String(), t.tok.Pos() - 1}
+		return false, &UnexpectedTokenError{Token: tok.String(), Offset: t.tok.Pos() - 1}
 	}
 }
 
@@ -196,12 +275,12 @@ func (t *textReader) nextBeforeTypeAnnotations() (bool, error) {
 			t.eof = true
 			return true, nil
 		}
-		return false, &UnexpectedEOFError{t.tok.Pos() - 1}
+		return false, &UnexpectedEOFError{Offset: t.tok.Pos() - 1}
 
 	case tokenSymbolOperator, tokenDot:
 		if t.ctx.peek() != ctxInSexp {
 			// Operators can only appear inside an sexp.
-			return false, &UnexpectedTokenError{tok.String(), t.tok.Pos() - 1}
+			return false, &UnexpectedTokenError{Token: tok.String(), Offset: t.tok.Pos() - 1}
 		}
 		fallthrough
 
@@ -218,20 +297,20 @@ func (t *textReader) nextBeforeTypeAnnotations() (bool, error) {
 
 		if ok {
 			// val was an annotation; remember it and keep going.
+			sym := NewSymbolToken(val)
 			if tok == tokenSymbol {
 				if err := t.verifyUnquotedSymbol(val, "annotation"); err != nil {
 					return false, err
 				}
+				sym, val = t.resolve(val)
 			}
 			t.annotations = append(t.annotations, val)
+			t.annotationSyms = append(t.annotationSyms, sym)
 			return false, nil
 		}
 
-		// val was a legit symbol value.
-		if err := t.onSymbol(val, tok, ws); err != nil {
-			return false, err
-		}
-		return true, nil
+		// val was a legit symbol value (or possibly a version marker).
+		return t.onSymbol(val, tok, ws)
 
 	case tokenString, tokenLongString:
 		val, err := t.tok.ReadValue(tok)
@@ -286,7 +365,7 @@ func (t *textReader) nextBeforeTypeAnnotations() (bool, error) {
 			t.eof = true
 			return true, nil
 		}
-		return false, &UnexpectedTokenError{"]", t.tok.Pos() - 1}
+		return false, &UnexpectedTokenError{Token: "]", Offset: t.tok.Pos() - 1}
 
 	case tokenCloseParen:
 		// No more values in this sexp.
@@ -294,10 +373,10 @@ func (t *textReader) nextBeforeTypeAnnotations() (bool, error) {
 			t.eof = true
 			return true, nil
 		}
-		return false, &UnexpectedTokenError{")", t.tok.Pos() - 1}
+		return false, &UnexpectedTokenError{Token: ")", Offset: t.tok.Pos() - 1}
 
 	default:
-		return false, &UnexpectedTokenError{tok.String(), t.tok.Pos() - 1}
+		return false, &UnexpectedTokenError{Token: tok.String(), Offset: t.tok.Pos() - 1}
 	}
 }
 
@@ -311,6 +390,7 @@ func (t *textReader) StepIn() error {
 	}
 
 	ctx := containerTypeToCtx(t.valueType)
+	t.pushPath()
 	t.ctx.push(ctx)
 
 	if ctx == ctxInStruct {
@@ -341,17 +421,18 @@ func (t *textReader) StepOut() error {
 	_, err := t.tok.FinishValue()
 	if err != nil {
 		t.explode(err)
-		return err
+		return t.err
 	}
 
 	// If we haven't seen the end of the container yet, skip values until we find it.
 	if !t.eof {
 		if err := t.tok.SkipContainerContents(ctype); err != nil {
 			t.explode(err)
-			return err
+			return t.err
 		}
 	}
 
+	t.popPath()
 	t.ctx.pop()
 	t.state = t.stateAfterValue()
 	t.clear()
@@ -365,22 +446,28 @@ func (t *textReader) StepOut() error {
 func (t *textReader) verifyUnquotedSymbol(val string, ctx string) error {
 	switch val {
 	case "null", "true", "false", "nan":
-		return &SyntaxError{fmt.Sprintf("unquoted keyword '%v' as %v", val, ctx), t.tok.Pos() - 1}
+		return &SyntaxError{Msg: fmt.Sprintf("unquoted keyword '%v' as %v", val, ctx), Offset: t.tok.Pos() - 1}
 	}
 	return nil
 }
 
-// OnSymbol handles finding a symbol-token value.
-func (t *textReader) onSymbol(val string, tok token, ws bool) error {
+// OnSymbol handles finding a symbol-token value. It returns false if the symbol
+// turned out to be an Ion version marker rather than a user-facing value.
+func (t *textReader) onSymbol(val string, tok token, ws bool) (bool, error) {
 	valueType := SymbolType
 	var value interface{} = val
+	var sym *SymbolToken
 
 	if tok == tokenSymbol {
+		if t.ctx.peek() == ctxAtTopLevel && len(t.annotations) == 0 && isIonVersionMarker(val) {
+			return false, t.onIVM(val)
+		}
+
 		switch val {
 		case "null":
 			vt, err := t.onNull(ws)
 			if err != nil {
-				return err
+				return false, err
 			}
 			valueType = vt
 			value = nil
@@ -396,14 +483,79 @@ func (t *textReader) onSymbol(val string, tok token, ws bool) error {
 		case "nan":
 			valueType = FloatType
 			value = math.NaN()
+
+		default:
+			tok, str := t.resolve(val)
+			sym = &tok
+			value = str
 		}
 	}
 
 	t.state = t.stateAfterValue()
 	t.valueType = valueType
 	t.value = value
+	t.symbolValue = sym
 
-	return nil
+	return true, nil
+}
+
+// OnIVM handles finding an Ion version marker, validating it and resetting
+// the local symbol table.
+func (t *textReader) onIVM(val string) error {
+	t.state = t.stateAfterValue()
+
+	if val == "$ion_1_0" {
+		t.lst = V1SystemSymbolTable
+		return nil
+	}
+
+	var major, minor int
+	fmt.Sscanf(val, "$ion_%d_%d", &major, &minor)
+	return &UnsupportedVersionError{Major: major, Minor: minor, Offset: t.tok.Pos() - uint64(len(val)) - 1}
+}
+
+// Resolve resolves an unquoted symbol to a symbol token and its string form. Symbols
+// of the form $<integer> are looked up by ID in the current symbol table; if their
+// text is unknown, their string form remains $<integer>.
+func (t *textReader) resolve(val string) (SymbolToken, string) {
+	if !isSymbolRef(val) {
+		return NewSymbolToken(val), val
+	}
+
+	id, err := strconv.ParseUint(val[1:], 10, 64)
+	if err != nil {
+		return SymbolToken{LocalSID: SymbolIDUnknown}, val
+	}
+
+	tok := newSymbolTokenFromTable(t.lst, id)
+	if tok.Text == nil {
+		return tok, fmt.Sprintf("$%v", id)
+	}
+	return tok, *tok.Text
+}
+
+// IsIonVersionMarker returns true if the given unquoted symbol is an Ion version
+// marker of the form $ion_<major>_<minor>.
+func isIonVersionMarker(val string) bool {
+	if !strings.HasPrefix(val, "$ion_") {
+		return false
+	}
+
+	parts := strings.Split(val[5:], "_")
+	if len(parts) != 2 {
+		return false
+	}
+	for _, p := range parts {
+		if len(p) == 0 {
+			return false
+		}
+		for i := 0; i < len(p); i++ {
+			if !isDigit(int(p[i])) {
+				return false
+			}
+		}
+	}
+	return true
 }
 
 // OnNull handles finding a null token.
@@ -427,7 +579,7 @@ func (t *textReader) readNullType() (Type, error) {
 	}
 	if t.tok.Token() != tokenSymbol {
 		msg := fmt.Sprintf("invalid symbol null.%v", t.tok.Token())
-		return NoType, &SyntaxError{msg, t.tok.Pos() - 1}
+		return NoType, &SyntaxError{Msg: msg, Offset: t.tok.Pos() - 1}
 	}
 
 	val, err := t.tok.ReadValue(tokenSymbol)
@@ -464,7 +616,7 @@ func (t *textReader) readNullType() (Type, error) {
 		return SexpType, nil
 	default:
 		msg := fmt.Sprintf("invalid symbol null.%v", t.tok.Token())
-		return NoType, &SyntaxError{msg, t.tok.Pos() - 1}
+		return NoType, &SyntaxError{Msg: msg, Offset: t.tok.Pos() - 1}
 	}
 }
 
@@ -653,6 +805,12 @@ func (t *textReader) stateAfterValue() trs {
 // Explode explodes the reader state when something unexpected
 // happens and further calls to Next are a bad idea.
 func (t *textReader) explode(err error) {
+	lineCol := t.tok.LineCol
+	if t.hoisted {
+		// Having seeked, we don't know what line we're on.
+		lineCol = nil
+	}
+
 	t.state = trsDone
-	t.err = err
+	t.err = withPosition(err, lineCol, t.errPath())
 }
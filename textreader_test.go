@@ -2,8 +2,11 @@ package ion
 
 import (
 	"bytes"
+	"errors"
+	"io"
 	"math"
 	"math/big"
+	"strings"
 	"testing"
 	"time"
 )
@@ -414,6 +417,89 @@ func TestTopLevelOperators(t *testing.T) {
 	}
 }
 
+func TestReadTextSymbolIDs(t *testing.T) {
+	r := NewReaderStr("$4::{$5:$6} '$4' $99")
+
+	_structAF(t, r, "", []string{"name"}, func(t *testing.T, r Reader) {
+		_symbolAF(t, r, "version", nil, "imports")
+		if sid := r.FieldNameSymbol().LocalSID; sid != 5 {
+			t.Errorf("expected field name SID 5, got %v", sid)
+		}
+		_eof(t, r)
+	})
+	_symbol(t, r, "$4")
+	_symbol(t, r, "$99")
+
+	tok, err := r.SymbolValue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.Text != nil || tok.LocalSID != 99 {
+		t.Errorf("expected unknown symbol $99, got %+v", tok)
+	}
+
+	_eof(t, r)
+}
+
+func TestReadTextLocalSymbolTable(t *testing.T) {
+	ion := `$ion_symbol_table::{symbols:["foo", "bar"]}
+$10 $11
+$ion_symbol_table::{imports:$ion_symbol_table, symbols:["baz"]}
+$10 $12
+$ion_1_0
+$10`
+	r := NewReaderStr(ion)
+
+	_symbol(t, r, "foo")
+	_symbol(t, r, "bar")
+	if r.SymbolTable().MaxID() != 11 {
+		t.Errorf("expected max id 11, got %v", r.SymbolTable().MaxID())
+	}
+
+	_symbol(t, r, "foo")
+	_symbol(t, r, "baz")
+
+	_symbol(t, r, "$10")
+	if r.SymbolTable() != V1SystemSymbolTable {
+		t.Errorf("expected system symbol table after IVM, got %v", r.SymbolTable())
+	}
+
+	_eof(t, r)
+}
+
+func TestReadTextSharedSymbolTableImports(t *testing.T) {
+	sst := NewSharedSymbolTable("shared", 1, []string{"a", "b"})
+	ion := `$ion_symbol_table::{imports:[{name:"shared", version:1, max_id:2}], symbols:["c"]}
+$10 $11 $12`
+	r := NewReaderCat(strings.NewReader(ion), NewCatalog(sst))
+
+	_symbol(t, r, "a")
+	tok, err := r.SymbolValue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.Source == nil || tok.Source.Table != "shared" || tok.Source.SID != 1 {
+		t.Errorf("expected symbol imported from shared/1, got %+v", tok)
+	}
+
+	_symbol(t, r, "b")
+	_symbol(t, r, "c")
+	_eof(t, r)
+}
+
+func TestReadTextUnsupportedVersion(t *testing.T) {
+	r := NewReaderStr("'$ion_2_0' $ion_2_0")
+
+	_symbol(t, r, "$ion_2_0")
+
+	if r.Next() {
+		t.Fatal("next returned true")
+	}
+	if _, ok := r.Err().(*UnsupportedVersionError); !ok {
+		t.Errorf("expected UnsupportedVersionError, got %v", r.Err())
+	}
+}
+
 func TestTrsToString(t *testing.T) {
 	for i := trsDone; i <= trsAfterValue+1; i++ {
 		str := i.String()
@@ -786,3 +872,48 @@ func _eof(t *testing.T, r Reader) {
 		t.Fatal(r.Err())
 	}
 }
+
+func TestReadTextErrorPosition(t *testing.T) {
+	ion := `{orders:[
+  {id:1},
+  {id:2, items:[1, 2, {price: 1.2.3}]}
+]}`
+	_, err := ReadValues(NewReaderStr(ion))
+
+	re, ok := err.(*UnexpectedRuneError)
+	if !ok {
+		t.Fatalf("expected UnexpectedRuneError, got %v", err)
+	}
+	if re.Line != 3 || re.Column != 34 {
+		t.Errorf("expected line 3, column 34, got line %v, column %v", re.Line, re.Column)
+	}
+	if re.Path != "orders[1].items[2].price" {
+		t.Errorf("expected path orders[1].items[2].price, got %v", re.Path)
+	}
+
+	eval := "ion: unexpected rune '.' (offset 53, line 3, column 34, at orders[1].items[2].price)"
+	if err.Error() != eval {
+		t.Errorf("expected %v, got %v", eval, err.Error())
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+func TestReadTextIOError(t *testing.T) {
+	r := NewReader(failingReader{})
+	if r.Next() {
+		t.Fatal("next returned true")
+	}
+
+	var ioe *IOError
+	if !errors.As(r.Err(), &ioe) {
+		t.Fatalf("expected an IOError, got %v", r.Err())
+	}
+	if !errors.Is(r.Err(), io.ErrClosedPipe) {
+		t.Errorf("expected %v to wrap io.ErrClosedPipe", r.Err())
+	}
+}
@@ -1,12 +1,10 @@
 package ion
 
 import (
-	"fmt"
 	"io"
 	"math/big"
 	"strconv"
 	"strings"
-	"time"
 )
 
 // Does this symbol need to be quoted in text form?
@@ -145,6 +143,44 @@ func formatFloat(val float64) string {
 	return str
 }
 
+// FormatJSONDecimal formats a decimal as a JSON number, which uses 'e' instead of
+// 'd' for its exponent and doesn't allow a trailing decimal point.
+func formatJSONDecimal(val *Decimal) string {
+	str := strings.Replace(val.String(), "d", "e", 1)
+	return strings.TrimSuffix(str, ".")
+}
+
+// Write the given string out as a quoted JSON string.
+func writeJSONString(str string, out io.Writer) error {
+	if err := writeRawChar('"', out); err != nil {
+		return err
+	}
+
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		var err error
+		switch {
+		case c == '"' || c == '\\':
+			err = writeRawChars([]byte{'\\', c}, out)
+		case c == '\n':
+			err = writeRawString("\\n", out)
+		case c == '\r':
+			err = writeRawString("\\r", out)
+		case c == '\t':
+			err = writeRawString("\\t", out)
+		case c < 32:
+			err = writeRawChars([]byte{'\\', 'u', '0', '0', hexChars[c>>4], hexChars[c&0xF]}, out)
+		default:
+			err = writeRawChar(c, out)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return writeRawChar('"', out)
+}
+
 // Write the given symbol out, quoting and encoding if necessary.
 func writeSymbol(sym string, out io.Writer) error {
 	if symbolNeedsQuoting(sym) {
@@ -159,6 +195,21 @@ func writeSymbol(sym string, out io.Writer) error {
 	return writeRawString(sym, out)
 }
 
+// Write the given symbol token out. Symbols with known text are quoted if they would
+// otherwise be mistaken for a symbol ID; symbols with unknown text are written as $<sid>.
+func writeSymbolToken(tok SymbolToken, out io.Writer) error {
+	if tok.Text != nil && isSymbolRef(*tok.Text) {
+		if err := writeRawChar('\'', out); err != nil {
+			return err
+		}
+		if err := writeEscapedSymbol(*tok.Text, out); err != nil {
+			return err
+		}
+		return writeRawChar('\'', out)
+	}
+	return writeSymbol(tok.String(), out)
+}
+
 // Write the given symbol out, escaping any characters that need escaping.
 func writeEscapedSymbol(sym string, out io.Writer) error {
 	for i := 0; i < len(sym); i++ {
@@ -193,6 +244,24 @@ func writeEscapedString(str string, out io.Writer) error {
 	return nil
 }
 
+// Write out the given string, escaping any characters as needed to be
+// written inside a long string.
+func writeEscapedLongString(str string, out io.Writer) error {
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		if c < 32 || c == '\\' || c == '\'' {
+			if err := writeEscapedChar(c, out); err != nil {
+				return err
+			}
+		} else {
+			if err := writeRawChar(c, out); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // Write out the given character in escaped form.
 func writeEscapedChar(c byte, out io.Writer) error {
 	switch c {
@@ -303,80 +372,6 @@ func parseInt(str string, radix int) (interface{}, error) {
 	return bi, nil
 }
 
-func parseTimestamp(val string) (time.Time, error) {
-	if len(val) < 5 {
-		return invalidTimestamp(val)
-	}
-
-	year, err := strconv.ParseInt(val[:4], 10, 32)
-	if err != nil {
-		return invalidTimestamp(val)
-	}
-	if len(val) == 5 && (val[4] == 't' || val[4] == 'T') {
-		// yyyyT
-		return time.Date(int(year), 1, 1, 0, 0, 0, 0, time.UTC), nil
-	}
-	if val[4] != '-' {
-		return invalidTimestamp(val)
-	}
-
-	if len(val) < 8 {
-		return invalidTimestamp(val)
-	}
-
-	month, err := strconv.ParseInt(val[5:7], 10, 32)
-	if err != nil {
-		return invalidTimestamp(val)
-	}
-
-	if len(val) == 8 && (val[7] == 't' || val[7] == 'T') {
-		// yyyy-mmT
-		return time.Date(int(year), time.Month(month), 1, 0, 0, 0, 0, time.UTC), nil
-	}
-	if val[7] != '-' {
-		return invalidTimestamp(val)
-	}
-
-	if len(val) < 10 {
-		return invalidTimestamp(val)
-	}
-
-	day, err := strconv.ParseInt(val[8:10], 10, 32)
-	if err != nil {
-		return invalidTimestamp(val)
-	}
-
-	if len(val) == 10 || (len(val) == 11 && (val[10] == 't' || val[10] == 'T')) {
-		// yyyy-mm-dd or yyyy-mm-ddT
-		return time.Date(int(year), time.Month(month), int(day), 0, 0, 0, 0, time.UTC), nil
-	}
-	if val[10] != 't' && val[10] != 'T' {
-		return invalidTimestamp(val)
-	}
-
-	if len(val) < 17 {
-		return invalidTimestamp(val)
-	}
-	if val[16] != ':' {
-		return time.Parse("2006-01-02T15:04Z07:00", val)
-	}
-
-	if len(val) > 19 && val[19] == '.' {
-		i := 20
-		for i < len(val) && isDigit(int(val[i])) {
-			i++
-		}
-
-		if i >= 29 {
-			// Too much precision for a go Time.
-			// TODO: We should probably round instead of truncating? Ah well.
-			return time.Parse(time.RFC3339Nano, val[:29]+val[i:])
-		}
-	}
-
-	return time.Parse(time.RFC3339Nano, val)
-}
-
-func invalidTimestamp(val string) (time.Time, error) {
-	return time.Time{}, fmt.Errorf("ion: invalid timestamp: %v", val)
+func parseTimestamp(str string) (*Timestamp, error) {
+	return ParseTimestamp(str)
 }
@@ -19,7 +19,7 @@ func TestParseTimestamp(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			if !val.Equal(et) {
+			if !val.Time().Equal(et) {
 				t.Errorf("expected %v, got %v", eval, val)
 			}
 		})
@@ -1,11 +1,15 @@
 package ion
 
 import (
+	"bytes"
 	"encoding/base64"
 	"fmt"
 	"io"
+	"math"
 	"math/big"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 // TextWriterOpts defines a set of bit flag options for text writers.
@@ -16,6 +20,32 @@ const (
 	// know you're only emiting one datagram; dangerous if there's a chance you're going
 	// to emit another datagram using the same Writer.
 	TextWriterQuietFinish TextWriterOpts = 1
+
+	// TextWriterPretty writes text meant to be read by humans: each struct field and
+	// each list or sexp element goes on its own line, indented to show its depth.
+	TextWriterPretty TextWriterOpts = 2
+
+	// TextWriterCompactLists, together with TextWriterPretty, writes lists and sexps
+	// that contain only scalar values on a single line if they fit within the line
+	// width.
+	TextWriterCompactLists TextWriterOpts = 4
+
+	// TextWriterWrapStrings, together with TextWriterPretty, writes strings in lists
+	// and structs that are too long to fit within the line width as a sequence of
+	// '''long string''' segments, one per line.
+	TextWriterWrapStrings TextWriterOpts = 8
+
+	// TextWriterJSON writes JSON instead of Ion, down-converting values as described
+	// by the Ion specification: nulls of any type, nan, and infinities become null;
+	// ints, floats, and decimals become numbers; timestamps, symbols, clobs, and
+	// base64-encoded blobs become strings; sexps become arrays; and annotations are
+	// dropped. Top-level values are written one per line.
+	TextWriterJSON TextWriterOpts = 16
+)
+
+const (
+	defaultTextIndent = "  "
+	defaultTextWidth  = 80
 )
 
 // textWriter is a writer that writes human-readable text
@@ -23,6 +53,14 @@ type textWriter struct {
 	writer
 	needsSeparator bool
 	opts           TextWriterOpts
+
+	// Pretty-printing state: the indentation for each level of nesting, the
+	// line width to aim for, the column the output is currently at, and the
+	// list or sexp currently being written compactly, if any.
+	indent  string
+	width   int
+	cols    *columnWriter
+	compact *compactSeq
 }
 
 // NewTextWriter returns a new text writer.
@@ -30,8 +68,12 @@ func NewTextWriter(out io.Writer) Writer {
 	return NewTextWriterOpts(out, 0)
 }
 
-// NewTextWriterOpts returns a new text writer with the given options.
+// NewTextWriterOpts returns a new text writer with the given options. If the options
+// include TextWriterPretty, it indents with two spaces and aims for a line width of 80.
 func NewTextWriterOpts(out io.Writer, opts TextWriterOpts) Writer {
+	if opts&TextWriterPretty != 0 {
+		return NewTextWriterIndent(out, opts, defaultTextIndent, defaultTextWidth)
+	}
 	return &textWriter{
 		writer: writer{
 			out: out,
@@ -40,6 +82,34 @@ func NewTextWriterOpts(out io.Writer, opts TextWriterOpts) Writer {
 	}
 }
 
+// NewJSONWriter returns a new writer that down-converts Ion values to JSON, as
+// with TextWriterJSON.
+//
+// 	w := NewJSONWriter(out)
+// 	e := NewEncoder(w)
+// 	err := e.Encode(thing)
+func NewJSONWriter(out io.Writer) Writer {
+	return NewTextWriterOpts(out, TextWriterJSON)
+}
+
+// NewTextWriterIndent returns a new text writer that pretty-prints its output,
+// indenting nested values with the given indent string and wrapping strings and
+// lists (if the corresponding options are set) to fit in the given line width.
+//
+// 	w := NewTextWriterIndent(out, TextWriterCompactLists, "\t", 100)
+func NewTextWriterIndent(out io.Writer, opts TextWriterOpts, indent string, width int) Writer {
+	cols := &columnWriter{out: out}
+	return &textWriter{
+		writer: writer{
+			out: cols,
+		},
+		opts:   opts | TextWriterPretty,
+		indent: indent,
+		width:  width,
+		cols:   cols,
+	}
+}
+
 // WriteNull writes an untyped null.
 func (w *textWriter) WriteNull() error {
 	return w.writeValue("Writer.WriteNull", textNulls[NoType])
@@ -47,6 +117,9 @@ func (w *textWriter) WriteNull() error {
 
 // WriteNullType writes a typed null.
 func (w *textWriter) WriteNullType(t Type) error {
+	if w.json() {
+		t = NoType
+	}
 	return w.writeValue("Writer.WriteNullType", textNulls[t])
 }
 
@@ -76,21 +149,47 @@ func (w *textWriter) WriteBigInt(val *big.Int) error {
 
 // WriteFloat writes a floating-point value.
 func (w *textWriter) WriteFloat(val float64) error {
+	if w.json() && (math.IsNaN(val) || math.IsInf(val, 0)) {
+		return w.writeValue("Writer.WriteFloat", textNulls[NoType])
+	}
 	return w.writeValue("Writer.WriteFloat", formatFloat(val))
 }
 
+// WriteFloat32 writes a 32-bit floating-point value. Text has no notion of
+// float size, so it's written exactly as its 64-bit equivalent.
+func (w *textWriter) WriteFloat32(val float32) error {
+	return w.WriteFloat(float64(val))
+}
+
 // WriteDecimal writes an arbitrary-precision decimal value.
 func (w *textWriter) WriteDecimal(val *Decimal) error {
+	if w.json() {
+		return w.writeValue("Writer.WriteDecimal", formatJSONDecimal(val))
+	}
 	return w.writeValue("Writer.WriteDecimal", val.String())
 }
 
 // WriteTimestamp writes a timestamp.
 func (w *textWriter) WriteTimestamp(val time.Time) error {
+	if w.json() {
+		return w.writeJSONString("Writer.WriteTimestamp", val.Format(time.RFC3339Nano))
+	}
 	return w.writeValue("Writer.WriteTimestamp", val.Format(time.RFC3339Nano))
 }
 
+// WriteIonTimestamp writes an Ion timestamp.
+func (w *textWriter) WriteIonTimestamp(val *Timestamp) error {
+	if w.json() {
+		return w.writeJSONString("Writer.WriteIonTimestamp", val.String())
+	}
+	return w.writeValue("Writer.WriteIonTimestamp", val.String())
+}
+
 // WriteSymbol writes a symbol.
 func (w *textWriter) WriteSymbol(val string) error {
+	if w.json() {
+		return w.writeJSONString("Writer.WriteSymbol", val)
+	}
 	if w.err != nil {
 		return w.err
 	}
@@ -102,19 +201,57 @@ func (w *textWriter) WriteSymbol(val string) error {
 		return w.err
 	}
 
-	w.endValue()
-	return nil
+	return w.endValue()
+}
+
+// WriteSymbolToken writes a symbol token.
+func (w *textWriter) WriteSymbolToken(val SymbolToken) error {
+	if w.json() {
+		return w.writeJSONString("Writer.WriteSymbolToken", val.String())
+	}
+	if w.err != nil {
+		return w.err
+	}
+	if w.err = w.beginValue("Writer.WriteSymbolToken"); w.err != nil {
+		return w.err
+	}
+
+	if w.err = writeSymbolToken(val, w.out); w.err != nil {
+		return w.err
+	}
+
+	return w.endValue()
 }
 
 // WriteString writes a string.
 func (w *textWriter) WriteString(val string) error {
+	if w.json() {
+		return w.writeJSONString("Writer.WriteString", val)
+	}
 	if w.err != nil {
 		return w.err
 	}
+	// Adjacent long strings are concatenated when read, so only wrap strings where
+	// there's a comma to keep them apart from their neighbors.
+	wrap := w.opts&TextWriterWrapStrings != 0 && w.opts&TextWriterPretty != 0 &&
+		(w.ctx.peek() == ctxInList || w.ctx.peek() == ctxInStruct)
+	if wrap && w.compact != nil && utf8.RuneCountInString(val) > w.width {
+		// Not going to fit on one line with anything else.
+		if w.err = w.expand(); w.err != nil {
+			return w.err
+		}
+	}
 	if w.err = w.beginValue("Writer.WriteString"); w.err != nil {
 		return w.err
 	}
 
+	if wrap && w.compact == nil && utf8.RuneCountInString(val)+2 > w.width-w.cols.col {
+		if w.err = w.writeLongString(val); w.err != nil {
+			return w.err
+		}
+		return w.endValue()
+	}
+
 	if w.err = writeRawChar('"', w.out); w.err != nil {
 		return w.err
 	}
@@ -125,12 +262,19 @@ func (w *textWriter) WriteString(val string) error {
 		return w.err
 	}
 
-	w.endValue()
-	return nil
+	return w.endValue()
 }
 
 // WriteClob writes a clob.
 func (w *textWriter) WriteClob(val []byte) error {
+	if w.json() {
+		// Each byte becomes the code point with the same value.
+		rs := make([]rune, len(val))
+		for i, c := range val {
+			rs[i] = rune(c)
+		}
+		return w.writeJSONString("Writer.WriteClob", string(rs))
+	}
 	if w.err != nil {
 		return w.err
 	}
@@ -156,12 +300,14 @@ func (w *textWriter) WriteClob(val []byte) error {
 		return w.err
 	}
 
-	w.endValue()
-	return nil
+	return w.endValue()
 }
 
 // WriteBlob writes a blob.
 func (w *textWriter) WriteBlob(val []byte) error {
+	if w.json() {
+		return w.writeJSONString("Writer.WriteBlob", base64.StdEncoding.EncodeToString(val))
+	}
 	if w.err != nil {
 		return w.err
 	}
@@ -183,8 +329,7 @@ func (w *textWriter) WriteBlob(val []byte) error {
 		return w.err
 	}
 
-	w.endValue()
-	return nil
+	return w.endValue()
 }
 
 // BeginList begins writing a list.
@@ -206,7 +351,11 @@ func (w *textWriter) EndList() error {
 // BeginSexp begins writing an s-expression.
 func (w *textWriter) BeginSexp() error {
 	if w.err == nil {
-		w.err = w.begin("Writer.BeginSexp", ctxInSexp, '(')
+		c := byte('(')
+		if w.json() {
+			c = '['
+		}
+		w.err = w.begin("Writer.BeginSexp", ctxInSexp, c)
 	}
 	return w.err
 }
@@ -214,7 +363,11 @@ func (w *textWriter) BeginSexp() error {
 // EndSexp finishes writing an s-expression.
 func (w *textWriter) EndSexp() error {
 	if w.err == nil {
-		w.err = w.end("Writer.EndSexp", ctxInSexp, ')')
+		c := byte(')')
+		if w.json() {
+			c = ']'
+		}
+		w.err = w.end("Writer.EndSexp", ctxInSexp, c)
 	}
 	return w.err
 }
@@ -235,6 +388,16 @@ func (w *textWriter) EndStruct() error {
 	return w.err
 }
 
+// WriteValue writes the value the reader is positioned on.
+func (w *textWriter) WriteValue(r Reader) error {
+	return w.writeValueFrom(w, r)
+}
+
+// WriteValues writes the rest of the reader's values.
+func (w *textWriter) WriteValues(r Reader) error {
+	return writeValues(w, r)
+}
+
 // Finish finishes writing the current datagram.
 func (w *textWriter) Finish() error {
 	if w.err != nil {
@@ -268,21 +431,48 @@ func (w *textWriter) writeValue(api string, val string) error {
 		return w.err
 	}
 
-	w.endValue()
-	return nil
+	return w.endValue()
+}
+
+// writeJSONString writes a value as a JSON string.
+func (w *textWriter) writeJSONString(api string, val string) error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.err = w.beginValue(api); w.err != nil {
+		return w.err
+	}
+
+	if w.err = writeJSONString(val, w.out); w.err != nil {
+		return w.err
+	}
+
+	return w.endValue()
+}
+
+// json returns true if we're writing JSON instead of Ion.
+func (w *textWriter) json() bool {
+	return w.opts&TextWriterJSON != 0
 }
 
 // beginValue begins the process of writing a value, by writing out
 // a separator (if needed), field name (if in a struct), and type
 // annotations (if any).
 func (w *textWriter) beginValue(api string) error {
-	if w.needsSeparator {
+	if w.opts&TextWriterPretty != 0 {
+		if err := w.writePrettySeparator(); err != nil {
+			return err
+		}
+	} else if w.needsSeparator {
 		var sep byte
 		switch w.ctx.peek() {
 		case ctxInStruct, ctxInList:
 			sep = ','
 		case ctxInSexp:
 			sep = ' '
+			if w.json() {
+				sep = ','
+			}
 		default:
 			sep = '\n'
 		}
@@ -293,26 +483,39 @@ func (w *textWriter) beginValue(api string) error {
 	}
 
 	if w.inStruct() {
-		if w.fieldName == "" {
+		if w.fieldName == nil {
 			return &UsageError{api, "field name not set"}
 		}
 		name := w.fieldName
-		w.fieldName = ""
+		w.fieldName = nil
 
-		if err := writeSymbol(name, w.out); err != nil {
+		if w.json() {
+			if err := writeJSONString(name.String(), w.out); err != nil {
+				return err
+			}
+		} else if err := writeSymbolToken(*name, w.out); err != nil {
 			return err
 		}
 		if err := writeRawChar(':', w.out); err != nil {
 			return err
 		}
+		if w.opts&TextWriterPretty != 0 {
+			if err := writeRawChar(' ', w.out); err != nil {
+				return err
+			}
+		}
 	}
 
+	if len(w.annotations) > 0 && w.json() {
+		// JSON has no annotations; drop them.
+		w.annotations = nil
+	}
 	if len(w.annotations) > 0 {
 		as := w.annotations
 		w.annotations = nil
 
 		for _, a := range as {
-			if err := writeSymbol(a, w.out); err != nil {
+			if err := writeSymbolToken(a, w.out); err != nil {
 				return err
 			}
 			if err := writeRawString("::", w.out); err != nil {
@@ -325,12 +528,29 @@ func (w *textWriter) beginValue(api string) error {
 }
 
 // endValue finishes the process of writing a value.
-func (w *textWriter) endValue() {
+func (w *textWriter) endValue() error {
 	w.needsSeparator = true
+
+	if c := w.compact; c != nil {
+		c.starts = append(c.starts, c.start)
+		c.ends = append(c.ends, c.buf.Len())
+
+		// Leave room for the closing bracket.
+		if c.col+c.buf.Len()+1 > w.width {
+			w.err = w.expand()
+		}
+	}
+	return w.err
 }
 
 // begin starts writing a container of the given type.
 func (w *textWriter) begin(api string, t ctx, c byte) error {
+	if w.compact != nil {
+		// Only lists and sexps of scalars are written compactly.
+		if err := w.expand(); err != nil {
+			return err
+		}
+	}
 	if err := w.beginValue(api); err != nil {
 		return err
 	}
@@ -338,6 +558,15 @@ func (w *textWriter) begin(api string, t ctx, c byte) error {
 	w.ctx.push(t)
 	w.needsSeparator = false
 
+	if t != ctxInStruct && w.opts&TextWriterCompactLists != 0 && w.opts&TextWriterPretty != 0 {
+		w.compact = &compactSeq{
+			out:  w.out,
+			col:  w.cols.col,
+			open: c,
+		}
+		w.out = &w.compact.buf
+	}
+
 	return writeRawChar(c, w.out)
 }
 
@@ -347,13 +576,181 @@ func (w *textWriter) end(api string, t ctx, c byte) error {
 		return &UsageError{api, "not in that kind of container"}
 	}
 
+	if cs := w.compact; cs != nil {
+		// It fit; write it out on one line.
+		w.compact = nil
+		w.out = cs.out
+		if err := writeRawChars(cs.buf.Bytes(), w.out); err != nil {
+			return err
+		}
+	} else if w.opts&TextWriterPretty != 0 && w.needsSeparator {
+		if err := w.writeNewline(len(w.ctx.arr) - 1); err != nil {
+			return err
+		}
+	}
+
 	if err := writeRawChar(c, w.out); err != nil {
 		return err
 	}
 
 	w.clear()
 	w.ctx.pop()
-	w.endValue()
 
+	return w.endValue()
+}
+
+// writePrettySeparator writes whatever comes before a value when pretty-printing:
+// a comma (between elements of lists and structs), then a newline and indentation,
+// or a single space if writing a list or sexp compactly.
+func (w *textWriter) writePrettySeparator() error {
+	c := w.ctx.peek()
+	if c == ctxAtTopLevel {
+		if w.needsSeparator {
+			return writeRawChar('\n', w.out)
+		}
+		return nil
+	}
+
+	if w.needsSeparator && (c != ctxInSexp || w.json()) {
+		if err := writeRawChar(',', w.out); err != nil {
+			return err
+		}
+	}
+
+	if w.compact != nil {
+		if w.needsSeparator {
+			if err := writeRawChar(' ', w.out); err != nil {
+				return err
+			}
+		}
+		w.compact.start = w.compact.buf.Len()
+		return nil
+	}
+
+	return w.writeNewline(len(w.ctx.arr))
+}
+
+// writeNewline writes a newline followed by indentation for the given depth.
+func (w *textWriter) writeNewline(depth int) error {
+	if err := writeRawChar('\n', w.out); err != nil {
+		return err
+	}
+	return writeRawString(strings.Repeat(w.indent, depth), w.out)
+}
+
+// expand gives up on writing the current list or sexp compactly, writing out the
+// elements buffered so far one per line instead.
+func (w *textWriter) expand() error {
+	cs := w.compact
+	w.compact = nil
+	w.out = cs.out
+
+	if err := writeRawChar(cs.open, w.out); err != nil {
+		return err
+	}
+
+	buf := cs.buf.Bytes()
+	depth := len(w.ctx.arr)
+	for i := range cs.starts {
+		if i > 0 && cs.open != '(' {
+			if err := writeRawChar(',', w.out); err != nil {
+				return err
+			}
+		}
+		if err := w.writeNewline(depth); err != nil {
+			return err
+		}
+		if err := writeRawChars(buf[cs.starts[i]:cs.ends[i]], w.out); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeLongString writes a string as a sequence of long-string segments, one per
+// line, breaking after newlines and (if possible) between words.
+func (w *textWriter) writeLongString(val string) error {
+	depth := len(w.ctx.arr)
+	for i, seg := range splitLongString(val, w.width-w.cols.col-6) {
+		if i > 0 {
+			if err := w.writeNewline(depth); err != nil {
+				return err
+			}
+		}
+		if err := writeRawString("'''", w.out); err != nil {
+			return err
+		}
+		if err := writeEscapedLongString(seg, w.out); err != nil {
+			return err
+		}
+		if err := writeRawString("'''", w.out); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// splitLongString splits a string into segments of at most max runes, ending each
+// after a newline or, if one fits, a space.
+func splitLongString(s string, max int) []string {
+	if max < 20 {
+		// Not much room; let it overflow rather than chopping it to bits.
+		max = 20
+	}
+
+	segs := []string{}
+	for len(s) > 0 {
+		end, space, n := len(s), -1, 0
+		for i, r := range s {
+			if n == max {
+				end = i
+				if space > 0 {
+					end = space
+				}
+				break
+			}
+			if r == '\n' {
+				end = i + 1
+				break
+			}
+			if r == ' ' {
+				space = i + 1
+			}
+			n++
+		}
+
+		segs = append(segs, s[:end])
+		s = s[end:]
+	}
+	return segs
+}
+
+// A compactSeq is a list or sexp being written on a single line. Its elements are
+// buffered until it ends, in case they turn out not to fit.
+type compactSeq struct {
+	out  io.Writer
+	buf  bytes.Buffer
+	col  int
+	open byte
+
+	start  int
+	starts []int
+	ends   []int
+}
+
+// A columnWriter keeps track of the column the output is currently at.
+type columnWriter struct {
+	out io.Writer
+	col int
+}
+
+func (c *columnWriter) Write(p []byte) (int, error) {
+	n, err := c.out.Write(p)
+	if i := bytes.LastIndexByte(p[:n], '\n'); i >= 0 {
+		c.col = utf8.RuneCount(p[i+1 : n])
+	} else {
+		c.col += utf8.RuneCount(p[:n])
+	}
+	return n, err
+}
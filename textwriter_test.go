@@ -1,6 +1,7 @@
 package ion
 
 import (
+	"encoding/json"
 	"math"
 	"math/big"
 	"strings"
@@ -349,3 +350,168 @@ func writeText(f func(Writer)) string {
 
 	return buf.String()
 }
+
+func TestWriteTextSymbolTokens(t *testing.T) {
+	expected := "{'$123':'$456'::'$789',$10:$0::$11}"
+	testTextWriter(t, expected, func(w Writer) {
+		w.BeginStruct()
+
+		w.FieldNameSymbol(NewSymbolToken("$123"))
+		w.AnnotationSymbols(NewSymbolToken("$456"))
+		w.WriteSymbolToken(NewSymbolToken("$789"))
+
+		w.FieldNameSymbol(NewSymbolTokenSID(10))
+		w.AnnotationSymbols(SymbolToken{LocalSID: SymbolIDUnknown, Source: &ImportSource{"bogus", 1}})
+		w.WriteSymbolToken(NewSymbolTokenSID(11))
+
+		w.EndStruct()
+	})
+}
+
+func TestWriteTextPretty(t *testing.T) {
+	test := func(opts TextWriterOpts, in, eval string) {
+		t.Run(in, func(t *testing.T) {
+			buf := strings.Builder{}
+			w := NewTextWriterIndent(&buf, opts|TextWriterQuietFinish, "  ", 30)
+			for _, v := range readValuesStr(t, in) {
+				if err := v.WriteTo(w); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Finish(); err != nil {
+				t.Fatal(err)
+			}
+
+			if buf.String() != eval {
+				t.Errorf("expected:\n%v\ngot:\n%v", eval, buf.String())
+			}
+
+			// Whatever the layout, it should mean the same thing.
+			evals := readValuesStr(t, in)
+			vals := readValuesStr(t, buf.String())
+			if len(vals) != len(evals) {
+				t.Fatalf("expected %v values, got %v", len(evals), len(vals))
+			}
+			for i := range evals {
+				if !evals[i].Equal(vals[i]) {
+					t.Errorf("expected %v, got %v", evals[i], vals[i])
+				}
+			}
+		})
+	}
+
+	test(0, "1 {} [] ()", "1\n{}\n[]\n()")
+	test(0, "a::{b:1,c:[d,(e f)]}", "a::{\n  b: 1,\n  c: [\n    d,\n    (\n      e\n      f\n    )\n  ]\n}")
+
+	compact := TextWriterCompactLists
+	test(compact, "{a:[1,2,3],b:(x y)}", "{\n  a: [1, 2, 3],\n  b: (x y)\n}")
+	test(compact, "[[1,2],3]", "[\n  [1, 2],\n  3\n]")
+	test(compact, "{a:[1,{b:2}]}", "{\n  a: [\n    1,\n    {\n      b: 2\n    }\n  ]\n}")
+	test(compact, "[aaaaaaaaaa,bbbbbbbbbb,cccccccccc]", "[\n  aaaaaaaaaa,\n  bbbbbbbbbb,\n  cccccccccc\n]")
+
+	wrap := TextWriterWrapStrings
+	test(wrap, `{s:"short"}`, "{\n  s: \"short\"\n}")
+	test(wrap, `{s:"the quick brown fox jumps over the lazy dog"}`,
+		"{\n  s: '''the quick brown fox '''\n  '''jumps over the lazy '''\n  '''dog'''\n}")
+	test(wrap, `["it's split\nat newlines, and also at spaces"]`,
+		"[\n  '''it\\'s split\\n'''\n  '''at newlines, and also '''\n  '''at spaces'''\n]")
+	test(wrap, `"the quick brown fox jumps over the lazy dog"`, `"the quick brown fox jumps over the lazy dog"`)
+}
+
+func TestWriteJSON(t *testing.T) {
+	test := func(ion, expected string) {
+		t.Run(ion, func(t *testing.T) {
+			buf := strings.Builder{}
+			w := NewJSONWriter(&buf)
+			if err := Copy(w, NewReaderStr(ion)); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Finish(); err != nil {
+				t.Fatal(err)
+			}
+
+			actual := buf.String()
+			if actual != expected {
+				t.Errorf("expected %v, got %v", expected, actual)
+			}
+			for _, line := range strings.Split(strings.TrimSpace(actual), "\n") {
+				if !json.Valid([]byte(line)) {
+					t.Errorf("invalid JSON: %v", line)
+				}
+			}
+		})
+	}
+
+	test("null null.int null.struct", "null\nnull\nnull\n")
+	test("true 123 -18446744073709551616", "true\n123\n-18446744073709551616\n")
+	test("1.5e0 nan +inf -inf", "1.5e+0\nnull\nnull\nnull\n")
+	test("1. 1.50 -12d3 1.2d-20", "1\n1.50\n-12e3\n1.2e-20\n")
+	test("2001-02-03T04:05:06.789Z 2001T", "\"2001-02-03T04:05:06.789Z\"\n\"2001T\"\n")
+	test("foo 'hello world' $ion", "\"foo\"\n\"hello world\"\n\"$ion\"\n")
+	test(`"a\"b\\c\nd\x01"`, `"a\"b\\c\nd\u0001"`+"\n")
+	test(`{{"hi\x01"}} {{aGk=}}`, "\"hi\\u0001\"\n\"aGk=\"\n")
+	test("a::b::[1, (+ 2 3), x::{}]", "[1,[\"+\",2,3],{}]\n")
+	test("{a:1, 'b c':{d:null.list}, '\"':e::f}", "{\"a\":1,\"b c\":{\"d\":null},\"\\\"\":\"f\"}\n")
+}
+
+func TestWriteJSONClob(t *testing.T) {
+	buf := strings.Builder{}
+	w := NewJSONWriter(&buf)
+	w.WriteClob([]byte{'h', 'i', 0xFF})
+	if err := w.Finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "\"hi\u00ff\"\n"; buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestWriteJSONPretty(t *testing.T) {
+	buf := strings.Builder{}
+	w := NewTextWriterIndent(&buf, TextWriterJSON|TextWriterCompactLists|TextWriterQuietFinish, "  ", 80)
+	if err := Copy(w, NewReaderStr("{a:(b c), d:x::[{e:1}]}")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{
+  "a": ["b", "c"],
+  "d": [
+    {
+      "e": 1
+    }
+  ]
+}`
+	if buf.String() != expected {
+		t.Errorf("expected %v, got %v", expected, buf.String())
+	}
+	if !json.Valid([]byte(buf.String())) {
+		t.Error("invalid JSON")
+	}
+}
+
+func TestEncodeJSON(t *testing.T) {
+	type item struct {
+		Name  string
+		Price *Decimal
+		When  time.Time `ion:"when"`
+	}
+
+	buf := strings.Builder{}
+	e := NewEncoder(NewJSONWriter(&buf))
+	err := e.Encode(item{"widget", MustParseDecimal("12.50"), time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"Name":"widget","Price":12.50,"when":"2020-01-02T00:00:00Z"}` + "\n"
+	if buf.String() != expected {
+		t.Errorf("expected %v, got %v", expected, buf.String())
+	}
+}
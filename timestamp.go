@@ -0,0 +1,469 @@
+package ion
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimestampPrecision is the precision of an Ion timestamp: the last of its
+// components that is explicitly specified.
+type TimestampPrecision uint8
+
+const (
+	// TimestampPrecisionYear is the precision of a timestamp like 2020T.
+	TimestampPrecisionYear TimestampPrecision = iota + 1
+	// TimestampPrecisionMonth is the precision of a timestamp like 2020-05T.
+	TimestampPrecisionMonth
+	// TimestampPrecisionDay is the precision of a timestamp like 2020-05-01.
+	TimestampPrecisionDay
+	// TimestampPrecisionMinute is the precision of a timestamp like 2020-05-01T12:34Z.
+	TimestampPrecisionMinute
+	// TimestampPrecisionSecond is the precision of a timestamp like 2020-05-01T12:34:56Z.
+	TimestampPrecisionSecond
+	// TimestampPrecisionFraction is the precision of a timestamp with fractional seconds,
+	// like 2020-05-01T12:34:56.789Z.
+	TimestampPrecisionFraction
+)
+
+// String implements fmt.Stringer for TimestampPrecision.
+func (p TimestampPrecision) String() string {
+	switch p {
+	case TimestampPrecisionYear:
+		return "year"
+	case TimestampPrecisionMonth:
+		return "month"
+	case TimestampPrecisionDay:
+		return "day"
+	case TimestampPrecisionMinute:
+		return "minute"
+	case TimestampPrecisionSecond:
+		return "second"
+	case TimestampPrecisionFraction:
+		return "fraction"
+	default:
+		return fmt.Sprintf("<unknown precision %v>", uint8(p))
+	}
+}
+
+// A Timestamp is an Ion timestamp. Unlike a time.Time, it remembers its precision,
+// carries an arbitrary number of fractional-second digits, and distinguishes a
+// known UTC offset (Z or +00:00) from an unknown one (-00:00), so it can be
+// round-tripped through a Reader and Writer without changing its value.
+type Timestamp struct {
+	// t holds the timestamp's fields in its local (offset) time zone. It is
+	// in UTC if the offset is unknown.
+	t           time.Time
+	precision   TimestampPrecision
+	fraction    *Decimal
+	offsetKnown bool
+}
+
+// NewTimestamp creates a new timestamp from the given time, truncated to the given
+// precision. Timestamps with minute precision or finer have a known offset taken
+// from t's zone; fraction-precision timestamps have nine digits of fractional seconds.
+// Timestamps with day precision or coarser have no offset and take their date from
+// t's zone.
+func NewTimestamp(t time.Time, precision TimestampPrecision) *Timestamp {
+	return newTimestamp(t, precision, true)
+}
+
+// NewTimestampUnknownOffset creates a new timestamp from the given time, truncated
+// to the given precision, with an unknown (-00:00) offset. The fields of the timestamp
+// are taken from t's zone.
+func NewTimestampUnknownOffset(t time.Time, precision TimestampPrecision) *Timestamp {
+	return newTimestamp(t, precision, false)
+}
+
+func newTimestamp(t time.Time, precision TimestampPrecision, offsetKnown bool) *Timestamp {
+	if precision < TimestampPrecisionYear || precision > TimestampPrecisionFraction {
+		panic(fmt.Sprintf("invalid timestamp precision %v", precision))
+	}
+
+	year, month, day := t.Date()
+	hour, min, sec := t.Clock()
+	nsec := t.Nanosecond()
+
+	if precision < TimestampPrecisionMonth {
+		month = 1
+	}
+	if precision < TimestampPrecisionDay {
+		day = 1
+	}
+	if precision < TimestampPrecisionMinute {
+		hour, min = 0, 0
+		offsetKnown = false
+	}
+	if precision < TimestampPrecisionSecond {
+		sec = 0
+	}
+	if precision < TimestampPrecisionFraction {
+		nsec = 0
+	}
+
+	loc := time.UTC
+	if offsetKnown {
+		_, offset := t.Zone()
+		loc = offsetZone(offset / 60)
+	}
+
+	ts := &Timestamp{
+		t:           time.Date(year, month, day, hour, min, sec, nsec, loc),
+		precision:   precision,
+		offsetKnown: offsetKnown,
+	}
+	if precision == TimestampPrecisionFraction {
+		ts.fraction = NewDecimal(big.NewInt(int64(nsec)), -9)
+	}
+	return ts
+}
+
+// MustParseTimestamp parses the given string into a timestamp, panicing on error.
+func MustParseTimestamp(in string) *Timestamp {
+	ts, err := ParseTimestamp(in)
+	if err != nil {
+		panic(err)
+	}
+	return ts
+}
+
+// ParseTimestamp parses the given string, in Ion text format, into a timestamp.
+func ParseTimestamp(in string) (*Timestamp, error) {
+	if len(in) < 5 {
+		return nil, invalidTimestamp(in)
+	}
+
+	fields := [6]int{0, 1, 1, 0, 0, 0}
+	precision := TimestampPrecisionYear
+
+	field := func(i, idx int) bool {
+		if idx+2 > len(in) {
+			return false
+		}
+		val, err := strconv.Atoi(in[idx : idx+2])
+		if err != nil || !isDigit(int(in[idx])) || !isDigit(int(in[idx+1])) {
+			return false
+		}
+		fields[i] = val
+		return true
+	}
+
+	year, err := strconv.Atoi(in[:4])
+	if err != nil || !isDigit(int(in[0])) {
+		return nil, invalidTimestamp(in)
+	}
+	fields[0] = year
+
+	// The date part of the timestamp: yyyyT, yyyy-mmT, yyyy-mm-dd, or yyyy-mm-ddT.
+	i := 4
+	switch {
+	case isTimestampT(in, i) && len(in) == i+1:
+		return makeTimestamp(in, fields, precision, nil, 0, false)
+
+	case in[i] == '-' && field(1, i+1):
+		precision = TimestampPrecisionMonth
+		i += 3
+
+	default:
+		return nil, invalidTimestamp(in)
+	}
+
+	switch {
+	case isTimestampT(in, i) && len(in) == i+1:
+		return makeTimestamp(in, fields, precision, nil, 0, false)
+
+	case i < len(in) && in[i] == '-' && field(2, i+1):
+		precision = TimestampPrecisionDay
+		i += 3
+
+	default:
+		return nil, invalidTimestamp(in)
+	}
+
+	if len(in) == i || (isTimestampT(in, i) && len(in) == i+1) {
+		return makeTimestamp(in, fields, precision, nil, 0, false)
+	}
+	if !isTimestampT(in, i) {
+		return nil, invalidTimestamp(in)
+	}
+	i++
+
+	// The time part of the timestamp: hh:mm, hh:mm:ss, or hh:mm:ss.fff.
+	if !field(3, i) || i+2 >= len(in) || in[i+2] != ':' || !field(4, i+3) {
+		return nil, invalidTimestamp(in)
+	}
+	precision = TimestampPrecisionMinute
+	i += 5
+
+	var fraction *Decimal
+	if i < len(in) && in[i] == ':' {
+		if !field(5, i+1) {
+			return nil, invalidTimestamp(in)
+		}
+		precision = TimestampPrecisionSecond
+		i += 3
+
+		if i < len(in) && in[i] == '.' {
+			j := i + 1
+			for j < len(in) && isDigit(int(in[j])) {
+				j++
+			}
+			if j == i+1 {
+				return nil, invalidTimestamp(in)
+			}
+
+			coef, _ := new(big.Int).SetString(in[i+1:j], 10)
+			fraction = NewDecimal(coef, int32(i+1-j))
+			precision = TimestampPrecisionFraction
+			i = j
+		}
+	}
+
+	// The offset: Z, -00:00 (unknown), or +-hh:mm.
+	offset, offsetKnown, ok := parseTimestampOffset(in[i:])
+	if !ok {
+		return nil, invalidTimestamp(in)
+	}
+
+	return makeTimestamp(in, fields, precision, fraction, offset, offsetKnown)
+}
+
+// isTimestampT returns true if in[i] is a T separator.
+func isTimestampT(in string, i int) bool {
+	return i < len(in) && (in[i] == 'T' || in[i] == 't')
+}
+
+// parseTimestampOffset parses the offset suffix of a timestamp, in minutes.
+func parseTimestampOffset(in string) (int, bool, bool) {
+	if in == "Z" || in == "z" {
+		return 0, true, true
+	}
+	if len(in) != 6 || (in[0] != '+' && in[0] != '-') || in[3] != ':' {
+		return 0, false, false
+	}
+	for _, i := range []int{1, 2, 4, 5} {
+		if !isDigit(int(in[i])) {
+			return 0, false, false
+		}
+	}
+
+	hours, _ := strconv.Atoi(in[1:3])
+	mins, _ := strconv.Atoi(in[4:6])
+	if hours > 23 || mins > 59 {
+		return 0, false, false
+	}
+
+	offset := hours*60 + mins
+	if in[0] == '-' {
+		if offset == 0 {
+			// -00:00 means the offset is unknown.
+			return 0, false, true
+		}
+		offset = -offset
+	}
+	return offset, true, true
+}
+
+// makeTimestamp validates a set of parsed timestamp fields and turns them in to a Timestamp.
+func makeTimestamp(in string, fields [6]int, precision TimestampPrecision, fraction *Decimal, offset int, offsetKnown bool) (*Timestamp, error) {
+	ts, err := newTimestampFields(fields, precision, fraction, offset, offsetKnown)
+	if err != nil {
+		return nil, invalidTimestamp(in)
+	}
+	return ts, nil
+}
+
+// newTimestampFields creates a timestamp from its local fields, returning an error if they
+// are out of range.
+func newTimestampFields(fields [6]int, precision TimestampPrecision, fraction *Decimal, offset int, offsetKnown bool) (*Timestamp, error) {
+	year, month, day := fields[0], fields[1], fields[2]
+	hour, min, sec := fields[3], fields[4], fields[5]
+
+	if year < 1 || year > 9999 || month < 1 || month > 12 || day < 1 ||
+		hour > 23 || min > 59 || sec > 59 {
+		return nil, fmt.Errorf("ion: timestamp field out of range")
+	}
+	if day > time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day() {
+		return nil, fmt.Errorf("ion: timestamp day out of range")
+	}
+
+	nsec := 0
+	if fraction != nil {
+		var err error
+		if nsec, err = fractionNanos(fraction); err != nil {
+			return nil, err
+		}
+	}
+
+	if precision < TimestampPrecisionMinute {
+		offsetKnown = false
+	}
+
+	loc := time.UTC
+	if offsetKnown {
+		loc = offsetZone(offset)
+	}
+
+	return &Timestamp{
+		t:           time.Date(year, time.Month(month), day, hour, min, sec, nsec, loc),
+		precision:   precision,
+		fraction:    fraction,
+		offsetKnown: offsetKnown,
+	}, nil
+}
+
+// fractionNanos validates a fractional-seconds decimal, returning it truncated to
+// nanoseconds.
+func fractionNanos(fraction *Decimal) (int, error) {
+	if fraction.scale <= 0 || fraction.Sign() < 0 {
+		return 0, fmt.Errorf("ion: invalid timestamp fraction: %v", fraction)
+	}
+
+	nsec, err := fraction.ShiftL(9).Trunc()
+	if err != nil || nsec > 999999999 {
+		return 0, fmt.Errorf("ion: invalid timestamp fraction: %v", fraction)
+	}
+
+	return int(nsec), nil
+}
+
+// offsetZone returns a time.Location for the given offset in minutes.
+func offsetZone(offset int) *time.Location {
+	if offset == 0 {
+		return time.UTC
+	}
+	return time.FixedZone("", offset*60)
+}
+
+func invalidTimestamp(val string) error {
+	return fmt.Errorf("ion: invalid timestamp: %v", val)
+}
+
+// WithFraction returns a copy of this timestamp with fraction precision and the given
+// fractional seconds, which must be at least zero, less than one, and have a negative
+// exponent. The number of digits after the decimal point is preserved, so 0.500 and
+// 0.5 result in different timestamps.
+func (ts *Timestamp) WithFraction(fraction *Decimal) (*Timestamp, error) {
+	if ts.precision < TimestampPrecisionSecond {
+		return nil, fmt.Errorf("ion: cannot add a fraction to a timestamp with %v precision", ts.precision)
+	}
+
+	nsec, err := fractionNanos(fraction)
+	if err != nil {
+		return nil, err
+	}
+
+	t := ts.t
+	return &Timestamp{
+		t:           time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), nsec, t.Location()),
+		precision:   TimestampPrecisionFraction,
+		fraction:    fraction,
+		offsetKnown: ts.offsetKnown,
+	}, nil
+}
+
+// Time returns this timestamp as a time.Time. Fractional seconds beyond nanosecond
+// precision are truncated. Timestamps with an unknown offset are returned in UTC.
+func (ts *Timestamp) Time() time.Time {
+	return ts.t
+}
+
+// Precision returns the precision of this timestamp.
+func (ts *Timestamp) Precision() TimestampPrecision {
+	return ts.precision
+}
+
+// Fraction returns the fractional seconds of this timestamp, or nil if its precision
+// is coarser than TimestampPrecisionFraction.
+func (ts *Timestamp) Fraction() *Decimal {
+	return ts.fraction
+}
+
+// Offset returns this timestamp's offset from UTC in minutes. It returns zero if the
+// offset is unknown.
+func (ts *Timestamp) Offset() int {
+	_, offset := ts.t.Zone()
+	return offset / 60
+}
+
+// OffsetKnown returns true if this timestamp has a known offset. It returns false for
+// timestamps with an unknown (-00:00) offset and those with day precision or coarser.
+func (ts *Timestamp) OffsetKnown() bool {
+	return ts.offsetKnown
+}
+
+// Equal returns true if two timestamps are equivalent according to the Ion data
+// model: they must represent the same instant with the same precision, offset, and
+// number of fractional-second digits.
+func (ts *Timestamp) Equal(o *Timestamp) bool {
+	if ts.precision != o.precision || ts.offsetKnown != o.offsetKnown || ts.Offset() != o.Offset() {
+		return false
+	}
+	if !ts.t.Equal(o.t) {
+		return false
+	}
+	if ts.fraction != nil {
+		return ts.fraction.scale == o.fraction.scale && ts.fraction.n.Cmp(o.fraction.n) == 0
+	}
+	return true
+}
+
+// UTCFields returns the year, month, day, hour, minute, and second fields of this
+// timestamp, adjusted to UTC as they are encoded in binary Ion.
+func (ts *Timestamp) utcFields() [6]int {
+	t := ts.t.In(time.UTC)
+	return [6]int{t.Year(), int(t.Month()), t.Day(), t.Hour(), t.Minute(), t.Second()}
+}
+
+// String formats the timestamp as a string in Ion text format.
+func (ts *Timestamp) String() string {
+	t := ts.t
+	b := strings.Builder{}
+
+	b.WriteString(fmt.Sprintf("%04d", t.Year()))
+	if ts.precision == TimestampPrecisionYear {
+		b.WriteString("T")
+		return b.String()
+	}
+
+	b.WriteString(fmt.Sprintf("-%02d", int(t.Month())))
+	if ts.precision == TimestampPrecisionMonth {
+		b.WriteString("T")
+		return b.String()
+	}
+
+	b.WriteString(fmt.Sprintf("-%02d", t.Day()))
+	if ts.precision == TimestampPrecisionDay {
+		return b.String()
+	}
+
+	b.WriteString(fmt.Sprintf("T%02d:%02d", t.Hour(), t.Minute()))
+	if ts.precision >= TimestampPrecisionSecond {
+		b.WriteString(fmt.Sprintf(":%02d", t.Second()))
+	}
+	if ts.precision == TimestampPrecisionFraction {
+		digits := ts.fraction.n.String()
+		b.WriteString(".")
+		b.WriteString(strings.Repeat("0", int(ts.fraction.scale)-len(digits)))
+		b.WriteString(digits)
+	}
+
+	offset := ts.Offset()
+	switch {
+	case !ts.offsetKnown:
+		b.WriteString("-00:00")
+	case offset == 0:
+		b.WriteString("Z")
+	default:
+		sign := '+'
+		if offset < 0 {
+			sign = '-'
+			offset = -offset
+		}
+		b.WriteString(fmt.Sprintf("%c%02d:%02d", sign, offset/60, offset%60))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,196 @@
+package ion
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestParseTimestampPrecision(t *testing.T) {
+	test := func(str string, eprec TimestampPrecision, eknown bool, eoffset int) {
+		t.Run(str, func(t *testing.T) {
+			ts, err := ParseTimestamp(str)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ts.Precision() != eprec {
+				t.Errorf("expected precision %v, got %v", eprec, ts.Precision())
+			}
+			if ts.OffsetKnown() != eknown {
+				t.Errorf("expected offsetKnown=%v, got %v", eknown, ts.OffsetKnown())
+			}
+			if ts.Offset() != eoffset {
+				t.Errorf("expected offset %v, got %v", eoffset, ts.Offset())
+			}
+		})
+	}
+
+	test("2020T", TimestampPrecisionYear, false, 0)
+	test("2020-05T", TimestampPrecisionMonth, false, 0)
+	test("2020-05-01", TimestampPrecisionDay, false, 0)
+	test("2020-05-01T", TimestampPrecisionDay, false, 0)
+	test("2020-05-01T00:00Z", TimestampPrecisionMinute, true, 0)
+	test("2020-05-01T00:00-00:00", TimestampPrecisionMinute, false, 0)
+	test("2020-05-01T00:00:00+01:30", TimestampPrecisionSecond, true, 90)
+	test("2020-05-01T00:00:00.000-08:00", TimestampPrecisionFraction, true, -480)
+}
+
+func TestParseTimestampErrors(t *testing.T) {
+	test := func(str string) {
+		t.Run(str, func(t *testing.T) {
+			if _, err := ParseTimestamp(str); err == nil {
+				t.Errorf("expected error parsing %v", str)
+			}
+		})
+	}
+
+	test("2020")
+	test("0000T")
+	test("2020-13T")
+	test("2020-02-30")
+	test("2020-05-01T12Z")
+	test("2020-05-01T12:00")
+	test("2020-05-01T24:00Z")
+	test("2020-05-01T12:00:60Z")
+	test("2020-05-01T12:00:00.Z")
+	test("2020-05-01T12:00+24:00")
+	test("2020-05-01T12:00Zoops")
+}
+
+func TestTimestampString(t *testing.T) {
+	test := func(str string) {
+		t.Run(str, func(t *testing.T) {
+			ts := MustParseTimestamp(str)
+			if ts.String() != str {
+				t.Errorf("expected %v, got %v", str, ts.String())
+			}
+		})
+	}
+
+	test("2020T")
+	test("2020-05T")
+	test("2020-05-01")
+	test("2020-05-01T12:34Z")
+	test("2020-05-01T12:34-00:00")
+	test("2020-05-01T12:34:56+09:10")
+	test("2020-05-01T12:34:56.0Z")
+	test("2020-05-01T12:34:56.000-00:00")
+	test("2020-05-01T12:34:56.123456789012345-23:59")
+	test("0001-01-01T00:00:00.000001Z")
+}
+
+func TestTimestampEqual(t *testing.T) {
+	test := func(a, b string, eq bool) {
+		t.Run(a+"="+b, func(t *testing.T) {
+			if MustParseTimestamp(a).Equal(MustParseTimestamp(b)) != eq {
+				t.Errorf("expected %v.Equal(%v) = %v", a, b, eq)
+			}
+		})
+	}
+
+	test("2020T", "2020T", true)
+	test("2020T", "2020-01T", false)
+	test("2020-01-01", "2020-01-01T", true)
+	test("2020-01-01T00:00Z", "2020-01-01T00:00+00:00", true)
+	test("2020-01-01T00:00Z", "2020-01-01T00:00-00:00", false)
+	test("2020-01-01T00:00Z", "2020-01-01T01:00+01:00", false)
+	test("2020-01-01T00:00:00.5Z", "2020-01-01T00:00:00.50Z", false)
+	test("2020-01-01T00:00:00.0000000001Z", "2020-01-01T00:00:00.0000000002Z", false)
+}
+
+func TestNewTimestamp(t *testing.T) {
+	tt := time.Date(2020, 5, 1, 12, 34, 56, 789000000, time.FixedZone("", -8*3600))
+
+	test := func(ts *Timestamp, eval string) {
+		t.Run(eval, func(t *testing.T) {
+			if ts.String() != eval {
+				t.Errorf("expected %v, got %v", eval, ts.String())
+			}
+		})
+	}
+
+	test(NewTimestamp(tt, TimestampPrecisionYear), "2020T")
+	test(NewTimestamp(tt, TimestampPrecisionMonth), "2020-05T")
+	test(NewTimestamp(tt, TimestampPrecisionDay), "2020-05-01")
+	test(NewTimestamp(tt, TimestampPrecisionMinute), "2020-05-01T12:34-08:00")
+	test(NewTimestamp(tt, TimestampPrecisionSecond), "2020-05-01T12:34:56-08:00")
+	test(NewTimestamp(tt, TimestampPrecisionFraction), "2020-05-01T12:34:56.789000000-08:00")
+	test(NewTimestampUnknownOffset(tt, TimestampPrecisionSecond), "2020-05-01T12:34:56-00:00")
+
+	ts, err := NewTimestamp(tt, TimestampPrecisionSecond).WithFraction(MustParseDecimal("0.7890"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	test(ts, "2020-05-01T12:34:56.7890-08:00")
+
+	if _, err := ts.WithFraction(MustParseDecimal("1.5")); err == nil {
+		t.Error("expected error adding a fraction >= 1")
+	}
+	if _, err := NewTimestamp(tt, TimestampPrecisionDay).WithFraction(MustParseDecimal("0.5")); err == nil {
+		t.Error("expected error adding a fraction to a day-precision timestamp")
+	}
+}
+
+func TestTimestampRoundTrip(t *testing.T) {
+	test := func(str string) {
+		t.Run(str, func(t *testing.T) {
+			ets := MustParseTimestamp(str)
+
+			buf := bytes.Buffer{}
+			w := NewBinaryWriter(&buf)
+			w.WriteIonTimestamp(ets)
+			if err := w.Finish(); err != nil {
+				t.Fatal(err)
+			}
+
+			r := NewReaderBytes(buf.Bytes())
+			_next(t, r, TimestampType)
+
+			ts, err := r.TimestampValue()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ts.Equal(ets) {
+				t.Errorf("expected %v, got %v", ets, ts)
+			}
+
+			text := bytes.Buffer{}
+			tw := NewTextWriterOpts(&text, TextWriterQuietFinish)
+			tw.WriteIonTimestamp(ts)
+			if err := tw.Finish(); err != nil {
+				t.Fatal(err)
+			}
+			if text.String() != str {
+				t.Errorf("expected %v, got %v", str, text.String())
+			}
+		})
+	}
+
+	test("2020T")
+	test("2020-05T")
+	test("2020-05-01")
+	test("2020-05-01T23:59Z")
+	test("2020-05-01T23:59-00:00")
+	test("2020-05-01T23:59:58+10:00")
+	test("2020-05-01T23:59:58.000Z")
+	test("2020-05-01T23:59:58.000000000001-07:30")
+}
+
+func TestDecodeTimestamp(t *testing.T) {
+	var val struct {
+		TS Timestamp
+	}
+	if err := UnmarshalStr("{TS:2020-05-01T12:34:56.0000-00:00}", &val); err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := MarshalText(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	eval := "{TS:2020-05-01T12:34:56.0000-00:00}"
+	if string(text) != eval {
+		t.Errorf("expected %v, got %v", eval, string(text))
+	}
+}
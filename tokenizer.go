@@ -115,6 +115,27 @@ type tokenizer struct {
 	token      token
 	unfinished bool
 	pos        uint64
+	start      uint64
+
+	// The number of newlines read so far, and the offsets at which the current
+	// and (a few) previous lines started, so they can be unread.
+	line       int
+	lineStart  uint64
+	lineStarts []uint64
+
+	// The number of bytes taken up by the line breaks before those lines: one, or
+	// two for \r\n. Offsets count bytes of input, so \r\n counts as two.
+	lineBreaks []uint64
+
+	// Where the whitespace and comments skipped most recently started and ended,
+	// so they can be left out of the end of the value before them.
+	triviaStart uint64
+	triviaEnd   uint64
+
+	// The characters read since ContainerEnd started skipping ahead, which it
+	// puts back when it's done.
+	skipped  []int
+	skipping bool
 }
 
 func tokenizeString(in string) *tokenizer {
@@ -140,6 +161,65 @@ func (t *tokenizer) Pos() uint64 {
 	return t.pos
 }
 
+// Start returns the offset at which the current token started.
+func (t *tokenizer) Start() uint64 {
+	return t.start
+}
+
+// ValueEnd returns the offset just past the value that was just read, leaving out
+// any whitespace and comments skipped after it while looking for what comes next.
+func (t *tokenizer) ValueEnd() uint64 {
+	if t.pos == t.triviaEnd {
+		return t.triviaStart
+	}
+	return t.pos
+}
+
+// ContainerEnd returns the offset just past the end of the container of the given
+// type whose opening token was just read. It skips ahead to find it, then puts back
+// everything it skipped, so the container can still be stepped in to.
+func (t *tokenizer) ContainerEnd(typ Type) (uint64, error) {
+	saved := *t
+	saved.buffer = append([]int(nil), t.buffer...)
+	saved.lineStarts = append([]uint64(nil), t.lineStarts...)
+	saved.lineBreaks = append([]uint64(nil), t.lineBreaks...)
+
+	t.skipped = nil
+	t.skipping = true
+	err := t.SkipContainerContents(typ)
+	end := t.pos
+
+	// Anything still in the buffer comes after what we skipped; the buffer's
+	// read from the end, so put the skipped characters there in reverse.
+	buffer := t.buffer
+	for i := len(t.skipped) - 1; i >= 0; i-- {
+		buffer = append(buffer, t.skipped[i])
+	}
+
+	*t = saved
+	t.buffer = buffer
+
+	if err != nil {
+		return 0, err
+	}
+	return end, nil
+}
+
+// LineCol returns the one-based line and column of the given offset, which must
+// be on the current line or one of the last few lines read.
+func (t *tokenizer) LineCol(off uint64) (int, int) {
+	line, start := t.line, t.lineStart
+	for i := len(t.lineStarts) - 1; off < start && i >= 0; i-- {
+		line--
+		start = t.lineStarts[i]
+	}
+	if off < start {
+		// Too far back to say.
+		return line + 1, 1
+	}
+	return line + 1, int(off-start) + 1
+}
+
 // Next advances to the next token in the input stream.
 func (t *tokenizer) Next() error {
 	var c int
@@ -155,6 +235,8 @@ func (t *tokenizer) Next() error {
 		return err
 	}
 
+	t.start = t.pos - 1
+
 	switch {
 	case c == -1:
 		return t.ok(tokenEOF, true)
@@ -379,7 +461,7 @@ func (t *tokenizer) ReadNumber() (string, Type, error) {
 
 	if first == '0' {
 		if w.Len()-oldlen > 1 {
-			return "", NoType, &SyntaxError{"invalid leading zeroes", t.pos - 1}
+			return "", NoType, &SyntaxError{Msg: "invalid leading zeroes", Offset: t.pos - 1}
 		}
 	}
 
@@ -665,7 +747,7 @@ func (t *tokenizer) readEscapedChar(clob bool) (rune, error) {
 		return t.readHexEscapeSeq(2)
 	}
 
-	return 0, &SyntaxError{fmt.Sprintf("bad escape sequence '\\%c'", c), t.pos - 2}
+	return 0, &SyntaxError{Msg: fmt.Sprintf("bad escape sequence '\\%c'", c), Offset: t.pos - 2}
 }
 
 func (t *tokenizer) readHexEscapeSeq(len int) (rune, error) {
@@ -1150,9 +1232,9 @@ func (t *tokenizer) expect(f matcher) error {
 // unexpected.
 func (t *tokenizer) invalidChar(c int) error {
 	if c == -1 {
-		return &UnexpectedEOFError{t.pos - 1}
+		return &UnexpectedEOFError{Offset: t.pos - 1}
 	}
-	return &UnexpectedRuneError{rune(c), t.pos - 1}
+	return &UnexpectedRuneError{Rune: rune(c), Offset: t.pos - 1}
 }
 
 // SkipN skips over the next n bytes of input. Presumably you've
@@ -1224,7 +1306,50 @@ func (t *tokenizer) peek() (int, error) {
 // returned as (-1, nil) rather than (0, io.EOF), because I find it
 // easier to reason about that way. Newlines are normalized to '\n'.
 func (t *tokenizer) read() (int, error) {
+	c, width, err := t.readByte()
+	if c == '\n' {
+		t.lineStarts = append(t.lineStarts, t.lineStart)
+		t.lineBreaks = append(t.lineBreaks, width)
+		if len(t.lineStarts) > maxUnreadLines {
+			t.lineStarts = t.lineStarts[1:]
+			t.lineBreaks = t.lineBreaks[1:]
+		}
+		t.line++
+		t.lineStart = t.pos
+	}
+	return c, err
+}
+
+// We never unread more than a handful of characters, so there's no need to
+// remember where every line started.
+const maxUnreadLines = 8
+
+// A crlf stands for a \r\n line break in the buffer, which reads as a single '\n'
+// but takes up two bytes of input.
+const crlf = -2
+
+// ReadByte does the actual work of reading a byte of input for read, returning the
+// number of bytes of input it took up.
+func (t *tokenizer) readByte() (int, uint64, error) {
+	c, err := t.readByteHelper()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if t.skipping {
+		t.skipped = append(t.skipped, c)
+	}
+
+	if c == crlf {
+		t.pos += 2
+		return '\n', 2, nil
+	}
 	t.pos++
+	return c, 1, nil
+}
+
+// ReadByteHelper reads a byte of input from the buffer or the underlying reader.
+func (t *tokenizer) readByteHelper() (int, error) {
 	if len(t.buffer) > 0 {
 		// We've already peeked ahead; read from our buffer.
 		c := t.buffer[len(t.buffer)-1]
@@ -1250,6 +1375,7 @@ func (t *tokenizer) read() (int, error) {
 		if len(cs) > 0 && cs[0] == '\n' {
 			// Skip over the '\n' as well.
 			t.in.ReadByte()
+			return crlf, nil
 		}
 		return '\n', nil
 	}
@@ -1260,6 +1386,23 @@ func (t *tokenizer) read() (int, error) {
 // Unread pushes a character (or -1) back into the input stream to
 // be read again later.
 func (t *tokenizer) unread(c int) {
-	t.pos--
+	width := uint64(1)
+	if c == '\n' {
+		t.line--
+		if n := len(t.lineStarts); n > 0 {
+			t.lineStart = t.lineStarts[n-1]
+			t.lineStarts = t.lineStarts[:n-1]
+			width = t.lineBreaks[n-1]
+			t.lineBreaks = t.lineBreaks[:n-1]
+		}
+		if width == 2 {
+			c = crlf
+		}
+	}
+
+	t.pos -= width
+	if t.skipping && len(t.skipped) > 0 {
+		t.skipped = t.skipped[:len(t.skipped)-1]
+	}
 	t.buffer = append(t.buffer, c)
 }
@@ -2,13 +2,14 @@ package ion
 
 import (
 	"bytes"
+	"encoding"
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"math/big"
 	"reflect"
 	"strconv"
-	"strings"
 )
 
 var (
@@ -16,6 +17,14 @@ var (
 	ErrNoInput = errors.New("ion: no input to decode")
 )
 
+// An Unmarshaler is a type that can unmarshal itself from Ion. UnmarshalIon is
+// called with the Reader positioned on the value to be unmarshaled; it may step
+// in to the value if it is a container, but must step back out before returning.
+// Null values are decoded as the zero value without calling UnmarshalIon.
+type Unmarshaler interface {
+	UnmarshalIon(r Reader) error
+}
+
 // Unmarshal unmarshals Ion data to the given object.
 func Unmarshal(data []byte, v interface{}) error {
 	return NewDecoder(NewReader(bytes.NewReader(data))).DecodeTo(v)
@@ -34,15 +43,103 @@ func UnmarshalFrom(r Reader, v interface{}) error {
 	return d.DecodeTo(v)
 }
 
+// UnmarshalCurrent unmarshals the value the given reader is positioned on to the
+// given object. Unlike UnmarshalFrom it doesn't call Next first, so UnmarshalIon
+// methods can use it to fall back to reflection for part of a value. It uses the
+// options of the Decoder calling the UnmarshalIon method, if any.
+func UnmarshalCurrent(r Reader, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr {
+		return errors.New("ion: v must be a pointer")
+	}
+	if rv.IsNil() {
+		return errors.New("ion: v must not be nil")
+	}
+
+	d := Decoder{
+		r:    r,
+		opts: DecoderOptsOf(r),
+	}
+	return d.decodeTo(rv)
+}
+
+// DecoderOptsOf returns the options of the Decoder calling an UnmarshalIon method
+// with the given reader, so that the method can honor them. The options travel with
+// the reader itself, so this works for the Readers created by this package (from
+// bytes, text, or Values) but not for other implementations of Reader, whose
+// UnmarshalIon methods always see 0. It also returns 0 outside of UnmarshalIon.
+func DecoderOptsOf(r Reader) DecoderOpts {
+	if or, ok := r.(optsReader); ok {
+		return or.decoderOpts()
+	}
+	return 0
+}
+
+// An optsReader is a Reader that can carry a Decoder's options to Unmarshalers.
+type optsReader interface {
+	decoderOpts() DecoderOpts
+	setDecoderOpts(opts DecoderOpts) DecoderOpts
+}
+
+// DecoderOpts holds bit-flag options for a Decoder.
+type DecoderOpts uint
+
+const (
+	// DecodeStrictAnnotations instructs the decoder to reject values decoded to an
+	// Annotated type unless their annotations start with the type's annotations.
+	DecodeStrictAnnotations DecoderOpts = 1
+
+	// DecodeDisallowUnknownFields instructs the decoder to reject structs with fields
+	// that don't match any field of the Go struct they're decoded to.
+	DecodeDisallowUnknownFields DecoderOpts = 2
+
+	// DecodeCaseSensitiveFields instructs the decoder to match struct field names
+	// exactly, rather than falling back to a case-insensitive match.
+	DecodeCaseSensitiveFields DecoderOpts = 4
+
+	// DecodeRejectDuplicateFields instructs the decoder to reject structs that set
+	// the same Go struct field or map key more than once.
+	DecodeRejectDuplicateFields DecoderOpts = 8
+
+	// DecodeDisallowLossyNumbers instructs the decoder to reject numeric values that
+	// can't be represented exactly by the Go type they're decoded to, e.g. a float
+	// with more precision than a float32 holds, or, when converting numbers, 1.5 as
+	// an int or a decimal with more precision than a float64 holds. By default, such
+	// values are truncated or rounded to the nearest representable value.
+	DecodeDisallowLossyNumbers DecoderOpts = 16
+
+	// DecodeConvertNumbers instructs the decoder to convert Ion decimals and floats
+	// to Go integer types, and Ion decimals to Go float types. By default, those are
+	// errors, except for decimals decoded to a field tagged with the decimal option.
+	// Combine it with DecodeDisallowLossyNumbers to only allow exact conversions.
+	DecodeConvertNumbers DecoderOpts = 32
+
+	// DecodeUseNumber instructs the decoder to decode ints, decimals and floats to a
+	// Number, rather than an int, big.Int, Decimal or float64, when it's decoding
+	// them to an interface{}.
+	DecodeUseNumber DecoderOpts = 64
+)
+
 // A Decoder decodes go values from an Ion reader.
 type Decoder struct {
-	r Reader
+	r    Reader
+	opts DecoderOpts
+
+	// The struct field being decoded, if any. Like the Encoder, the decoder applies
+	// its tag options through pointers and to the elements of lists.
+	field *field
 }
 
 // NewDecoder creates a new decoder.
 func NewDecoder(r Reader) *Decoder {
+	return NewDecoderOpts(r, 0)
+}
+
+// NewDecoderOpts creates a new decoder with the specified options.
+func NewDecoderOpts(r Reader, opts DecoderOpts) *Decoder {
 	return &Decoder{
-		r: r,
+		r:    r,
+		opts: opts,
 	}
 }
 
@@ -54,8 +151,10 @@ func NewTextDecoder(in io.Reader) *Decoder {
 }
 
 // Decode decodes a value from the underlying Ion reader without any expectations
-// about what it's going to get. Structs become map[string]interface{}s, Lists and
-// Sexps become []interface{}s.
+// about what it's going to get. Structs become map[string]interface{}s, lists
+// become []interface{}s, and sexps, symbols and clobs become Sexps, Symbols and
+// Clobs, so that they encode back to the same types. With DecodeUseNumber, ints,
+// decimals and floats become Numbers.
 func (d *Decoder) Decode() (interface{}, error) {
 	if !d.r.Next() {
 		if d.r.Err() != nil {
@@ -73,6 +172,10 @@ func (d *Decoder) decode() (interface{}, error) {
 		return nil, nil
 	}
 
+	if d.opts&DecodeUseNumber != 0 && isNumber(d.r.Type()) {
+		return d.decodeNumber()
+	}
+
 	switch d.r.Type() {
 	case BoolType:
 		return d.r.BoolValue()
@@ -89,18 +192,30 @@ func (d *Decoder) decode() (interface{}, error) {
 	case TimestampType:
 		return d.r.TimeValue()
 
-	case StringType, SymbolType:
+	case StringType:
 		return d.r.StringValue()
 
-	case BlobType, ClobType:
+	case SymbolType:
+		val, err := d.r.StringValue()
+		return Symbol(val), err
+
+	case BlobType:
 		return d.r.ByteValue()
 
+	case ClobType:
+		val, err := d.r.ByteValue()
+		return Clob(val), err
+
 	case StructType:
 		return d.decodeMap()
 
-	case ListType, SexpType:
+	case ListType:
 		return d.decodeSlice()
 
+	case SexpType:
+		val, err := d.decodeSlice()
+		return Sexp(val), err
+
 	default:
 		panic("wat?")
 	}
@@ -124,6 +239,37 @@ func (d *Decoder) decodeInt() (interface{}, error) {
 	}
 }
 
+// DecodeNumber decodes an Ion int, decimal or float to a Number.
+func (d *Decoder) decodeNumber() (Number, error) {
+	switch d.r.Type() {
+	case IntType:
+		val, err := d.r.BigIntValue()
+		if err != nil {
+			return "", err
+		}
+		return Number(val.String()), nil
+
+	case DecimalType:
+		val, err := d.r.DecimalValue()
+		if err != nil {
+			return "", err
+		}
+		return Number(val.String()), nil
+
+	default:
+		val, err := d.r.FloatValue()
+		if err != nil {
+			return "", err
+		}
+		return Number(formatFloat(val)), nil
+	}
+}
+
+// IsNumber returns true if t is one of the Ion number types.
+func isNumber(t Type) bool {
+	return t == IntType || t == DecimalType || t == FloatType
+}
+
 // DecodeMap decodes an Ion struct to a go map.
 func (d *Decoder) decodeMap() (map[string]interface{}, error) {
 	if err := d.r.StepIn(); err != nil {
@@ -199,12 +345,49 @@ func (d *Decoder) decodeTo(v reflect.Value) error {
 	}
 
 	isNull := d.r.IsNull()
+	if isNull && v.Type() == rawValueType && v.CanAddr() {
+		// Raw values keep nulls, with their types and annotations.
+		t := d.r.Type()
+		if err := v.Addr().Interface().(*RawValue).UnmarshalIon(d.r); err != nil {
+			return d.wrapError(v, t, err)
+		}
+		return nil
+	}
+
 	v = indirect(v, isNull)
 	if isNull {
 		v.Set(reflect.Zero(v.Type()))
 		return nil
 	}
 
+	if d.opts&DecodeStrictAnnotations != 0 {
+		if err := d.checkAnnotations(v); err != nil {
+			return err
+		}
+	}
+
+	if i, ok := implementer(v, unmarshalerType); ok {
+		t := d.r.Type()
+		if err := d.unmarshalIon(i.(Unmarshaler)); err != nil {
+			return d.wrapError(v, t, err)
+		}
+		return nil
+	}
+
+	if v.Type() == numberType || (d.opts&DecodeUseNumber != 0 && v.Kind() == reflect.Interface && v.NumMethod() == 0) {
+		if isNumber(d.r.Type()) {
+			n, err := d.decodeNumber()
+			if err != nil {
+				return err
+			}
+			v.Set(reflect.ValueOf(n))
+			return nil
+		}
+		if v.Type() == numberType {
+			return d.typeError(v)
+		}
+	}
+
 	switch d.r.Type() {
 	case BoolType:
 		return d.decodeBoolTo(v)
@@ -222,13 +405,21 @@ func (d *Decoder) decodeTo(v reflect.Value) error {
 		return d.decodeTimestampTo(v)
 
 	case StringType, SymbolType:
+		if d.field != nil && d.field.str && d.r.Type() == StringType {
+			return d.decodeStringNumberTo(v)
+		}
 		return d.decodeStringTo(v)
 
 	case BlobType, ClobType:
 		return d.decodeLobTo(v)
 
 	case StructType:
-		return d.decodeStructTo(v)
+		// The field's options don't apply to the fields or values of a struct.
+		outer := d.field
+		d.field = nil
+		err := d.decodeStructTo(v)
+		d.field = outer
+		return err
 
 	case ListType, SexpType:
 		return d.decodeSliceTo(v)
@@ -238,6 +429,127 @@ func (d *Decoder) decodeTo(v reflect.Value) error {
 	}
 }
 
+// CheckAnnotations checks that the current value's annotations start with the
+// annotations of the type it's being decoded to, if that type is Annotated.
+func (d *Decoder) checkAnnotations(v reflect.Value) error {
+	want := staticAnnotations(v)
+	got := d.r.Annotations()
+
+	if !hasPrefix(got, want) {
+		msg := fmt.Sprintf("cannot decode value with annotations %v to %v, which requires annotations %v",
+			got, v.Type().String(), want)
+		return d.decodeError(v, msg, nil)
+	}
+	return nil
+}
+
+// UnmarshalIon calls u's UnmarshalIon method, letting it see the decoder's options.
+func (d *Decoder) unmarshalIon(u Unmarshaler) error {
+	if or, ok := d.r.(optsReader); ok {
+		outer := or.setDecoderOpts(d.opts)
+		defer or.setDecoderOpts(outer)
+	}
+	return u.UnmarshalIon(d.r)
+}
+
+// A positionedReader is a Reader that knows where its current value is.
+type positionedReader interface {
+	valuePath() string
+	valueOffset() uint64
+}
+
+// DecodeError returns a DecodeError for the current value, which could not be
+// decoded to v.
+func (d *Decoder) decodeError(v reflect.Value, msg string, err error) *DecodeError {
+	e := &DecodeError{
+		Type:   d.r.Type(),
+		GoType: v.Type(),
+		Msg:    msg,
+		Err:    err,
+	}
+	if p, ok := d.r.(positionedReader); ok {
+		e.Path = p.valuePath()
+		e.Offset = p.valueOffset()
+	}
+	return e
+}
+
+// TypeError returns a DecodeError for a value whose type doesn't match v's.
+func (d *Decoder) typeError(v reflect.Value) error {
+	return d.decodeError(v, "", nil)
+}
+
+// LossyError returns a DecodeError for a value that can't be represented exactly
+// by v, when lossy conversions are disallowed.
+func (d *Decoder) lossyError(v reflect.Value, val interface{}) error {
+	return d.decodeError(v, fmt.Sprintf("value %v can't be represented exactly as type %v", val, v.Type().String()), nil)
+}
+
+// SetInt sets an integer-kinded v to the (truncated) value i of a non-integer
+// value val, checking that it fits.
+func (d *Decoder) setInt(v reflect.Value, i *big.Int, val interface{}) error {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !i.IsInt64() || v.OverflowInt(i.Int64()) {
+			return d.overflowError(v, val)
+		}
+		v.SetInt(i.Int64())
+	default:
+		if !i.IsUint64() || v.OverflowUint(i.Uint64()) {
+			return d.overflowError(v, val)
+		}
+		v.SetUint(i.Uint64())
+	}
+	return nil
+}
+
+// OverflowError returns a DecodeError for a value too large to fit in v.
+func (d *Decoder) overflowError(v reflect.Value, val interface{}) error {
+	return d.decodeError(v, fmt.Sprintf("value %v won't fit in type %v", val, v.Type().String()), nil)
+}
+
+// WrapError wraps an error returned while decoding a value of type t to v, unless
+// it's already a DecodeError from further down.
+func (d *Decoder) wrapError(v reflect.Value, t Type, err error) error {
+	if _, ok := err.(*DecodeError); ok {
+		return err
+	}
+	e := d.decodeError(v, "", err)
+	e.Type = t
+	return e
+}
+
+// WithField records the struct field being decoded when a DecodeError occurred,
+// unless it's already been recorded by a more deeply-nested struct.
+func withField(err error, t reflect.Type, f *field) error {
+	if e, ok := err.(*DecodeError); ok && e.Struct == "" {
+		e.Struct = structName(t)
+		e.Field = t.FieldByIndex(f.path).Name
+	}
+	return err
+}
+
+// StructName returns the name of a struct type for a DecodeError.
+func structName(t reflect.Type) string {
+	if t.Name() != "" {
+		return t.Name()
+	}
+	return t.String()
+}
+
+// HasPrefix returns true if as starts with prefix.
+func hasPrefix(as, prefix []string) bool {
+	if len(as) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if as[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
 func (d *Decoder) decodeBoolTo(v reflect.Value) error {
 	val, err := d.r.BoolValue()
 	if err != nil {
@@ -256,11 +568,9 @@ func (d *Decoder) decodeBoolTo(v reflect.Value) error {
 			return nil
 		}
 	}
-	return fmt.Errorf("ion: cannot decode bool to %v", v.Type().String())
+	return d.typeError(v)
 }
 
-var bigIntType = reflect.TypeOf(big.Int{})
-
 func (d *Decoder) decodeIntTo(v reflect.Value) error {
 	switch v.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
@@ -269,7 +579,7 @@ func (d *Decoder) decodeIntTo(v reflect.Value) error {
 			return err
 		}
 		if v.OverflowInt(val) {
-			return fmt.Errorf("ion: value %v won't fit in type %v", val, v.Type().String())
+			return d.overflowError(v, val)
 		}
 		v.SetInt(val)
 		return nil
@@ -280,7 +590,7 @@ func (d *Decoder) decodeIntTo(v reflect.Value) error {
 			return err
 		}
 		if val < 0 || v.OverflowUint(uint64(val)) {
-			return fmt.Errorf("ion: value %v won't fit in type %v", val, v.Type().String())
+			return d.overflowError(v, val)
 		}
 		v.SetUint(uint64(val))
 		return nil
@@ -291,11 +601,11 @@ func (d *Decoder) decodeIntTo(v reflect.Value) error {
 			return err
 		}
 		if !val.IsUint64() {
-			return fmt.Errorf("ion: value %v won't fit in type %v", val, v.Type().String())
+			return d.overflowError(v, val)
 		}
 		uiv := val.Uint64()
 		if v.OverflowUint(uiv) {
-			return fmt.Errorf("ion: value %v won't fit in type %v", val, v.Type().String())
+			return d.overflowError(v, val)
 		}
 		v.SetUint(uiv)
 		return nil
@@ -320,7 +630,7 @@ func (d *Decoder) decodeIntTo(v reflect.Value) error {
 			return nil
 		}
 	}
-	return fmt.Errorf("ion: cannot decode int to %v", v.Type().String())
+	return d.typeError(v)
 }
 
 func (d *Decoder) decodeFloatTo(v reflect.Value) error {
@@ -332,17 +642,35 @@ func (d *Decoder) decodeFloatTo(v reflect.Value) error {
 	switch v.Kind() {
 	case reflect.Float32, reflect.Float64:
 		if v.OverflowFloat(val) {
-			return fmt.Errorf("ion: value %v won't fit in type %v", val, v.Type().String())
+			return d.overflowError(v, val)
+		}
+		lossy := v.Kind() == reflect.Float32 && float64(float32(val)) != val && !math.IsNaN(val)
+		if lossy && d.opts&DecodeDisallowLossyNumbers != 0 {
+			return d.lossyError(v, val)
 		}
 		v.SetFloat(val)
 		return nil
 
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if d.opts&DecodeConvertNumbers == 0 {
+			break
+		}
+		if math.IsNaN(val) || math.IsInf(val, 0) {
+			return d.overflowError(v, val)
+		}
+		i := math.Trunc(val)
+		if i != val && d.opts&DecodeDisallowLossyNumbers != 0 {
+			return d.lossyError(v, val)
+		}
+		bi, _ := big.NewFloat(i).Int(nil)
+		return d.setInt(v, bi, val)
+
 	case reflect.Struct:
 		if v.Type() == decimalType {
-			flt := strconv.FormatFloat(val, 'g', -1, 64)
-			dec, err := ParseDecimal(strings.Replace(flt, "e", "d", 1))
+			dec, err := decimalFromFloat(val, 64)
 			if err != nil {
-				return err
+				return d.decodeError(v, "", err)
 			}
 			v.Set(reflect.ValueOf(*dec))
 			return nil
@@ -354,7 +682,7 @@ func (d *Decoder) decodeFloatTo(v reflect.Value) error {
 			return nil
 		}
 	}
-	return fmt.Errorf("ion: cannot decode float to %v", v.Type().String())
+	return d.typeError(v)
 }
 
 func (d *Decoder) decodeDecimalTo(v reflect.Value) error {
@@ -364,6 +692,50 @@ func (d *Decoder) decodeDecimalTo(v reflect.Value) error {
 	}
 
 	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		if !d.convertDecimals() {
+			break
+		}
+		flt, err := val.float()
+		if err != nil {
+			return d.decodeError(v, "", err)
+		}
+		if v.OverflowFloat(flt) {
+			return d.overflowError(v, val)
+		}
+		if d.opts&DecodeDisallowLossyNumbers != 0 {
+			// Lossless if it round-trips back to the same number.
+			bits := v.Type().Bits()
+			if bits == 32 {
+				flt = float64(float32(flt))
+			}
+			if flt == 0 && val.Sign() != 0 {
+				// Underflowed; comparing would rescale to val's (maybe huge) scale.
+				return d.lossyError(v, val)
+			}
+			back, err := decimalFromFloat(flt, bits)
+			if err != nil || back.Cmp(val) != 0 {
+				return d.lossyError(v, val)
+			}
+		}
+		v.SetFloat(flt)
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if !d.convertDecimals() {
+			break
+		}
+		if _, exp := val.CoEx(); exp > 20 && val.Sign() != 0 {
+			// Definitely too big, and expensive to find out exactly how big.
+			return d.overflowError(v, val)
+		}
+		i := val.truncBig()
+		if d.opts&DecodeDisallowLossyNumbers != 0 && !val.IsInteger() {
+			return d.lossyError(v, val)
+		}
+		return d.setInt(v, i, val)
+
 	case reflect.Struct:
 		if v.Type() == decimalType {
 			v.Set(reflect.ValueOf(*val))
@@ -376,14 +748,22 @@ func (d *Decoder) decodeDecimalTo(v reflect.Value) error {
 			return nil
 		}
 	}
-	return fmt.Errorf("ion: cannot decode decimal to %v", v.Type().String())
+	return d.typeError(v)
+}
+
+// ConvertDecimals returns true if decimals can be decoded to Go integer and float
+// types: with DecodeConvertNumbers, or for a field tagged with the decimal option,
+// which the Encoder writes as decimals.
+func (d *Decoder) convertDecimals() bool {
+	return d.opts&DecodeConvertNumbers != 0 || (d.field != nil && d.field.decimal)
 }
 
 func (d *Decoder) decodeTimestampTo(v reflect.Value) error {
-	val, err := d.r.TimeValue()
+	ts, err := d.r.TimestampValue()
 	if err != nil {
 		return err
 	}
+	val := ts.Time()
 
 	switch v.Kind() {
 	case reflect.Struct:
@@ -391,6 +771,10 @@ func (d *Decoder) decodeTimestampTo(v reflect.Value) error {
 			v.Set(reflect.ValueOf(val))
 			return nil
 		}
+		if v.Type() == timestampType {
+			v.Set(reflect.ValueOf(*ts))
+			return nil
+		}
 
 	case reflect.Interface:
 		if v.NumMethod() == 0 {
@@ -398,7 +782,7 @@ func (d *Decoder) decodeTimestampTo(v reflect.Value) error {
 			return nil
 		}
 	}
-	return fmt.Errorf("ion: cannot decode timestamp to %v", v.Type().String())
+	return d.typeError(v)
 }
 
 func (d *Decoder) decodeStringTo(v reflect.Value) error {
@@ -407,6 +791,15 @@ func (d *Decoder) decodeStringTo(v reflect.Value) error {
 		return err
 	}
 
+	if !isNativeType(v.Type()) {
+		if i, ok := implementer(v, textUnmarshalerType); ok {
+			if err := i.(encoding.TextUnmarshaler).UnmarshalText([]byte(val)); err != nil {
+				return d.wrapError(v, d.r.Type(), err)
+			}
+			return nil
+		}
+	}
+
 	switch v.Kind() {
 	case reflect.String:
 		v.SetString(val)
@@ -414,11 +807,15 @@ func (d *Decoder) decodeStringTo(v reflect.Value) error {
 
 	case reflect.Interface:
 		if v.NumMethod() == 0 {
-			v.Set(reflect.ValueOf(val))
+			if d.r.Type() == SymbolType {
+				v.Set(reflect.ValueOf(Symbol(val)))
+			} else {
+				v.Set(reflect.ValueOf(val))
+			}
 			return nil
 		}
 	}
-	return fmt.Errorf("ion: cannot decode string to %v", v.Type().String())
+	return d.typeError(v)
 }
 
 func (d *Decoder) decodeLobTo(v reflect.Value) error {
@@ -428,6 +825,10 @@ func (d *Decoder) decodeLobTo(v reflect.Value) error {
 	}
 
 	switch v.Kind() {
+	case reflect.String:
+		v.SetString(string(val))
+		return nil
+
 	case reflect.Slice:
 		if v.Type().Elem().Kind() == reflect.Uint8 {
 			v.SetBytes(val)
@@ -445,11 +846,15 @@ func (d *Decoder) decodeLobTo(v reflect.Value) error {
 
 	case reflect.Interface:
 		if v.NumMethod() == 0 {
-			v.Set(reflect.ValueOf(val))
+			if d.r.Type() == ClobType {
+				v.Set(reflect.ValueOf(Clob(val)))
+			} else {
+				v.Set(reflect.ValueOf(val))
+			}
 			return nil
 		}
 	}
-	return fmt.Errorf("ion: cannot decode lob to %v", v.Type().String())
+	return d.typeError(v)
 }
 
 func (d *Decoder) decodeStructTo(v reflect.Value) error {
@@ -470,46 +875,128 @@ func (d *Decoder) decodeStructTo(v reflect.Value) error {
 			return nil
 		}
 	}
-	return fmt.Errorf("ion: cannot decode struct to %v", v.Type().String())
+	return d.typeError(v)
 }
 
 func (d *Decoder) decodeStructToStruct(v reflect.Value) error {
 	fields := fieldsFor(v.Type())
 
+	for _, i := range fields.annotations {
+		subv, err := findSubvalue(v, &fields.list[i])
+		if err != nil {
+			return err
+		}
+
+		// The Encoder writes the type's own annotations first, so leave them out.
+		as := d.r.Annotations()
+		if static := staticAnnotations(v); hasPrefix(as, static) {
+			as = as[len(static):]
+		}
+		subv.Set(reflect.ValueOf(append([]string(nil), as...)))
+	}
+
 	if err := d.r.StepIn(); err != nil {
 		return err
 	}
 
+	var seen []bool
+	if d.opts&DecodeRejectDuplicateFields != 0 {
+		seen = make([]bool, len(fields.list))
+	}
+
 	for d.r.Next() {
 		name := d.r.FieldName()
-		field := findField(fields, name)
-		if field != nil {
-			subv, err := findSubvalue(v, field)
-			if err != nil {
-				return err
+		i := fields.find(name, d.opts&DecodeCaseSensitiveFields != 0)
+		if i < 0 {
+			if d.opts&DecodeDisallowUnknownFields != 0 {
+				return d.structFieldError(v, fmt.Sprintf("unknown field %q", name))
 			}
+			continue
+		}
 
-			if err := d.decodeTo(subv); err != nil {
-				return err
+		if seen != nil {
+			if seen[i] {
+				return d.structFieldError(v, fmt.Sprintf("duplicate field %q", name))
 			}
+			seen[i] = true
+		}
+
+		field := &fields.list[i]
+		subv, err := findSubvalue(v, field)
+		if err != nil {
+			return err
+		}
+
+		if err := d.decodeFieldTo(subv, field); err != nil {
+			return withField(err, v.Type(), field)
 		}
 	}
 
 	return d.r.StepOut()
 }
 
-func findField(fields []field, name string) *field {
-	var f *field
-	for i := range fields {
-		ff := &fields[i]
-		if ff.name == name {
-			return ff
+// StructFieldError returns a DecodeError for a field of a struct being decoded
+// to v that doesn't fit any of v's fields.
+func (d *Decoder) structFieldError(v reflect.Value, msg string) error {
+	t := v.Type()
+	e := d.decodeError(v, fmt.Sprintf("%v in %v", msg, t.String()), nil)
+	e.Struct = structName(t)
+	return e
+}
+
+// DecodeFieldTo decodes a value to a struct field, applying any options from the
+// field's tag.
+func (d *Decoder) decodeFieldTo(v reflect.Value, f *field) error {
+	outer := d.field
+	d.field = f
+	err := d.decodeTo(v)
+	d.field = outer
+	return err
+}
+
+// DecodeStringNumberTo decodes a string-encoded bool or number, as written for fields
+// tagged with the string option.
+func (d *Decoder) decodeStringNumberTo(v reflect.Value) error {
+	str, err := d.r.StringValue()
+	if err != nil {
+		return err
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(str)
+		if err != nil {
+			return d.decodeError(v, fmt.Sprintf("cannot decode string %q to %v", str, v.Type().String()), err)
+		}
+		v.SetBool(b)
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(str, 10, v.Type().Bits())
+		if err != nil {
+			return d.decodeError(v, fmt.Sprintf("cannot decode string %q to %v", str, v.Type().String()), err)
 		}
-		if f == nil && strings.EqualFold(ff.name, name) {
-			f = ff
+		v.SetInt(i)
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		i, err := strconv.ParseUint(str, 10, v.Type().Bits())
+		if err != nil {
+			return d.decodeError(v, fmt.Sprintf("cannot decode string %q to %v", str, v.Type().String()), err)
 		}
+		v.SetUint(i)
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		flt, err := strconv.ParseFloat(str, v.Type().Bits())
+		if err != nil {
+			return d.decodeError(v, fmt.Sprintf("cannot decode string %q to %v", str, v.Type().String()), err)
+		}
+		v.SetFloat(flt)
+		return nil
 	}
-	return f
+
+	return d.decodeStringTo(v)
 }
 
 func findSubvalue(v reflect.Value, f *field) (reflect.Value, error) {
@@ -530,17 +1017,25 @@ func findSubvalue(v reflect.Value, f *field) (reflect.Value, error) {
 
 func (d *Decoder) decodeStructToMap(v reflect.Value) error {
 	t := v.Type()
-	switch t.Key().Kind() {
-	case reflect.String:
+	kt := t.Key()
+	switch kt.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
 	default:
-		return fmt.Errorf("ion: cannot decode struct to %v", t.String())
+		if !reflect.PtrTo(kt).Implements(textUnmarshalerType) {
+			return d.typeError(v)
+		}
 	}
 
 	if v.IsNil() {
 		v.Set(reflect.MakeMap(t))
 	}
 
-	subv := reflect.New(t.Elem()).Elem()
+	var seen map[string]bool
+	if d.opts&DecodeRejectDuplicateFields != 0 {
+		seen = map[string]bool{}
+	}
 
 	if err := d.r.StepIn(); err != nil {
 		return err
@@ -548,43 +1043,88 @@ func (d *Decoder) decodeStructToMap(v reflect.Value) error {
 
 	for d.r.Next() {
 		name := d.r.FieldName()
-		if err := d.decodeTo(subv); err != nil {
-			return err
+		if seen != nil {
+			if seen[name] {
+				return d.decodeError(v, fmt.Sprintf("duplicate field %q in %v", name, t.String()), nil)
+			}
+			seen[name] = true
 		}
 
-		var kv reflect.Value
-		switch t.Key().Kind() {
-		case reflect.String:
-			kv = reflect.ValueOf(name)
-		default:
-			panic("wat?")
+		// Each entry gets a fresh value, so nothing leaks from the previous one.
+		subv := reflect.New(t.Elem()).Elem()
+		if err := d.decodeTo(subv); err != nil {
+			return err
 		}
 
-		if kv.IsValid() {
-			v.SetMapIndex(kv, subv)
+		kv := reflect.New(kt).Elem()
+		if err := setKey(kv, name); err != nil {
+			return d.decodeError(kv, fmt.Sprintf("cannot decode field name %q to %v", name, kt), err)
 		}
+		v.SetMapIndex(kv, subv)
 	}
 
 	return d.r.StepOut()
 }
 
+// SetKey sets the map key kv from a field name, the reverse of keyString.
+func setKey(kv reflect.Value, name string) error {
+	if kv.Kind() == reflect.String {
+		kv.SetString(name)
+		return nil
+	}
+
+	if tu, ok := kv.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return tu.UnmarshalText([]byte(name))
+	}
+
+	switch kv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(name, 10, kv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		kv.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(name, 10, kv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		kv.SetUint(u)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(name)
+		if err != nil {
+			return err
+		}
+		kv.SetBool(b)
+	}
+	return nil
+}
+
 func (d *Decoder) decodeSliceTo(v reflect.Value) error {
 	k := v.Kind()
 
-	// If all we know is we need an interface{}, decode an []interface{} with
-	// types based on the Ion value stream.
+	// If all we know is we need an interface{}, decode an []interface{} (or a Sexp)
+	// with types based on the Ion value stream.
 	if k == reflect.Interface && v.NumMethod() == 0 {
+		// Check the type before decodeSlice steps back out past the container.
+		sexp := d.r.Type() == SexpType
 		s, err := d.decodeSlice()
 		if err != nil {
 			return err
 		}
-		v.Set(reflect.ValueOf(s))
+		if sexp {
+			v.Set(reflect.ValueOf(Sexp(s)))
+		} else {
+			v.Set(reflect.ValueOf(s))
+		}
 		return nil
 	}
 
 	// Only other valid targets are arrays and slices.
 	if k != reflect.Array && k != reflect.Slice {
-		return fmt.Errorf("ion: cannot unmarshal slice to %v", v.Type().String())
+		return d.typeError(v)
 	}
 
 	if err := d.r.StepIn(); err != nil {
@@ -2,6 +2,8 @@ package ion
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"math"
 	"math/big"
 	"reflect"
@@ -441,6 +443,25 @@ func TestDecodeStructTo(t *testing.T) {
 	test("{}", &map[string]string{}, &map[string]string{})
 	test("{foo:bar}", &map[string]string{}, &map[string]string{"foo": "bar"})
 	test("{a:4,b:2}", &map[string]int{}, &map[string]int{"a": 4, "b": 2})
+	test("{a:{foo:x},b:{bar:1}}", &map[string]foo{}, &map[string]foo{"a": {"x", 0}, "b": {"", 1}})
+
+	one, two := 1, 2
+	test("{a:1,b:2}", &map[string]*int{}, &map[string]*int{"a": &one, "b": &two})
+
+	type name string
+	test("{a:1,b:2}", &map[name]int{}, &map[name]int{"a": 1, "b": 2})
+	test("{'-1':a,'10':b}", &map[int]string{}, &map[int]string{-1: "a", 10: "b"})
+	test("{'255':true}", &map[uint8]bool{}, &map[uint8]bool{255: true})
+	test("{'false':0,'true':1}", &map[bool]int{}, &map[bool]int{true: 1, false: 0})
+
+	for _, str := range []string{"{'256':true}", "{'-1':true}", "{x:true}"} {
+		if err := UnmarshalStr(str, &map[uint8]bool{}); err == nil {
+			t.Errorf("expected an error decoding %v to a map[uint8]bool", str)
+		}
+	}
+	if err := UnmarshalStr("{a:1}", &map[struct{}]int{}); err == nil {
+		t.Error("expected an error decoding to a map with struct keys")
+	}
 }
 
 func TestDecodeListTo(t *testing.T) {
@@ -473,6 +494,12 @@ func TestDecodeListTo(t *testing.T) {
 	var i interface{}
 	var ei interface{} = []interface{}{true, false}
 	test("[true,false]", &i, &ei)
+
+	type holder struct {
+		X interface{}
+	}
+	test("{X:(a 1 b)}", &holder{}, &holder{Sexp{Symbol("a"), 1, Symbol("b")}})
+	test("{X:[a, 1, b]}", &holder{}, &holder{[]interface{}{Symbol("a"), 1, Symbol("b")}})
 }
 
 func TestDecode(t *testing.T) {
@@ -511,7 +538,7 @@ func TestDecode(t *testing.T) {
 
 	test("2020T", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
 
-	test("hello", "hello")
+	test("hello", Symbol("hello"))
 	test("\"hello\"", "hello")
 
 	test("null.blob", nil)
@@ -519,21 +546,623 @@ func TestDecode(t *testing.T) {
 	test("{{aGVsbG8=}}", []byte("hello"))
 
 	test("null.clob", nil)
-	test("{{''''''}}", []byte{})
-	test("{{'''hello'''}}", []byte("hello"))
+	test("{{''''''}}", Clob{})
+	test("{{'''hello'''}}", Clob("hello"))
 
 	test("null.struct", nil)
 	test("{}", map[string]interface{}{})
 	test("{a:1,b:two}", map[string]interface{}{
 		"a": 1,
-		"b": "two",
+		"b": Symbol("two"),
 	})
 
 	test("null.list", nil)
 	test("[]", []interface{}{})
-	test("[1, two]", []interface{}{1, "two"})
+	test("[1, two]", []interface{}{1, Symbol("two")})
 
 	test("null.sexp", nil)
-	test("()", []interface{}{})
-	test("(1 + two)", []interface{}{1, "+", "two"})
+	test("()", Sexp{})
+	test("(1 + two)", Sexp{1, Symbol("+"), Symbol("two")})
+}
+
+func TestDecodeUseNumber(t *testing.T) {
+	test := func(data string, eval interface{}) {
+		t.Run(data, func(t *testing.T) {
+			d := NewDecoderOpts(NewReaderStr(data), DecodeUseNumber)
+			val, err := d.Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(val, eval) {
+				t.Errorf("expected %v, got %v", eval, val)
+			}
+		})
+	}
+
+	test("null.int", nil)
+	test("0x1F", Number("31"))
+	test("9223372036854775808", Number("9223372036854775808"))
+	test("4.20", Number("4.20"))
+	test("4.2e1", Number("4.2e+1"))
+	test("-inf", Number("-inf"))
+	test("[1, 2.0, {a:3e0}]", []interface{}{Number("1"), Number("2.0"), map[string]interface{}{"a": Number("3e+0")}})
+
+	// Numbers can be decoded to without the option, but only from numbers.
+	n := Number("")
+	if err := UnmarshalStr("1.50", &n); err != nil {
+		t.Fatal(err)
+	}
+	if n != "1.50" {
+		t.Errorf("expected 1.50, got %v", n)
+	}
+	if err := UnmarshalStr("\"1.50\"", &n); err == nil {
+		t.Error("expected an error decoding a string to a Number")
+	}
+}
+
+func TestDecodeRoundTrip(t *testing.T) {
+	test := func(data string) {
+		t.Run(data, func(t *testing.T) {
+			d := NewDecoderOpts(NewReaderStr(data), DecodeUseNumber)
+			val, err := d.Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			text, err := MarshalText(val)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(text) != data {
+				t.Errorf("expected %v, got %v", data, string(text))
+			}
+		})
+	}
+
+	test("sym")
+	test("\"str\"")
+	test("{{\"clob\"}}")
+	test("{{YmxvYg==}}")
+	test("(f 1 (g 2.50))")
+	test("[a,\"b\",(c),[1d-3,1.5e+0]]")
+	test("{a:sym,b:\"str\",c:(-1 18446744073709551616)}")
+}
+
+func TestDecodeUnmarshalers(t *testing.T) {
+	test := func(str string, val, eval interface{}) {
+		t.Run(str, func(t *testing.T) {
+			d := NewDecoder(NewReaderStr(str))
+			err := d.DecodeTo(val)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(val, eval) {
+				t.Errorf("expected %v, got %v", eval, val)
+			}
+		})
+	}
+
+	type order struct {
+		Price  money
+		Refund *money
+		Color  color
+	}
+
+	test("(42 USD)", &money{}, &money{42, "USD"})
+	test("{Price:(1 EUR),Refund:(2 GBP),Color:blue}", &order{}, &order{money{1, "EUR"}, &money{2, "GBP"}, 2})
+	test("{Refund:null}", &order{Refund: &money{}}, &order{})
+	test("[(1 EUR),(2 GBP)]", &[]money{}, &[]money{{1, "EUR"}, {2, "GBP"}})
+
+	test("green", new(color), func() *color { c := color(1); return &c }())
+	test("{red:1,blue:3}", &map[color]int{}, &map[color]int{0: 1, 2: 3})
+
+	if err := UnmarshalStr("purple", new(color)); err == nil {
+		t.Error("expected an error decoding an unknown color")
+	}
+	if err := UnmarshalStr("[]", &money{}); err == nil {
+		t.Error("expected an error from UnmarshalIon")
+	}
+
+	// Pointer-receiver unmarshalers can't be called on unaddressable values, since
+	// they'd decode into a throwaway copy; marshalers still can.
+	if _, ok := implementer(reflect.ValueOf(money{}), unmarshalerType); ok {
+		t.Error("expected an unaddressable money not to be an Unmarshaler")
+	}
+	if _, ok := implementer(reflect.ValueOf(color(0)), textUnmarshalerType); ok {
+		t.Error("expected an unaddressable color not to be a TextUnmarshaler")
+	}
+	if _, ok := implementer(reflect.ValueOf(money{}), marshalerType); !ok {
+		t.Error("expected an unaddressable money to be a Marshaler")
+	}
+}
+
+func TestUnmarshalCurrent(t *testing.T) {
+	r := NewReaderStr("{a:1} [2, 3]")
+
+	r.Next()
+	m := map[string]int{}
+	if err := UnmarshalCurrent(r, &m); err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 1 || m["a"] != 1 {
+		t.Errorf("expected {a:1}, got %v", m)
+	}
+
+	r.Next()
+	if err := r.StepIn(); err != nil {
+		t.Fatal(err)
+	}
+	r.Next()
+	i := 0
+	if err := UnmarshalCurrent(r, &i); err != nil {
+		t.Fatal(err)
+	}
+	if i != 2 {
+		t.Errorf("expected 2, got %v", i)
+	}
+
+	if err := UnmarshalCurrent(r, i); err == nil {
+		t.Error("expected an error for a non-pointer")
+	}
+}
+
+// A fallback unmarshals itself reflectively, recording the decoder's options.
+type fallback struct {
+	A    int
+	Opts DecoderOpts `ion:"-"`
+}
+
+func (f *fallback) UnmarshalIon(r Reader) error {
+	type plain fallback
+	opts := DecoderOptsOf(r)
+	if err := UnmarshalCurrent(r, (*plain)(f)); err != nil {
+		return err
+	}
+	f.Opts = opts
+	return nil
+}
+
+func TestUnmarshalerDecoderOpts(t *testing.T) {
+	// Text and Value readers both carry the options to UnmarshalIon.
+	readers := []struct {
+		name string
+		new  func(t *testing.T, str string) Reader
+	}{
+		{"text", func(t *testing.T, str string) Reader { return NewReaderStr(str) }},
+		{"value", func(t *testing.T, str string) Reader { return NewValueReader(readValuesStr(t, str)...) }},
+	}
+
+	test := func(str string, opts DecoderOpts, ok bool) {
+		for _, rd := range readers {
+			t.Run(fmt.Sprintf("%v/%v/%v", rd.name, str, opts), func(t *testing.T) {
+				r := rd.new(t, str)
+				v := []fallback{}
+				err := NewDecoderOpts(r, opts).DecodeTo(&v)
+				if !ok {
+					if err == nil {
+						t.Fatalf("expected an error, got %+v", v)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(v) != 1 || v[0].A != 1 || v[0].Opts != opts {
+					t.Errorf("expected [{A:1 Opts:%v}], got %+v", opts, v)
+				}
+				if o := DecoderOptsOf(r); o != 0 {
+					t.Errorf("expected no options after decoding, got %v", o)
+				}
+			})
+		}
+	}
+
+	test("[{A:1}]", 0, true)
+	test("[{A:1}]", DecodeDisallowUnknownFields|DecodeUseNumber, true)
+	test("[{a:1}]", 0, true)
+	test("[{a:1}]", DecodeCaseSensitiveFields|DecodeDisallowUnknownFields, false)
+	test("[{A:1,B:2}]", 0, true)
+	test("[{A:1,B:2}]", DecodeDisallowUnknownFields, false)
+	test("[{A:1,A:1}]", DecodeRejectDuplicateFields, false)
+	test("[{A:1e0}]", 0, false)
+	test("[{A:1e0}]", DecodeConvertNumbers, true)
+}
+
+func TestDecodeIonTags(t *testing.T) {
+	type foo struct {
+		A  int       `ion:"a" json:"b"`
+		S  string    `ion:"s,symbol"`
+		F  float64   `ion:"f,decimal"`
+		C  string    `ion:"c,clob"`
+		TS time.Time `ion:"ts,timestamp=day"`
+		I  int       `ion:"i,string"`
+		B  *bool     `ion:"t,string"`
+		U  uint16    `ion:"u,string"`
+	}
+
+	tru := true
+	eval := foo{
+		A:  1,
+		S:  "sym",
+		F:  1.5,
+		C:  "hi",
+		TS: time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC),
+		I:  -42,
+		B:  &tru,
+		U:  42,
+	}
+
+	var val foo
+	err := UnmarshalStr(`{a:1,b:2,s:sym,f:1.5,c:{{"hi"}},ts:2020-05-01,i:"-42",t:"true",u:"42"}`, &val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(val, eval) {
+		t.Errorf("expected %v, got %v", eval, val)
+	}
+
+	if err := UnmarshalStr(`{u:"-1"}`, &val); err == nil {
+		t.Error("expected an error decoding a negative uint16")
+	}
+
+	// Unknown options are ignored.
+	var h struct {
+		H time.Time `ion:"h,timestamp=hours"`
+	}
+	if err := UnmarshalStr(`{h:2020-05-01T12:00Z}`, &h); err != nil {
+		t.Error(err)
+	}
+	if !h.H.Equal(time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected 2020-05-01T12:00Z, got %v", h.H)
+	}
+}
+
+func TestIonTagsRoundTrip(t *testing.T) {
+	type inner struct {
+		N int `ion:"n"`
+	}
+	type tagged struct {
+		N  []int          `ion:"n,string"`
+		P  *[]*bool       `ion:"p,string"`
+		A  [2]uint8       `ion:"a,string"`
+		S  []string       `ion:"s,symbol"`
+		F  []float64      `ion:"f,decimal"`
+		I  []inner        `ion:"i,string"`
+		M  map[string]int `ion:"m,string"`
+		LL [][]int        `ion:"ll,string"`
+		X  []interface{}  `ion:"x,string"`
+	}
+
+	tru := true
+	bs := []*bool{&tru, nil}
+	v := tagged{
+		N:  []int{1, -2},
+		P:  &bs,
+		A:  [2]uint8{3, 4},
+		S:  []string{"a", "b"},
+		F:  []float64{1.5, 0.1},
+		I:  []inner{{5}},
+		M:  map[string]int{"m": 6},
+		LL: [][]int{{7}, {8, 9}},
+		X:  []interface{}{"8"},
+	}
+
+	text, err := MarshalText(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eval := `{n:["1","-2"],p:["true",null],a:[3,4],s:[a,b],f:[1.5,1d-1],i:[{n:5}],m:{m:6},ll:[["7"],["8","9"]],x:["8"]}`
+	if string(text) != eval {
+		t.Errorf("expected %v, got %v", eval, string(text))
+	}
+
+	var back tagged
+	if err := UnmarshalStr(string(text), &back); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back, v) {
+		t.Errorf("expected %+v, got %+v", v, back)
+	}
+}
+
+func TestDecodeAnnotations(t *testing.T) {
+	var o order
+	if err := UnmarshalStr("order::rush::{id:1}", &o); err != nil {
+		t.Fatal(err)
+	}
+	eval := order{ID: 1, Tags: []string{"rush"}}
+	if !reflect.DeepEqual(o, eval) {
+		t.Errorf("expected %v, got %v", eval, o)
+	}
+
+	// Annotations aren't checked by default.
+	if err := UnmarshalStr("invoice::{id:1}", &o); err != nil {
+		t.Fatal(err)
+	}
+	eval = order{ID: 1, Tags: []string{"invoice"}}
+	if !reflect.DeepEqual(o, eval) {
+		t.Errorf("expected %v, got %v", eval, o)
+	}
+
+	test := func(str string, ok bool) {
+		t.Run(str, func(t *testing.T) {
+			var os []*order
+			d := NewDecoderOpts(NewReaderStr(str), DecodeStrictAnnotations)
+			err := d.DecodeTo(&os)
+			if ok && err != nil {
+				t.Error(err)
+			}
+			if !ok && err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	test("[order::{id:1}]", true)
+	test("[order::rush::{id:1}, null]", true)
+	test("[{id:1}]", false)
+	test("[rush::order::{id:1}]", false)
+}
+
+func TestDecodeErrors(t *testing.T) {
+	type item struct {
+		Price float64 `ion:"price"`
+		Qty   uint8   `ion:"qty"`
+	}
+	type order struct {
+		Items []item `ion:"items"`
+	}
+	type batch struct {
+		Orders []order `ion:"orders"`
+	}
+
+	test := func(str string, eval DecodeError) {
+		t.Run(str, func(t *testing.T) {
+			var b batch
+			err := UnmarshalStr(str, &b)
+
+			de, ok := err.(*DecodeError)
+			if !ok {
+				t.Fatalf("expected a DecodeError, got %v", err)
+			}
+			if de.Path != eval.Path || de.Type != eval.Type || de.GoType != eval.GoType ||
+				de.Struct != eval.Struct || de.Field != eval.Field || de.Offset != eval.Offset {
+				t.Errorf("expected %+v, got %+v", eval, *de)
+			}
+		})
+	}
+
+	floatType := reflect.TypeOf(float64(0))
+	test(`{orders:[{items:[]},{items:[{price:1.5e0},{price:"free"}]}]}`, DecodeError{
+		Path:   "orders[1].items[1].price",
+		Type:   StringType,
+		GoType: floatType,
+		Struct: "item",
+		Field:  "Price",
+		Offset: 49,
+	})
+	test(`{orders:[{items:[{qty:300}]}]}`, DecodeError{
+		Path:   "orders[0].items[0].qty",
+		Type:   IntType,
+		GoType: reflect.TypeOf(uint8(0)),
+		Struct: "item",
+		Field:  "Qty",
+		Offset: 22,
+	})
+	test(`{orders:{}}`, DecodeError{
+		Path:   "orders",
+		Type:   StructType,
+		GoType: reflect.TypeOf([]order{}),
+		Struct: "batch",
+		Field:  "Orders",
+		Offset: 8,
+	})
+
+	// Binary readers know paths and offsets too.
+	bin, err := MarshalBinary(map[string]interface{}{"orders": []interface{}{true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b batch
+	err = Unmarshal(bin, &b)
+	de, ok := err.(*DecodeError)
+	if !ok {
+		t.Fatalf("expected a DecodeError, got %v", err)
+	}
+	eval := fmt.Sprintf("ion: cannot decode bool to ion.order (offset %v, at orders[0], field batch.Orders)", len(bin)-1)
+	if de.Error() != eval {
+		t.Errorf("expected %v, got %v", eval, de.Error())
+	}
+}
+
+func TestDecodeErrorUnwrap(t *testing.T) {
+	var m money
+	err := UnmarshalStr("[1, usd]", &m)
+
+	var de *DecodeError
+	if !errors.As(err, &de) {
+		t.Fatalf("expected a DecodeError, got %v", err)
+	}
+	if de.Err == nil || errors.Unwrap(err) != de.Err {
+		t.Errorf("expected %v to wrap the Unmarshaler's error", err)
+	}
+}
+
+func TestDecodeStrictFields(t *testing.T) {
+	type thing struct {
+		Name string `ion:"name"`
+		Size int    `ion:"size"`
+	}
+
+	test := func(str string, opts DecoderOpts, eval thing, ok bool) {
+		t.Run(fmt.Sprintf("%v/%v", str, opts), func(t *testing.T) {
+			var th thing
+			err := NewDecoderOpts(NewReaderStr(str), opts).DecodeTo(&th)
+			if !ok {
+				if _, isDE := err.(*DecodeError); !isDE {
+					t.Errorf("expected a DecodeError, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if th != eval {
+				t.Errorf("expected %v, got %v", eval, th)
+			}
+		})
+	}
+
+	test("{name:a,size:1,color:red}", 0, thing{"a", 1}, true)
+	test("{name:a,size:1,color:red}", DecodeDisallowUnknownFields, thing{}, false)
+	test("{name:a,size:1}", DecodeDisallowUnknownFields, thing{"a", 1}, true)
+
+	test("{NAME:a}", 0, thing{Name: "a"}, true)
+	test("{NAME:a}", DecodeCaseSensitiveFields, thing{}, true)
+	test("{NAME:a}", DecodeCaseSensitiveFields|DecodeDisallowUnknownFields, thing{}, false)
+
+	test("{name:a,name:b}", 0, thing{Name: "b"}, true)
+	test("{name:a,name:b}", DecodeRejectDuplicateFields, thing{}, false)
+	test("{name:a,NAME:b}", DecodeRejectDuplicateFields, thing{}, false)
+	test("{name:a,NAME:b}", DecodeRejectDuplicateFields|DecodeCaseSensitiveFields, thing{Name: "a"}, true)
+
+	var m map[string]int
+	err := NewDecoderOpts(NewReaderStr("{a:1,a:2}"), DecodeRejectDuplicateFields).DecodeTo(&m)
+	if _, ok := err.(*DecodeError); !ok {
+		t.Errorf("expected a DecodeError, got %v", err)
+	}
+
+	// Unknown fields are reported against the struct they're found in.
+	type outer struct {
+		Inner thing `ion:"inner"`
+	}
+	var o outer
+	err = NewDecoderOpts(NewReaderStr("{inner:{bogus:1}}"), DecodeDisallowUnknownFields).DecodeTo(&o)
+	de, ok := err.(*DecodeError)
+	if !ok {
+		t.Fatalf("expected a DecodeError, got %v", err)
+	}
+	if de.Path != "inner.bogus" || de.Struct != "thing" || de.Field != "" {
+		t.Errorf("expected inner.bogus in thing, got %v in %v.%v", de.Path, de.Struct, de.Field)
+	}
+}
+
+func TestDecodeLossyNumbers(t *testing.T) {
+	test := func(str string, v interface{}, eval interface{}, strictOK bool) {
+		t.Run(fmt.Sprintf("%v/%T", str, v), func(t *testing.T) {
+			d := NewDecoderOpts(NewReaderStr(str), DecodeConvertNumbers)
+			if err := d.DecodeTo(v); err != nil {
+				t.Fatal(err)
+			}
+			if val := reflect.ValueOf(v).Elem().Interface(); val != eval {
+				t.Errorf("expected %v, got %v", eval, val)
+			}
+
+			d = NewDecoderOpts(NewReaderStr(str), DecodeConvertNumbers|DecodeDisallowLossyNumbers)
+			err := d.DecodeTo(v)
+			if strictOK && err != nil {
+				t.Error(err)
+			}
+			if !strictOK && err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	test("1.5e0", new(int), 1, false)
+	test("-2.0e0", new(int8), int8(-2), true)
+	test("2.5", new(uint), uint(2), false)
+	test("42.000", new(int64), int64(42), true)
+	test("1d3", new(int), 1000, true)
+	test("0.5", new(float64), 0.5, true)
+	test("0.1", new(float64), 0.1, true)
+	test("0.1", new(float32), float32(0.1), true)
+	test("0.30000000000000000001", new(float64), 0.3, false)
+	test("0.1e0", new(float32), float32(0.1), false)
+
+	// Without DecodeConvertNumbers, only floats and decimal-tagged fields convert.
+	for _, str := range []string{"1.0", "1.5e0"} {
+		if err := UnmarshalStr(str, new(int)); err == nil {
+			t.Errorf("expected an error decoding %v to an int", str)
+		}
+	}
+	if err := UnmarshalStr("0.5", new(float64)); err == nil {
+		t.Error("expected an error decoding a decimal to a float64")
+	}
+	var f32 float32
+	if err := UnmarshalStr("0.1e0", &f32); err != nil || f32 != 0.1 {
+		t.Errorf("expected 0.1, got %v, %v", f32, err)
+	}
+	var tagged struct {
+		I int     `ion:"i,decimal"`
+		F float64 `ion:"f,decimal"`
+	}
+	if err := UnmarshalStr("{i:42.,f:0.5}", &tagged); err != nil || tagged.I != 42 || tagged.F != 0.5 {
+		t.Errorf("expected {42 0.5}, got %v, %v", tagged, err)
+	}
+
+	convert := func(str string, v interface{}) error {
+		return NewDecoderOpts(NewReaderStr(str), DecodeConvertNumbers).DecodeTo(v)
+	}
+
+	var i int8
+	if err := convert("300.0", &i); err == nil {
+		t.Error("expected an overflow error")
+	}
+	if err := convert("1d100", &i); err == nil {
+		t.Error("expected an overflow error")
+	}
+	if err := convert("nan", &i); err == nil {
+		t.Error("expected an error decoding nan to an int")
+	}
+
+	// Huge negative exponents mustn't make the decoder work out 10^-exp.
+	for _, str := range []string{"1d-20000000", "-123d-2147483647"} {
+		n := 42
+		if err := convert(str, &n); err != nil || n != 0 {
+			t.Errorf("expected %v to decode to 0, got %v, %v", str, n, err)
+		}
+		d := NewDecoderOpts(NewReaderStr(str), DecodeConvertNumbers|DecodeDisallowLossyNumbers)
+		if err := d.DecodeTo(&n); err == nil {
+			t.Errorf("expected an error decoding %v to an int", str)
+		}
+		for _, v := range []interface{}{new(float64), new(float32)} {
+			d := NewDecoderOpts(NewReaderStr(str), DecodeConvertNumbers|DecodeDisallowLossyNumbers)
+			if err := d.DecodeTo(v); err == nil {
+				t.Errorf("expected an error decoding %v to a %T", str, v)
+			}
+		}
+	}
+}
+
+func BenchmarkDecodeText(b *testing.B) {
+	data, err := MarshalText(benchOrderValue)
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkDecode(b, data)
+}
+
+func BenchmarkDecodeBinary(b *testing.B) {
+	data, err := MarshalBinary(benchOrderValue)
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkDecode(b, data)
+}
+
+func BenchmarkDecodeFolded(b *testing.B) {
+	// Field names that only match case-insensitively.
+	data := []byte(`{ID:1234567,CUSTOMER:"Jane Doe",Email:"jane@example.com",EXPRESS:true,
+		Items:[{Sku:"A-1",Quantity:2,Price:9.99e0}],Total:50.73e0,Currency:USD}`)
+	benchmarkDecode(b, data)
+}
+
+func benchmarkDecode(b *testing.B, data []byte) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		order := benchOrder{}
+		if err := NewDecoder(NewReaderBytes(data)).DecodeTo(&order); err != nil {
+			b.Fatal(err)
+		}
+	}
 }
@@ -0,0 +1,759 @@
+package ion
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// A Value is an in-memory Ion value. Unlike the generic values returned by
+// Decoder.Decode, a Value losslessly represents everything in an Ion data model
+// value: annotations, typed nulls, the distinction between lists and sexps,
+// symbols and strings, clobs and blobs, and struct fields that are repeated or
+// whose order matters to a human reader.
+//
+// 	v := NewStructValue(
+// 		Field{NewSymbolToken("id"), NewIntValue(42)},
+// 		Field{NewSymbolToken("tags"), NewListValue(NewSymbolValue("new"))},
+// 	)
+// 	v.SetAnnotations("order")
+// 	fmt.Println(v) // order::{id:42,tags:[new]}
+//
+// Values can be read from any Reader with ReadValue or ReadValues, written to any
+// Writer with WriteTo, compared with Equal, and modified in place.
+type Value struct {
+	typ         Type
+	annotations []SymbolToken
+
+	// The value itself, or nil if it's a null. Its type depends on typ:
+	// bool, *big.Int, float64, *Decimal, *Timestamp, SymbolToken, string,
+	// []byte, []*Value (lists and sexps), or []Field (structs).
+	val interface{}
+}
+
+// A Field is a named value in an Ion struct.
+type Field struct {
+	Name  SymbolToken
+	Value *Value
+}
+
+// NewNullValue creates a new null value of the given type. A type of NullType
+// creates an untyped null.
+func NewNullValue(t Type) *Value {
+	if t == NoType {
+		t = NullType
+	}
+	return &Value{typ: t}
+}
+
+// NewBoolValue creates a new bool value.
+func NewBoolValue(val bool) *Value {
+	return &Value{typ: BoolType, val: val}
+}
+
+// NewIntValue creates a new int value.
+func NewIntValue(val int64) *Value {
+	return &Value{typ: IntType, val: big.NewInt(val)}
+}
+
+// NewBigIntValue creates a new int value from a big.Int, which is copied, or
+// null.int if val is nil.
+func NewBigIntValue(val *big.Int) *Value {
+	if val == nil {
+		return NewNullValue(IntType)
+	}
+	return &Value{typ: IntType, val: new(big.Int).Set(val)}
+}
+
+// NewFloatValue creates a new float value.
+func NewFloatValue(val float64) *Value {
+	return &Value{typ: FloatType, val: val}
+}
+
+// NewDecimalValue creates a new decimal value from a Decimal, which is copied, or
+// null.decimal if val is nil.
+func NewDecimalValue(val *Decimal) *Value {
+	if val == nil {
+		return NewNullValue(DecimalType)
+	}
+	// Decimal methods never modify the coefficient in place, so a shallow copy
+	// is enough to protect against the caller reusing val.
+	d := *val
+	return &Value{typ: DecimalType, val: &d}
+}
+
+// NewTimestampValue creates a new timestamp value, or null.timestamp if val is nil.
+func NewTimestampValue(val *Timestamp) *Value {
+	if val == nil {
+		return NewNullValue(TimestampType)
+	}
+	return &Value{typ: TimestampType, val: val}
+}
+
+// NewSymbolValue creates a new symbol value with the given text.
+func NewSymbolValue(val string) *Value {
+	return NewSymbolTokenValue(NewSymbolToken(val))
+}
+
+// NewSymbolTokenValue creates a new symbol value from a SymbolToken, which may
+// have unknown text.
+func NewSymbolTokenValue(val SymbolToken) *Value {
+	return &Value{typ: SymbolType, val: val}
+}
+
+// NewStringValue creates a new string value.
+func NewStringValue(val string) *Value {
+	return &Value{typ: StringType, val: val}
+}
+
+// NewClobValue creates a new clob value.
+func NewClobValue(val []byte) *Value {
+	return &Value{typ: ClobType, val: append([]byte{}, val...)}
+}
+
+// NewBlobValue creates a new blob value.
+func NewBlobValue(val []byte) *Value {
+	return &Value{typ: BlobType, val: append([]byte{}, val...)}
+}
+
+// NewListValue creates a new list value containing the given values.
+func NewListValue(vals ...*Value) *Value {
+	return &Value{typ: ListType, val: append([]*Value{}, vals...)}
+}
+
+// NewSexpValue creates a new sexp value containing the given values.
+func NewSexpValue(vals ...*Value) *Value {
+	return &Value{typ: SexpType, val: append([]*Value{}, vals...)}
+}
+
+// NewStructValue creates a new struct value containing the given fields.
+func NewStructValue(fields ...Field) *Value {
+	return &Value{typ: StructType, val: append([]Field{}, fields...)}
+}
+
+// Type returns the type of the value.
+func (v *Value) Type() Type {
+	return v.typ
+}
+
+// IsNull returns true if the value is a (possibly typed) null.
+func (v *Value) IsNull() bool {
+	return v.val == nil
+}
+
+// Annotations returns the text of the value's annotations. Annotations with unknown
+// text are returned as $<sid>.
+func (v *Value) Annotations() []string {
+	if len(v.annotations) == 0 {
+		return nil
+	}
+	as := make([]string, len(v.annotations))
+	for i, a := range v.annotations {
+		as[i] = a.String()
+	}
+	return as
+}
+
+// AnnotationSymbols returns the value's annotations.
+func (v *Value) AnnotationSymbols() []SymbolToken {
+	if len(v.annotations) == 0 {
+		return nil
+	}
+	return append([]SymbolToken{}, v.annotations...)
+}
+
+// SetAnnotations replaces the value's annotations.
+func (v *Value) SetAnnotations(as ...string) {
+	v.annotations = nil
+	for _, a := range as {
+		v.annotations = append(v.annotations, NewSymbolToken(a))
+	}
+}
+
+// SetAnnotationSymbols replaces the value's annotations.
+func (v *Value) SetAnnotationSymbols(as ...SymbolToken) {
+	v.annotations = append([]SymbolToken(nil), as...)
+}
+
+// BoolValue returns the value as a bool. It returns an error if the value is not
+// an Ion bool.
+func (v *Value) BoolValue() (bool, error) {
+	if v.typ != BoolType {
+		return false, &UsageError{"Value.BoolValue", "value is not a bool"}
+	}
+	if v.val == nil {
+		return false, nil
+	}
+	return v.val.(bool), nil
+}
+
+// Int64Value returns the value as an int64. It returns an error if the value is not
+// an Ion int or is too large to fit in an int64.
+func (v *Value) Int64Value() (int64, error) {
+	if v.typ != IntType {
+		return 0, &UsageError{"Value.Int64Value", "value is not an int"}
+	}
+	if v.val == nil {
+		return 0, nil
+	}
+
+	i := v.val.(*big.Int)
+	if !i.IsInt64() {
+		return 0, &UsageError{"Value.Int64Value", "value too large for an int64"}
+	}
+	return i.Int64(), nil
+}
+
+// BigIntValue returns a copy of the value as a big.Int. It returns an error if the
+// value is not an Ion int.
+func (v *Value) BigIntValue() (*big.Int, error) {
+	if v.typ != IntType {
+		return nil, &UsageError{"Value.BigIntValue", "value is not an int"}
+	}
+	if v.val == nil {
+		return nil, nil
+	}
+	return new(big.Int).Set(v.val.(*big.Int)), nil
+}
+
+// FloatValue returns the value as a float64. It returns an error if the value is
+// not an Ion float.
+func (v *Value) FloatValue() (float64, error) {
+	if v.typ != FloatType {
+		return 0, &UsageError{"Value.FloatValue", "value is not a float"}
+	}
+	if v.val == nil {
+		return 0, nil
+	}
+	return v.val.(float64), nil
+}
+
+// DecimalValue returns the value as a Decimal. It returns an error if the value is
+// not an Ion decimal.
+func (v *Value) DecimalValue() (*Decimal, error) {
+	if v.typ != DecimalType {
+		return nil, &UsageError{"Value.DecimalValue", "value is not a decimal"}
+	}
+	if v.val == nil {
+		return nil, nil
+	}
+	return v.val.(*Decimal), nil
+}
+
+// TimestampValue returns the value as a Timestamp. It returns an error if the value
+// is not an Ion timestamp.
+func (v *Value) TimestampValue() (*Timestamp, error) {
+	if v.typ != TimestampType {
+		return nil, &UsageError{"Value.TimestampValue", "value is not a timestamp"}
+	}
+	if v.val == nil {
+		return nil, nil
+	}
+	return v.val.(*Timestamp), nil
+}
+
+// SymbolValue returns the value as a SymbolToken. It returns an error if the value
+// is not an Ion symbol.
+func (v *Value) SymbolValue() (*SymbolToken, error) {
+	if v.typ != SymbolType {
+		return nil, &UsageError{"Value.SymbolValue", "value is not a symbol"}
+	}
+	if v.val == nil {
+		return nil, nil
+	}
+	tok := v.val.(SymbolToken)
+	return &tok, nil
+}
+
+// StringValue returns the text of the value. It returns an error if the value is not
+// an Ion string or symbol. Symbols with unknown text are returned as $<sid>.
+func (v *Value) StringValue() (string, error) {
+	if v.typ != StringType && v.typ != SymbolType {
+		return "", &UsageError{"Value.StringValue", "value is not a string"}
+	}
+	switch val := v.val.(type) {
+	case string:
+		return val, nil
+	case SymbolToken:
+		return val.String(), nil
+	}
+	return "", nil
+}
+
+// ByteValue returns the value's bytes. It returns an error if the value is not an
+// Ion clob or blob.
+func (v *Value) ByteValue() ([]byte, error) {
+	if v.typ != ClobType && v.typ != BlobType {
+		return nil, &UsageError{"Value.ByteValue", "value is not a lob"}
+	}
+	if v.val == nil {
+		return nil, nil
+	}
+	return v.val.([]byte), nil
+}
+
+// Len returns the number of values in a list or sexp, or the number of fields in a
+// struct. It returns zero for other values, including null containers.
+func (v *Value) Len() int {
+	switch val := v.val.(type) {
+	case []*Value:
+		return len(val)
+	case []Field:
+		return len(val)
+	}
+	return 0
+}
+
+// Values returns the values contained in a list or sexp.
+func (v *Value) Values() []*Value {
+	if vals, ok := v.val.([]*Value); ok {
+		return append([]*Value{}, vals...)
+	}
+	return nil
+}
+
+// Index returns the i'th value in a list or sexp, or the value of the i'th field
+// in a struct. It panics if v is not a container or i is out of range.
+func (v *Value) Index(i int) *Value {
+	switch val := v.val.(type) {
+	case []*Value:
+		return val[i]
+	case []Field:
+		return val[i].Value
+	}
+	panic(fmt.Sprintf("ion: Value.Index called on a %v", v.describe()))
+}
+
+// SetIndex replaces the i'th value in a list or sexp. It panics if i is out of range.
+func (v *Value) SetIndex(i int, val *Value) error {
+	vals, err := v.sequence("Value.SetIndex")
+	if err != nil {
+		return err
+	}
+	vals[i] = val
+	return nil
+}
+
+// Append appends values to the end of a list or sexp.
+func (v *Value) Append(vals ...*Value) error {
+	cur, err := v.sequence("Value.Append")
+	if err != nil {
+		return err
+	}
+	v.val = append(cur, vals...)
+	return nil
+}
+
+// Remove removes the i'th value from a list or sexp, or the i'th field from a struct.
+// It panics if i is out of range.
+func (v *Value) Remove(i int) error {
+	switch val := v.val.(type) {
+	case []*Value:
+		v.val = append(val[:i], val[i+1:]...)
+		return nil
+	case []Field:
+		v.val = append(val[:i], val[i+1:]...)
+		return nil
+	}
+	return &UsageError{"Value.Remove", fmt.Sprintf("cannot remove from a %v", v.describe())}
+}
+
+// Fields returns the fields of a struct, in order.
+func (v *Value) Fields() []Field {
+	if fields, ok := v.val.([]Field); ok {
+		return append([]Field{}, fields...)
+	}
+	return nil
+}
+
+// Field returns the value of the first field in a struct with the given name, or nil
+// if there is no such field.
+func (v *Value) Field(name string) *Value {
+	fields, _ := v.val.([]Field)
+	for _, f := range fields {
+		if f.Name.Text != nil && *f.Name.Text == name {
+			return f.Value
+		}
+	}
+	return nil
+}
+
+// FieldValues returns the values of all fields in a struct with the given name.
+func (v *Value) FieldValues(name string) []*Value {
+	var vals []*Value
+
+	fields, _ := v.val.([]Field)
+	for _, f := range fields {
+		if f.Name.Text != nil && *f.Name.Text == name {
+			vals = append(vals, f.Value)
+		}
+	}
+	return vals
+}
+
+// AddField adds a field to the end of a struct, even if the struct already has a field
+// with the same name.
+func (v *Value) AddField(name string, val *Value) error {
+	return v.AddFieldSymbol(NewSymbolToken(name), val)
+}
+
+// AddFieldSymbol adds a field whose name is a SymbolToken to the end of a struct, even
+// if the struct already has a field with the same name.
+func (v *Value) AddFieldSymbol(name SymbolToken, val *Value) error {
+	fields, err := v.fields("Value.AddField")
+	if err != nil {
+		return err
+	}
+	v.val = append(fields, Field{name, val})
+	return nil
+}
+
+// SetField sets the value of a field in a struct, replacing the first existing field
+// with the given name (and removing any others), or adding a new field if there are none.
+func (v *Value) SetField(name string, val *Value) error {
+	fields, err := v.fields("Value.SetField")
+	if err != nil {
+		return err
+	}
+
+	res := fields[:0]
+	found := false
+	for _, f := range fields {
+		if f.Name.Text != nil && *f.Name.Text == name {
+			if found {
+				continue
+			}
+			f.Value = val
+			found = true
+		}
+		res = append(res, f)
+	}
+	if !found {
+		res = append(res, Field{NewSymbolToken(name), val})
+	}
+
+	v.val = res
+	return nil
+}
+
+// RemoveField removes all fields with the given name from a struct, returning the
+// number of fields removed.
+func (v *Value) RemoveField(name string) (int, error) {
+	fields, err := v.fields("Value.RemoveField")
+	if err != nil {
+		return 0, err
+	}
+
+	res := fields[:0]
+	for _, f := range fields {
+		if f.Name.Text == nil || *f.Name.Text != name {
+			res = append(res, f)
+		}
+	}
+
+	v.val = res
+	return len(fields) - len(res), nil
+}
+
+// Sequence returns the values of a non-null list or sexp.
+func (v *Value) sequence(api string) ([]*Value, error) {
+	if v.typ != ListType && v.typ != SexpType {
+		return nil, &UsageError{api, fmt.Sprintf("value is a %v, not a list or sexp", v.typ)}
+	}
+	if v.val == nil {
+		return nil, &UsageError{api, fmt.Sprintf("value is a %v", v.describe())}
+	}
+	return v.val.([]*Value), nil
+}
+
+// Fields returns the fields of a non-null struct.
+func (v *Value) fields(api string) ([]Field, error) {
+	if v.typ != StructType {
+		return nil, &UsageError{api, fmt.Sprintf("value is a %v, not a struct", v.typ)}
+	}
+	if v.val == nil {
+		return nil, &UsageError{api, "value is a null struct"}
+	}
+	return v.val.([]Field), nil
+}
+
+// Describe describes the value's type for error messages.
+func (v *Value) describe() string {
+	if v.val == nil {
+		return "null " + v.typ.String()
+	}
+	return v.typ.String()
+}
+
+// ReadValue reads the value the Reader is currently positioned on (after a call
+// to Next) into memory. If the value is a container, the Reader is left positioned
+// after it, such that the next call to Next moves to the following value.
+func ReadValue(r Reader) (*Value, error) {
+	if r.Type() == NoType {
+		return nil, &UsageError{"ReadValue", "reader is not positioned on a value"}
+	}
+
+	v := &Value{
+		typ:         r.Type(),
+		annotations: r.AnnotationSymbols(),
+	}
+	if r.IsNull() {
+		return v, nil
+	}
+
+	var err error
+	switch v.typ {
+	case BoolType:
+		v.val, err = r.BoolValue()
+	case IntType:
+		v.val, err = r.BigIntValue()
+	case FloatType:
+		v.val, err = r.FloatValue()
+	case DecimalType:
+		v.val, err = r.DecimalValue()
+	case TimestampType:
+		v.val, err = r.TimestampValue()
+	case SymbolType:
+		var tok *SymbolToken
+		if tok, err = r.SymbolValue(); err == nil {
+			v.val = *tok
+		}
+	case StringType:
+		v.val, err = r.StringValue()
+	case ClobType, BlobType:
+		v.val, err = r.ByteValue()
+	case ListType, SexpType:
+		v.val, err = readValues(r)
+	case StructType:
+		v.val, err = readFields(r)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// ReadValues reads all remaining values from the Reader's current value stream
+// into memory.
+func ReadValues(r Reader) ([]*Value, error) {
+	vals := []*Value{}
+	for r.Next() {
+		v, err := ReadValue(r)
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, v)
+	}
+	if err := r.Err(); err != nil {
+		return nil, err
+	}
+	return vals, nil
+}
+
+// ReadValues reads the values of a list or sexp.
+func readValues(r Reader) ([]*Value, error) {
+	if err := r.StepIn(); err != nil {
+		return nil, err
+	}
+	vals, err := ReadValues(r)
+	if err != nil {
+		return nil, err
+	}
+	return vals, r.StepOut()
+}
+
+// ReadFields reads the fields of a struct.
+func readFields(r Reader) ([]Field, error) {
+	if err := r.StepIn(); err != nil {
+		return nil, err
+	}
+
+	fields := []Field{}
+	for r.Next() {
+		name := r.FieldNameSymbol()
+		if name == nil {
+			return nil, &UsageError{"ReadValue", "struct field has no name"}
+		}
+
+		v, err := ReadValue(r)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, Field{*name, v})
+	}
+	if err := r.Err(); err != nil {
+		return nil, err
+	}
+
+	return fields, r.StepOut()
+}
+
+// WriteTo writes the value to the given Writer.
+func (v *Value) WriteTo(w Writer) error {
+	if len(v.annotations) > 0 {
+		w.AnnotationSymbols(v.annotations...)
+	}
+
+	if v.val == nil {
+		if v.typ == NullType {
+			return w.WriteNull()
+		}
+		return w.WriteNullType(v.typ)
+	}
+
+	switch val := v.val.(type) {
+	case bool:
+		return w.WriteBool(val)
+	case *big.Int:
+		return w.WriteBigInt(val)
+	case float64:
+		return w.WriteFloat(val)
+	case *Decimal:
+		return w.WriteDecimal(val)
+	case *Timestamp:
+		return w.WriteIonTimestamp(val)
+	case SymbolToken:
+		return w.WriteSymbolToken(val)
+	case string:
+		return w.WriteString(val)
+	case []byte:
+		if v.typ == ClobType {
+			return w.WriteClob(val)
+		}
+		return w.WriteBlob(val)
+
+	case []*Value:
+		if v.typ == SexpType {
+			w.BeginSexp()
+		} else {
+			w.BeginList()
+		}
+		for _, e := range val {
+			if err := e.WriteTo(w); err != nil {
+				return err
+			}
+		}
+		if v.typ == SexpType {
+			return w.EndSexp()
+		}
+		return w.EndList()
+
+	case []Field:
+		w.BeginStruct()
+		for _, f := range val {
+			w.FieldNameSymbol(f.Name)
+			if err := f.Value.WriteTo(w); err != nil {
+				return err
+			}
+		}
+		return w.EndStruct()
+	}
+
+	panic(fmt.Sprintf("unexpected value %T", v.val))
+}
+
+// String returns the value in Ion text format.
+func (v *Value) String() string {
+	buf := strings.Builder{}
+
+	w := NewTextWriterOpts(&buf, TextWriterQuietFinish)
+	v.WriteTo(w)
+	w.Finish()
+
+	return buf.String()
+}
+
+// Equal returns true if two values are equivalent according to the Ion data model:
+// they have the same type, annotations, and value, including the precision of
+// decimals and timestamps. Struct fields are compared without regard to order.
+func (v *Value) Equal(o *Value) bool {
+	if v == nil || o == nil {
+		return v == o
+	}
+	if v.typ != o.typ || len(v.annotations) != len(o.annotations) {
+		return false
+	}
+	for i := range v.annotations {
+		if !v.annotations[i].Equal(o.annotations[i]) {
+			return false
+		}
+	}
+	if v.val == nil || o.val == nil {
+		return v.val == nil && o.val == nil
+	}
+
+	switch val := v.val.(type) {
+	case bool:
+		return val == o.val.(bool)
+	case *big.Int:
+		return val.Cmp(o.val.(*big.Int)) == 0
+	case float64:
+		ov := o.val.(float64)
+		if math.IsNaN(val) {
+			return math.IsNaN(ov)
+		}
+		// Distinguishes 0 and -0.
+		return math.Float64bits(val) == math.Float64bits(ov)
+	case *Decimal:
+		return decimalEquiv(val, o.val.(*Decimal))
+	case *Timestamp:
+		return val.Equal(o.val.(*Timestamp))
+	case SymbolToken:
+		return val.Equal(o.val.(SymbolToken))
+	case string:
+		return val == o.val.(string)
+	case []byte:
+		return bytes.Equal(val, o.val.([]byte))
+
+	case []*Value:
+		ov := o.val.([]*Value)
+		if len(val) != len(ov) {
+			return false
+		}
+		for i := range val {
+			if !val[i].Equal(ov[i]) {
+				return false
+			}
+		}
+		return true
+
+	case []Field:
+		return fieldsEquiv(val, o.val.([]Field))
+	}
+
+	panic(fmt.Sprintf("unexpected value %T", v.val))
+}
+
+// DecimalEquiv returns true if two decimals have the same sign, coefficient and
+// exponent, meaning they have the same value and precision.
+func decimalEquiv(a, b *Decimal) bool {
+	an, ae := a.CoEx()
+	bn, be := b.CoEx()
+	return ae == be && an.Cmp(bn) == 0 && a.Signbit() == b.Signbit()
+}
+
+// FieldsEquiv returns true if two sets of struct fields are equivalent: every field
+// in one has a distinct equivalent field in the other.
+func fieldsEquiv(a, b []Field) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	matched := make([]bool, len(b))
+
+Outer:
+	for _, af := range a {
+		for i, bf := range b {
+			if !matched[i] && af.Name.Equal(bf.Name) && af.Value.Equal(bf.Value) {
+				matched[i] = true
+				continue Outer
+			}
+		}
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,250 @@
+package ion
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func readValuesStr(t *testing.T, str string) []*Value {
+	vals, err := ReadValues(NewReaderStr(str))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vals
+}
+
+func TestValueRoundTrip(t *testing.T) {
+	test := func(str string) {
+		t.Run(str, func(t *testing.T) {
+			vals := readValuesStr(t, str)
+
+			// Text.
+			buf := bytes.Buffer{}
+			w := NewTextWriterOpts(&buf, TextWriterQuietFinish)
+			for _, v := range vals {
+				if err := v.WriteTo(w); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Finish(); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != str {
+				t.Errorf("expected %v, got %v", str, buf.String())
+			}
+
+			// Binary.
+			buf.Reset()
+			bw := NewBinaryWriter(&buf)
+			for _, v := range vals {
+				if err := v.WriteTo(bw); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := bw.Finish(); err != nil {
+				t.Fatal(err)
+			}
+
+			bvals, err := ReadValues(NewReaderBytes(buf.Bytes()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(bvals) != len(vals) {
+				t.Fatalf("expected %v values, got %v", len(vals), len(bvals))
+			}
+			for i := range vals {
+				if !vals[i].Equal(bvals[i]) {
+					t.Errorf("expected %v, got %v", vals[i], bvals[i])
+				}
+			}
+		})
+	}
+
+	test("null\nnull.int\nnull.struct")
+	test("true\n42\n-18446744073709551616\n1.5e+0\nnan\n+inf")
+	test("1.20\n2020-05-01T12:34Z\n2020T")
+	test("foo\n'bar baz'\n\"qux\"\n{{aGk=}}\n{{\"hi\"}}")
+	test("[1,(a b),[]]")
+	test("a::b::{x:1,x:2,y:c::null.list,'z z':{}}")
+}
+
+func TestValueEqual(t *testing.T) {
+	test := func(a, b string, eq bool) {
+		t.Run(a+"="+b, func(t *testing.T) {
+			av := readValuesStr(t, a)[0]
+			bv := readValuesStr(t, b)[0]
+			if av.Equal(bv) != eq {
+				t.Errorf("expected %v.Equal(%v) = %v", a, b, eq)
+			}
+			if bv.Equal(av) != eq {
+				t.Errorf("expected %v.Equal(%v) = %v", b, a, eq)
+			}
+		})
+	}
+
+	test("null", "null.null", true)
+	test("null", "null.int", false)
+	test("null.int", "0", false)
+	test("1", "1", true)
+	test("1", "1.", false)
+	test("1.0", "1.00", false)
+	test("1.0", "1.0", true)
+	test("nan", "nan", true)
+	test("0e0", "-0e0", false)
+	test("2020T", "2020-01T", false)
+	test("foo", "\"foo\"", false)
+	test("foo", "'foo'", true)
+	test("{{\"hi\"}}", "{{aGk=}}", false)
+	test("[1,2]", "(1 2)", false)
+	test("[1,2]", "[2,1]", false)
+	test("a::1", "1", false)
+	test("a::b::1", "b::a::1", false)
+	test("{a:1,b:2}", "{b:2,a:1}", true)
+	test("{a:1,a:2}", "{a:2,a:1}", true)
+	test("{a:1,a:1}", "{a:1}", false)
+	test("{a:1,a:1}", "{a:1,a:2}", false)
+	test("{a:{b:[c]}}", "{a:{b:[c]}}", true)
+}
+
+func TestValueConstructors(t *testing.T) {
+	v := NewStructValue(
+		Field{NewSymbolToken("id"), NewIntValue(42)},
+		Field{NewSymbolToken("tags"), NewListValue(NewSymbolValue("new"), NewStringValue("hot"))},
+		Field{NewSymbolToken("price"), NewDecimalValue(MustParseDecimal("1.20"))},
+		Field{NewSymbolToken("when"), NewTimestampValue(MustParseTimestamp("2020-05-01"))},
+		Field{NewSymbolToken("data"), NewSexpValue(NewBlobValue([]byte("hi")), NewClobValue([]byte("hi")))},
+		Field{NewSymbolToken("other"), NewNullValue(FloatType)},
+		Field{NewSymbolToken("ok"), NewBoolValue(true)},
+		Field{NewSymbolToken("ratio"), NewFloatValue(math.Inf(-1))},
+	)
+	v.SetAnnotations("order")
+
+	eval := "order::{id:42,tags:[new,\"hot\"],price:1.20,when:2020-05-01,data:({{aGk=}} {{\"hi\"}}),other:null.float,ok:true,ratio:-inf}"
+	if v.String() != eval {
+		t.Errorf("expected %v, got %v", eval, v.String())
+	}
+	if !v.Equal(readValuesStr(t, eval)[0]) {
+		t.Errorf("expected %v to equal its parsed form", v)
+	}
+}
+
+func TestValueConstructorsNil(t *testing.T) {
+	test := func(v *Value, eval string) {
+		t.Run(eval, func(t *testing.T) {
+			if !v.IsNull() {
+				t.Errorf("expected %v to be null", v)
+			}
+			if v.String() != eval {
+				t.Errorf("expected %v, got %v", eval, v.String())
+			}
+			if !v.Equal(readValuesStr(t, eval)[0]) {
+				t.Errorf("expected %v to equal its parsed form", v)
+			}
+		})
+	}
+
+	test(NewBigIntValue(nil), "null.int")
+	test(NewDecimalValue(nil), "null.decimal")
+	test(NewTimestampValue(nil), "null.timestamp")
+}
+
+func TestValueAccessors(t *testing.T) {
+	v := readValuesStr(t, "a::{i:42,s:sym,t:\"str\",l:[1,2],n:null.int,i:43}")[0]
+
+	if as := v.Annotations(); len(as) != 1 || as[0] != "a" {
+		t.Errorf("expected annotations [a], got %v", as)
+	}
+	if v.Len() != 6 {
+		t.Errorf("expected 6 fields, got %v", v.Len())
+	}
+
+	i, err := v.Field("i").Int64Value()
+	if err != nil || i != 42 {
+		t.Errorf("expected 42, got %v (%v)", i, err)
+	}
+	if vals := v.FieldValues("i"); len(vals) != 2 {
+		t.Errorf("expected 2 fields named i, got %v", len(vals))
+	}
+	if v.Field("bogus") != nil {
+		t.Error("expected no field named bogus")
+	}
+
+	s, err := v.Field("s").StringValue()
+	if err != nil || s != "sym" {
+		t.Errorf("expected sym, got %v (%v)", s, err)
+	}
+	if _, err := v.Field("t").SymbolValue(); err == nil {
+		t.Error("expected an error getting a string as a symbol")
+	}
+	if _, err := v.Field("s").BoolValue(); err == nil {
+		t.Error("expected an error getting a symbol as a bool")
+	}
+
+	l := v.Field("l")
+	if l.Len() != 2 || l.Index(1).String() != "2" {
+		t.Errorf("expected [1,2], got %v", l)
+	}
+
+	n := v.Field("n")
+	if !n.IsNull() || n.Type() != IntType {
+		t.Errorf("expected null.int, got %v", n)
+	}
+}
+
+func TestValueMutation(t *testing.T) {
+	v := readValuesStr(t, "{a:1,b:[1,2],a:2,c:3}")[0]
+
+	if err := v.SetField("a", NewSymbolValue("x")); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.AddField("d", NewNullValue(NullType)); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := v.RemoveField("c"); err != nil || n != 1 {
+		t.Errorf("expected to remove 1 field, removed %v (%v)", n, err)
+	}
+
+	b := v.Field("b")
+	if err := b.Append(NewIntValue(3)); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.SetIndex(0, NewIntValue(0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Remove(1); err != nil {
+		t.Fatal(err)
+	}
+	b.SetAnnotations("nums")
+
+	eval := "{a:x,b:nums::[0,3],d:null}"
+	if v.String() != eval {
+		t.Errorf("expected %v, got %v", eval, v.String())
+	}
+
+	if err := NewNullValue(ListType).Append(NewIntValue(1)); err == nil {
+		t.Error("expected an error appending to a null list")
+	}
+	if err := NewIntValue(1).AddField("a", NewIntValue(1)); err == nil {
+		t.Error("expected an error adding a field to an int")
+	}
+}
+
+func TestValueUnknownSymbols(t *testing.T) {
+	// Symbols with unknown text survive a round trip through a Value.
+	v := readValuesStr(t, "$ion_symbol_table::{imports:[{name:\"missing\",version:1,max_id:2}]} $10::{$11:$10}")[0]
+
+	buf := bytes.Buffer{}
+	w := NewTextWriterOpts(&buf, TextWriterQuietFinish)
+	if err := v.WriteTo(w); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	eval := "$10::{$11:$10}"
+	if buf.String() != eval {
+		t.Errorf("expected %v, got %v", eval, buf.String())
+	}
+}
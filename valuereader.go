@@ -0,0 +1,165 @@
+package ion
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// A valueReader reads in-memory Values.
+type valueReader struct {
+	reader
+
+	cur    *Value
+	frames []valueFrame
+}
+
+// A valueFrame tracks the reader's position within a sequence of values or
+// struct fields.
+type valueFrame struct {
+	vals     []*Value
+	fields   []Field
+	isStruct bool
+	pos      int
+}
+
+// NewValueReader creates a new Reader that reads the given in-memory Values as
+// if they were a stream of top-level values, without serializing them.
+//
+// 	r := NewValueReader(v)
+// 	d := NewDecoder(r)
+// 	err := d.DecodeTo(&thing)
+func NewValueReader(vals ...*Value) Reader {
+	return &valueReader{
+		frames: []valueFrame{{vals: vals}},
+	}
+}
+
+// SymbolTable returns nil, since in-memory values are not encoded against a
+// symbol table.
+func (r *valueReader) SymbolTable() SymbolTable {
+	return nil
+}
+
+// Next moves the reader to the next value.
+func (r *valueReader) Next() bool {
+	if r.eof || r.err != nil {
+		return false
+	}
+
+	r.clear()
+	r.cur = nil
+
+	f := &r.frames[len(r.frames)-1]
+
+	var v *Value
+	if f.isStruct {
+		if f.pos >= len(f.fields) {
+			r.eof = true
+			return false
+		}
+		field := f.fields[f.pos]
+		r.fieldName = field.Name.String()
+		name := field.Name
+		r.fieldNameSym = &name
+		v = field.Value
+	} else {
+		if f.pos >= len(f.vals) {
+			r.eof = true
+			return false
+		}
+		v = f.vals[f.pos]
+	}
+	f.pos++
+
+	if v == nil {
+		r.err = fmt.Errorf("ion: nil value in %v", ctxToContainerType(r.ctx.peek()))
+		return false
+	}
+
+	r.cur = v
+	r.setValue(v)
+	r.count++
+	return true
+}
+
+// SetValue sets the reader's current value from an in-memory Value.
+func (r *valueReader) setValue(v *Value) {
+	r.valueType = v.typ
+
+	if len(v.annotations) > 0 {
+		r.annotationSyms = v.annotations
+		r.annotations = make([]string, len(v.annotations))
+		for i, a := range v.annotations {
+			r.annotations[i] = a.String()
+		}
+	}
+
+	switch val := v.val.(type) {
+	case nil:
+		r.value = nil
+	case *big.Int:
+		if val.IsInt64() {
+			r.value = val.Int64()
+		} else {
+			r.value = val
+		}
+	case SymbolToken:
+		r.value = val.String()
+		r.symbolValue = &val
+	case []*Value, []Field:
+		// The base reader only needs a non-nil marker for containers.
+		r.value = v.typ
+	default:
+		r.value = val
+	}
+}
+
+// StepIn steps in to a container-type value.
+func (r *valueReader) StepIn() error {
+	if r.err != nil {
+		return r.err
+	}
+
+	if r.valueType != ListType && r.valueType != SexpType && r.valueType != StructType {
+		return &UsageError{"Reader.StepIn", fmt.Sprintf("cannot step in to a %v", r.valueType)}
+	}
+	if r.value == nil {
+		return &UsageError{"Reader.StepIn", "cannot step in to a null container"}
+	}
+
+	frame := valueFrame{}
+	switch val := r.cur.val.(type) {
+	case []*Value:
+		frame.vals = val
+	case []Field:
+		frame.fields = val
+		frame.isStruct = true
+	}
+
+	r.pushPath()
+	r.ctx.push(containerTypeToCtx(r.valueType))
+	r.frames = append(r.frames, frame)
+	r.clear()
+	r.cur = nil
+
+	return nil
+}
+
+// StepOut steps out of a container-type value.
+func (r *valueReader) StepOut() error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.ctx.peek() == ctxAtTopLevel {
+		return &UsageError{"Reader.StepOut", "cannot step out of top-level datagram"}
+	}
+
+	r.popPath()
+	r.ctx.pop()
+	r.frames = r.frames[:len(r.frames)-1]
+	r.clear()
+	r.cur = nil
+	r.eof = false
+
+	return nil
+}
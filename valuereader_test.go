@@ -0,0 +1,78 @@
+package ion
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestValueReader(t *testing.T) {
+	vals := readValuesStr(t, "a::{x:1,'y':[foo,\"bar\"],z:null.int} (1 18446744073709551616) null 1.5e0 2.0 {{aGk=}}")
+	r := NewValueReader(vals...)
+
+	_structAF(t, r, "", []string{"a"}, func(t *testing.T, r Reader) {
+		_intAF(t, r, "x", nil, 1)
+		_listAF(t, r, "y", nil, func(t *testing.T, r Reader) {
+			_symbol(t, r, "foo")
+			_string(t, r, "bar")
+		})
+		_nullAF(t, r, IntType, "z", nil)
+	})
+	_sexp(t, r, func(t *testing.T, r Reader) {
+		_int(t, r, 1)
+		_bigInt(t, r, new(big.Int).Lsh(big.NewInt(1), 64))
+	})
+	_null(t, r, NullType)
+	_float(t, r, 1.5)
+	_decimal(t, r, MustParseDecimal("2.0"))
+	_blob(t, r, []byte("hi"))
+	_eof(t, r)
+}
+
+func TestValueReaderSkip(t *testing.T) {
+	vals := readValuesStr(t, "[1,[2,3],4] {a:{b:c}} 5")
+	r := NewValueReader(vals...)
+
+	_list(t, r, func(t *testing.T, r Reader) {
+		_int(t, r, 1)
+		_next(t, r, ListType)
+	})
+	_struct(t, r, func(t *testing.T, r Reader) {})
+	_int(t, r, 5)
+	_eof(t, r)
+
+	if err := r.StepOut(); err == nil {
+		t.Error("expected an error stepping out of the top level")
+	}
+}
+
+func TestValueReaderDecode(t *testing.T) {
+	type item struct {
+		Name string
+		Qty  int
+	}
+	type order struct {
+		ID    int64
+		Items []item
+	}
+
+	v := NewStructValue(
+		Field{NewSymbolToken("ID"), NewIntValue(17)},
+		Field{NewSymbolToken("Items"), NewListValue(
+			NewStructValue(
+				Field{NewSymbolToken("Name"), NewStringValue("widget")},
+				Field{NewSymbolToken("Qty"), NewIntValue(2)},
+			),
+		)},
+	)
+
+	var o order
+	if err := UnmarshalFrom(NewValueReader(v), &o); err != nil {
+		t.Fatal(err)
+	}
+
+	eval := order{17, []item{{"widget", 2}}}
+	if !reflect.DeepEqual(o, eval) {
+		t.Errorf("expected %v, got %v", eval, o)
+	}
+}
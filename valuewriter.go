@@ -0,0 +1,223 @@
+package ion
+
+import (
+	"math/big"
+	"time"
+)
+
+// A valueWriter builds in-memory Values.
+type valueWriter struct {
+	writer
+
+	vals  *[]*Value
+	stack []*Value
+}
+
+// NewValueWriter creates a new Writer that builds in-memory Values instead of
+// serializing them, appending each top-level value to vals as it is begun.
+//
+// 	vals := []*Value{}
+// 	w := NewValueWriter(&vals)
+// 	e := NewEncoder(w)
+// 	err := e.Encode(thing)
+func NewValueWriter(vals *[]*Value) Writer {
+	return &valueWriter{
+		vals: vals,
+	}
+}
+
+// WriteNull writes an untyped null.
+func (w *valueWriter) WriteNull() error {
+	return w.writeValue("Writer.WriteNull", &Value{typ: NullType})
+}
+
+// WriteNullType writes a typed null.
+func (w *valueWriter) WriteNullType(t Type) error {
+	return w.writeValue("Writer.WriteNullType", NewNullValue(t))
+}
+
+// WriteBool writes a bool.
+func (w *valueWriter) WriteBool(val bool) error {
+	return w.writeValue("Writer.WriteBool", NewBoolValue(val))
+}
+
+// WriteInt writes an integer.
+func (w *valueWriter) WriteInt(val int64) error {
+	return w.writeValue("Writer.WriteInt", NewIntValue(val))
+}
+
+// WriteUint writes an unsigned integer.
+func (w *valueWriter) WriteUint(val uint64) error {
+	return w.writeValue("Writer.WriteUint", NewBigIntValue(new(big.Int).SetUint64(val)))
+}
+
+// WriteBigInt writes a big integer.
+func (w *valueWriter) WriteBigInt(val *big.Int) error {
+	return w.writeValue("Writer.WriteBigInt", NewBigIntValue(val))
+}
+
+// WriteFloat writes a floating-point value.
+func (w *valueWriter) WriteFloat(val float64) error {
+	return w.writeValue("Writer.WriteFloat", NewFloatValue(val))
+}
+
+// WriteFloat32 writes a 32-bit floating-point value as its 64-bit equivalent.
+func (w *valueWriter) WriteFloat32(val float32) error {
+	return w.WriteFloat(float64(val))
+}
+
+// WriteDecimal writes an arbitrary-precision decimal value.
+func (w *valueWriter) WriteDecimal(val *Decimal) error {
+	return w.writeValue("Writer.WriteDecimal", NewDecimalValue(val))
+}
+
+// WriteTimestamp writes a timestamp with nanosecond precision, as the binary
+// writer does.
+func (w *valueWriter) WriteTimestamp(val time.Time) error {
+	ts := NewTimestamp(val, TimestampPrecisionFraction)
+	return w.writeValue("Writer.WriteTimestamp", NewTimestampValue(ts))
+}
+
+// WriteIonTimestamp writes an Ion timestamp.
+func (w *valueWriter) WriteIonTimestamp(val *Timestamp) error {
+	return w.writeValue("Writer.WriteIonTimestamp", NewTimestampValue(val))
+}
+
+// WriteSymbol writes a symbol.
+func (w *valueWriter) WriteSymbol(val string) error {
+	return w.writeValue("Writer.WriteSymbol", NewSymbolTokenValue(symbolTokenFromString(val)))
+}
+
+// WriteSymbolToken writes a symbol from a SymbolToken.
+func (w *valueWriter) WriteSymbolToken(val SymbolToken) error {
+	return w.writeValue("Writer.WriteSymbolToken", NewSymbolTokenValue(val))
+}
+
+// WriteString writes a string.
+func (w *valueWriter) WriteString(val string) error {
+	return w.writeValue("Writer.WriteString", NewStringValue(val))
+}
+
+// WriteClob writes a clob.
+func (w *valueWriter) WriteClob(val []byte) error {
+	return w.writeValue("Writer.WriteClob", NewClobValue(val))
+}
+
+// WriteBlob writes a blob.
+func (w *valueWriter) WriteBlob(val []byte) error {
+	return w.writeValue("Writer.WriteBlob", NewBlobValue(val))
+}
+
+// BeginList begins writing a list.
+func (w *valueWriter) BeginList() error {
+	return w.begin("Writer.BeginList", ctxInList, NewListValue())
+}
+
+// EndList finishes writing a list.
+func (w *valueWriter) EndList() error {
+	return w.end("Writer.EndList", ctxInList)
+}
+
+// BeginSexp begins writing an s-expression.
+func (w *valueWriter) BeginSexp() error {
+	return w.begin("Writer.BeginSexp", ctxInSexp, NewSexpValue())
+}
+
+// EndSexp finishes writing an s-expression.
+func (w *valueWriter) EndSexp() error {
+	return w.end("Writer.EndSexp", ctxInSexp)
+}
+
+// BeginStruct begins writing a struct.
+func (w *valueWriter) BeginStruct() error {
+	return w.begin("Writer.BeginStruct", ctxInStruct, NewStructValue())
+}
+
+// EndStruct finishes writing a struct.
+func (w *valueWriter) EndStruct() error {
+	return w.end("Writer.EndStruct", ctxInStruct)
+}
+
+// WriteValue writes the value the reader is positioned on.
+func (w *valueWriter) WriteValue(r Reader) error {
+	return w.writeValueFrom(w, r)
+}
+
+// WriteValues writes the rest of the reader's values.
+func (w *valueWriter) WriteValues(r Reader) error {
+	return writeValues(w, r)
+}
+
+// Finish checks that all containers have been ended. Values are appended as
+// they are written, so there is nothing to flush.
+func (w *valueWriter) Finish() error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.ctx.peek() != ctxAtTopLevel {
+		return &UsageError{"Writer.Finish", "not at top level"}
+	}
+
+	w.clear()
+	return nil
+}
+
+// writeValue adds a value to the current container, or to the list of
+// top-level values.
+func (w *valueWriter) writeValue(api string, v *Value) error {
+	if w.err != nil {
+		return w.err
+	}
+
+	var parent *Value
+	if len(w.stack) > 0 {
+		parent = w.stack[len(w.stack)-1]
+	}
+
+	if len(w.annotations) > 0 {
+		v.annotations = w.annotations
+	}
+
+	switch {
+	case parent == nil:
+		*w.vals = append(*w.vals, v)
+	case w.inStruct():
+		if w.fieldName == nil {
+			w.err = &UsageError{api, "field name not set"}
+			return w.err
+		}
+		parent.val = append(parent.val.([]Field), Field{*w.fieldName, v})
+	default:
+		parent.val = append(parent.val.([]*Value), v)
+	}
+
+	w.clear()
+	return nil
+}
+
+// begin starts writing a container of the given type.
+func (w *valueWriter) begin(api string, t ctx, v *Value) error {
+	if err := w.writeValue(api, v); err != nil {
+		return err
+	}
+
+	w.ctx.push(t)
+	w.stack = append(w.stack, v)
+	return nil
+}
+
+// end finishes writing a container of the given type.
+func (w *valueWriter) end(api string, t ctx) error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.ctx.peek() != t {
+		w.err = &UsageError{api, "not in that kind of container"}
+		return w.err
+	}
+
+	w.clear()
+	w.ctx.pop()
+	w.stack = w.stack[:len(w.stack)-1]
+	return nil
+}
@@ -0,0 +1,127 @@
+package ion
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestValueWriter(t *testing.T) {
+	vals := []*Value{}
+	w := NewValueWriter(&vals)
+
+	w.Annotation("a")
+	w.BeginStruct()
+	{
+		w.FieldName("x")
+		w.WriteInt(1)
+
+		w.FieldName("y")
+		w.BeginList()
+		{
+			w.WriteSymbol("foo")
+			w.WriteString("bar")
+		}
+		w.EndList()
+
+		w.FieldName("z")
+		w.WriteNullType(IntType)
+	}
+	w.EndStruct()
+
+	w.BeginSexp()
+	{
+		w.WriteUint(1)
+		w.WriteBigInt(new(big.Int).Lsh(big.NewInt(1), 64))
+	}
+	w.EndSexp()
+
+	w.WriteNull()
+	w.WriteFloat(1.5)
+	w.WriteDecimal(MustParseDecimal("2.0"))
+	w.WriteBlob([]byte("hi"))
+
+	if err := w.Finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	evals := readValuesStr(t, "a::{x:1,'y':[foo,\"bar\"],z:null.int} (1 18446744073709551616) null 1.5e0 2.0 {{aGk=}}")
+	if len(vals) != len(evals) {
+		t.Fatalf("expected %v values, got %v", len(evals), len(vals))
+	}
+	for i := range evals {
+		if !evals[i].Equal(vals[i]) {
+			t.Errorf("expected %v, got %v", evals[i], vals[i])
+		}
+	}
+}
+
+func TestValueWriterCopies(t *testing.T) {
+	vals := []*Value{}
+	w := NewValueWriter(&vals)
+
+	i := big.NewInt(42)
+	d := MustParseDecimal("1.5")
+	w.WriteBigInt(i)
+	w.WriteDecimal(d)
+
+	// Reusing the written values must not change what was written.
+	i.SetInt64(7)
+	if err := d.UnmarshalText([]byte("2.5")); err != nil {
+		t.Fatal(err)
+	}
+
+	evals := readValuesStr(t, "42 1.5")
+	for j := range evals {
+		if !evals[j].Equal(vals[j]) {
+			t.Errorf("expected %v, got %v", evals[j], vals[j])
+		}
+	}
+}
+
+func TestValueWriterErrors(t *testing.T) {
+	vals := []*Value{}
+	w := NewValueWriter(&vals)
+
+	w.BeginStruct()
+	if err := w.WriteInt(1); err == nil {
+		t.Error("expected an error writing a field with no name")
+	}
+
+	w = NewValueWriter(&vals)
+	w.BeginList()
+	if err := w.EndStruct(); err == nil {
+		t.Error("expected an error ending the wrong kind of container")
+	}
+
+	w = NewValueWriter(&vals)
+	w.BeginList()
+	if err := w.Finish(); err == nil {
+		t.Error("expected an error finishing inside a list")
+	}
+}
+
+func TestValueWriterEncode(t *testing.T) {
+	type item struct {
+		Name string   `ion:"name"`
+		Tags []string `ion:"tags,symbol"`
+	}
+
+	vals := []*Value{}
+	if err := MarshalTo(NewValueWriter(&vals), item{"widget", []string{"new"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	eval := "{name:\"widget\",tags:[new]}"
+	if len(vals) != 1 || vals[0].String() != eval {
+		t.Fatalf("expected %v, got %v", eval, vals)
+	}
+
+	// And back again, without serializing in between.
+	var out item
+	if err := UnmarshalFrom(NewValueReader(vals...), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Name != "widget" || len(out.Tags) != 1 || out.Tags[0] != "new" {
+		t.Errorf("expected {widget [new]}, got %v", out)
+	}
+}
@@ -1,7 +1,7 @@
 package ion
 
 import (
-	"errors"
+	"fmt"
 	"io"
 	"math/big"
 	"time"
@@ -52,8 +52,12 @@ import (
 //
 type Writer interface {
 
-	// FieldName sets the field name for the next value written.
+	// FieldName sets the field name for the next value written. A name of the
+	// form $<integer> refers to a symbol by its ID.
 	FieldName(val string) error
+	// FieldNameSymbol sets the field name for the next value written from a
+	// SymbolToken, which may have unknown text.
+	FieldNameSymbol(val SymbolToken) error
 
 	// Annotation adds a single annotation to the next value written.
 	Annotation(val string) error
@@ -61,6 +65,10 @@ type Writer interface {
 	// Annotations adds multiple annotations to the next value written.
 	Annotations(vals ...string) error
 
+	// AnnotationSymbols adds one or more annotations, given as SymbolTokens, to the
+	// next value written.
+	AnnotationSymbols(vals ...SymbolToken) error
+
 	// WriteNull writes an untyped null value.
 	WriteNull() error
 	// WriteNullType writes a null value with a type qualifier, e.g. null.bool.
@@ -75,16 +83,26 @@ type Writer interface {
 	WriteUint(val uint64) error
 	// WriteBigInt writes a big integer value.
 	WriteBigInt(val *big.Int) error
-	// WriteFloat writes a floating-point value.
+	// WriteFloat writes a floating-point value, which binary writers encode in
+	// four bytes if that's lossless.
 	WriteFloat(val float64) error
+	// WriteFloat32 writes a 32-bit floating-point value, which binary writers
+	// encode in four bytes instead of eight.
+	WriteFloat32(val float32) error
 	// WriteDecimal writes an arbitrary-precision decimal value.
 	WriteDecimal(val *Decimal) error
 
 	// WriteTimestamp writes a timestamp value.
 	WriteTimestamp(val time.Time) error
+	// WriteIonTimestamp writes a timestamp value, preserving its precision and offset.
+	WriteIonTimestamp(val *Timestamp) error
 
-	// WriteSymbol writes a symbol value.
+	// WriteSymbol writes a symbol value. A value of the form $<integer> refers
+	// to a symbol by its ID.
 	WriteSymbol(val string) error
+	// WriteSymbolToken writes a symbol value from a SymbolToken, which may have
+	// unknown text.
+	WriteSymbolToken(val SymbolToken) error
 	// WriteString writes a string value.
 	WriteString(val string) error
 
@@ -108,6 +126,15 @@ type Writer interface {
 	// EndStruct finishes writing a struct value.
 	EndStruct() error
 
+	// WriteValue writes the value the given Reader is positioned on, along with its
+	// annotations and, if this Writer is in a struct and no field name has been set,
+	// its field name. Containers are written with all of their contents, leaving the
+	// Reader positioned after them.
+	WriteValue(r Reader) error
+	// WriteValues writes the rest of the values in the given Reader's current sequence,
+	// which at the top level is the rest of the stream.
+	WriteValues(r Reader) error
+
 	// Finish finishes writing values and flushes any buffered data.
 	Finish() error
 }
@@ -118,18 +145,32 @@ type writer struct {
 	ctx ctxstack
 	err error
 
-	fieldName   string
-	annotations []string
+	fieldName   *SymbolToken
+	annotations []SymbolToken
 }
 
 // FieldName sets the field name for the next value written.
 // It may only be called while writing a struct.
 func (w *writer) FieldName(val string) error {
+	if val == "" {
+		return w.setFieldName("Writer.FieldName", nil)
+	}
+	tok := symbolTokenFromString(val)
+	return w.setFieldName("Writer.FieldName", &tok)
+}
+
+// FieldNameSymbol sets the field name for the next value written.
+// It may only be called while writing a struct.
+func (w *writer) FieldNameSymbol(val SymbolToken) error {
+	return w.setFieldName("Writer.FieldNameSymbol", &val)
+}
+
+func (w *writer) setFieldName(api string, val *SymbolToken) error {
 	if w.err != nil {
 		return w.err
 	}
 	if !w.inStruct() {
-		w.err = errors.New("ion: Writer.FieldName called when not writing a struct")
+		w.err = fmt.Errorf("ion: %v called when not writing a struct", api)
 		return w.err
 	}
 
@@ -140,13 +181,23 @@ func (w *writer) FieldName(val string) error {
 // Annotation adds an annotation to the next value written.
 func (w *writer) Annotation(val string) error {
 	if w.err == nil {
-		w.annotations = append(w.annotations, val)
+		w.annotations = append(w.annotations, symbolTokenFromString(val))
 	}
 	return w.err
 }
 
 // Annotations adds one or more annotations to the next value written.
 func (w *writer) Annotations(val ...string) error {
+	if w.err == nil {
+		for _, a := range val {
+			w.annotations = append(w.annotations, symbolTokenFromString(a))
+		}
+	}
+	return w.err
+}
+
+// AnnotationSymbols adds one or more annotations to the next value written.
+func (w *writer) AnnotationSymbols(val ...SymbolToken) error {
 	if w.err == nil {
 		w.annotations = append(w.annotations, val...)
 	}
@@ -160,6 +211,6 @@ func (w *writer) inStruct() bool {
 
 // Clear clears field name and annotations after writing a value.
 func (w *writer) clear() {
-	w.fieldName = ""
+	w.fieldName = nil
 	w.annotations = nil
 }